@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"log"
 	"net/http"
@@ -10,9 +11,11 @@ import (
 
 	"github.com/avito-shop-service/internal/config"
 	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/middleware"
 	"github.com/avito-shop-service/internal/repository"
 	"github.com/avito-shop-service/internal/router"
 	"github.com/avito-shop-service/internal/services"
+	"github.com/avito-shop-service/pkg/auth"
 )
 
 func main() {
@@ -23,6 +26,12 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	authConfig, err := auth.LoadConfig(cfg.JWTConfigPath)
+	if err != nil {
+		log.Fatalf("Failed to load JWT config: %v", err)
+	}
+	tokenManager := auth.NewJWTManager(authConfig, nil)
+
 	dbConfig, err := pgxpool.ParseConfig(cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("Failed to parse DB config: %v", err)
@@ -39,18 +48,99 @@ func main() {
 	transactionRepo := repository.NewTransactionRepository(db)
 	inventoryRepo := repository.NewInventoryRepository(db)
 	merchRepo := repository.NewMerchRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	loginFailureRepo := repository.NewLoginFailureRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	loginEventRepo := repository.NewLoginEventRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	scheduledTransferRepo := repository.NewScheduledTransferRepository(db)
+	transferLimitRepo := repository.NewTransferLimitRepository(db)
+	issuanceRepo := repository.NewIssuanceRepository(db)
+	coinLotRepo := repository.NewCoinLotRepository(db)
 
-	userService := services.NewUserService(userRepo)
-	transactionService := services.NewTransactionService(transactionRepo)
+	tokenService := services.NewTokenService(tokenRepo, userRepo, tokenManager, cfg.RefreshTokenTTL)
+	loginGuard := services.NewLoginGuard(loginFailureRepo, services.LoginGuardPolicy{
+		MaxFailures:     cfg.LoginMaxFailures,
+		LockoutDuration: cfg.LoginLockoutDuration,
+		IPMaxFailures:   cfg.LoginIPMaxFailures,
+	}, nil)
+	authenticators, oidcAuthenticator, err := newAuthenticators(cfg, userRepo)
+	if err != nil {
+		log.Fatalf("Failed to configure identity providers: %v", err)
+	}
+	sessionService := services.NewSessionService(tokenRepo, loginEventRepo, userRepo, nil)
+	userService := services.NewUserService(userRepo, tokenService, loginGuard, sessionService, authenticators...)
+	passwordService := services.NewPasswordService(userRepo, passwordResetRepo, tokenService, loginGuard, cfg.PasswordResetTokenTTL, nil)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, services.NewRateLimiter(nil), cfg.APIKeyRateLimit, nil)
+	profileService := services.NewProfileService(userRepo)
+	transferLimitService := services.NewTransferLimitService(transferLimitRepo, userRepo, services.TransferLimitPolicy{
+		MaxPerTransfer:  cfg.TransferMaxAmount,
+		DailyLimit:      cfg.TransferDailyLimit,
+		MonthlyLimit:    cfg.TransferMonthlyLimit,
+		RecipientLimit:  cfg.TransferRecipientLimit,
+		RecipientPeriod: cfg.TransferRecipientPeriod,
+	}, nil)
+	transactionService := services.NewTransactionService(transactionRepo, cfg.KudosCategories, transferLimitService)
 	inventoryService := services.NewInventoryService(inventoryRepo)
 	merchService := services.NewMerchService(merchRepo)
+	ledgerService := services.NewLedgerService(ledgerRepo)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyKeyTTL, nil)
+	scheduledTransferService := services.NewScheduledTransferService(scheduledTransferRepo, transactionService, nil)
+	transferReversalService := services.NewTransferReversalService(transactionRepo, cfg.TransferCancelWindow, nil)
+	issuanceService, err := services.NewIssuanceService(issuanceRepo, services.AllowancePolicy{
+		Amount: cfg.AllowanceAmount,
+		Period: cfg.AllowancePeriod,
+	}, nil)
+	if err != nil {
+		log.Fatalf("Failed to configure the allowance: %v", err)
+	}
+	coinExpiryService := services.NewCoinExpiryService(coinLotRepo, cfg.CoinLifetimeMonths, nil)
+
+	autoProvisionMode := cfg.AutoProvisionMode
+	if !contains(cfg.AuthProviders, services.ProviderLocal) {
+		// Without local passwords /api/auth must not create local accounts; external
+		// providers provision users on their own.
+		autoProvisionMode = string(services.ProvisionNever)
+	}
+	provisioningPolicy, err := services.NewProvisioningPolicy(autoProvisionMode, cfg.AutoProvisionAllowList, cfg.AutoProvisionPattern)
+	if err != nil {
+		log.Fatalf("Failed to load provisioning policy: %v", err)
+	}
 
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, provisioningPolicy)
+	tokenHandler := handlers.NewTokenHandler(tokenService)
+	jwksHandler := handlers.NewJWKSHandler(tokenManager)
 	transactionHandler := handlers.NewTransactionHandler(userService, transactionService)
 	buyHandler := handlers.NewBuyHandler(userService, merchService, inventoryService, transactionService)
-	infoHandler := handlers.NewInformationHandler(userService, merchService, inventoryService, transactionService)
+	infoHandler := handlers.NewInformationHandler(userService, merchService, inventoryService, transactionService, coinExpiryService)
+	merchHandler := handlers.NewMerchHandler(merchService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	passwordHandler := handlers.NewPasswordHandler(passwordService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	profileHandler := handlers.NewProfileHandler(profileService)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+	scheduledTransferHandler := handlers.NewScheduledTransferHandler(scheduledTransferService)
+	transferLimitHandler := handlers.NewTransferLimitHandler(transferLimitService)
+	transferReversalHandler := handlers.NewTransferReversalHandler(transferReversalService)
+	issuanceHandler := handlers.NewIssuanceHandler(issuanceService)
+	var oidcHandler *handlers.OIDCHandler
+	if oidcAuthenticator != nil {
+		oidcHandler = handlers.NewOIDCHandler(userService, oidcAuthenticator)
+	}
+
+	authMiddleware := middleware.NewAuthMiddleware(tokenService, apiKeyService)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotencyService)
 
-	r := router.NewRouter(transactionHandler, userHandler, buyHandler, infoHandler)
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go scheduledTransferService.Run(jobsCtx, cfg.ScheduledTransferInterval)
+	go issuanceService.Run(jobsCtx, cfg.AllowanceInterval)
+	go coinExpiryService.Run(jobsCtx, cfg.CoinExpiryInterval)
+	go idempotencyService.Run(jobsCtx, cfg.IdempotencyCleanupInterval)
+
+	r := router.NewRouter(authMiddleware, idempotencyMiddleware, transactionHandler, userHandler, tokenHandler, jwksHandler, buyHandler, infoHandler, merchHandler, apiKeyHandler, passwordHandler, sessionHandler, profileHandler, ledgerHandler, scheduledTransferHandler, transferLimitHandler, transferReversalHandler, issuanceHandler, oidcHandler)
 
 	port := os.Getenv("SERVER_PORT")
 	if port == "" {
@@ -69,3 +159,53 @@ func main() {
 		log.Fatalf("Error starting server: %v", err)
 	}
 }
+
+// newAuthenticators creates the identity providers listed in AUTH_PROVIDERS, in that order.
+func newAuthenticators(cfg *config.Config, userRepo repository.UserRepositoryInterface) ([]services.Authenticator, *services.OIDCAuthenticator, error) {
+	var authenticators []services.Authenticator
+	var oidcAuthenticator *services.OIDCAuthenticator
+	for _, provider := range cfg.AuthProviders {
+		switch provider {
+		case services.ProviderLocal:
+			authenticators = append(authenticators, services.NewLocalAuthenticator(userRepo))
+		case services.ProviderLDAP:
+			ldapAuthenticator, err := services.NewLDAPAuthenticator(services.LDAPConfig{
+				URL:               cfg.LDAPURL,
+				BindDN:            cfg.LDAPBindDN,
+				BindPassword:      cfg.LDAPBindPassword,
+				BaseDN:            cfg.LDAPBaseDN,
+				UserFilter:        cfg.LDAPUserFilter,
+				UsernameAttribute: cfg.LDAPUsernameAttribute,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			authenticators = append(authenticators, ldapAuthenticator)
+		case services.ProviderOIDC:
+			var err error
+			oidcAuthenticator, err = services.NewOIDCAuthenticator(services.OIDCConfig{
+				Issuer:        cfg.OIDCIssuer,
+				ClientID:      cfg.OIDCClientID,
+				ClientSecret:  cfg.OIDCClientSecret,
+				RedirectURL:   cfg.OIDCRedirectURL,
+				UsernameClaim: cfg.OIDCUsernameClaim,
+			}, nil, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			authenticators = append(authenticators, oidcAuthenticator)
+		default:
+			return nil, nil, fmt.Errorf("unknown identity provider %q", provider)
+		}
+	}
+	return authenticators, oidcAuthenticator, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,63 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	DatabaseURL string
+	DatabaseURL   string
+	JWTConfigPath string
+
+	AutoProvisionMode      string
+	AutoProvisionAllowList []string
+	AutoProvisionPattern   string
+
+	RefreshTokenTTL       time.Duration
+	PasswordResetTokenTTL time.Duration
+
+	LoginMaxFailures     int
+	LoginLockoutDuration time.Duration
+	LoginIPMaxFailures   int
+
+	APIKeyRateLimit int
+
+	IdempotencyKeyTTL          time.Duration
+	IdempotencyCleanupInterval time.Duration
+
+	KudosCategories []string
+
+	ScheduledTransferInterval time.Duration
+
+	TransferMaxAmount       int
+	TransferDailyLimit      int
+	TransferMonthlyLimit    int
+	TransferRecipientLimit  int
+	TransferRecipientPeriod time.Duration
+	TransferCancelWindow    time.Duration
+
+	AllowanceAmount   int
+	AllowancePeriod   string
+	AllowanceInterval time.Duration
+
+	CoinLifetimeMonths int
+	CoinExpiryInterval time.Duration
+
+	AuthProviders []string
+
+	LDAPURL               string
+	LDAPBindDN            string
+	LDAPBindPassword      string
+	LDAPBaseDN            string
+	LDAPUserFilter        string
+	LDAPUsernameAttribute string
+
+	OIDCIssuer        string
+	OIDCClientID      string
+	OIDCClientSecret  string
+	OIDCRedirectURL   string
+	OIDCUsernameClaim string
 }
 
 func LoadConfig(flag bool) (*Config, error) {
@@ -21,7 +74,171 @@ func LoadConfig(flag bool) (*Config, error) {
 		return nil, fmt.Errorf("DATABASE_URL not set")
 	}
 
+	refreshTokenTTL, err := parseDuration("REFRESH_TOKEN_TTL")
+	if err != nil {
+		return nil, err
+	}
+	passwordResetTokenTTL, err := parseDuration("PASSWORD_RESET_TOKEN_TTL")
+	if err != nil {
+		return nil, err
+	}
+
+	loginMaxFailures, err := parseInt("LOGIN_MAX_FAILURES")
+	if err != nil {
+		return nil, err
+	}
+	loginIPMaxFailures, err := parseInt("LOGIN_IP_MAX_FAILURES")
+	if err != nil {
+		return nil, err
+	}
+	apiKeyRateLimit, err := parseInt("API_KEY_RATE_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	loginLockoutDuration, err := parseDuration("LOGIN_LOCKOUT_DURATION")
+	if err != nil {
+		return nil, err
+	}
+	idempotencyKeyTTL, err := parseDuration("IDEMPOTENCY_KEY_TTL")
+	if err != nil {
+		return nil, err
+	}
+	idempotencyCleanupInterval, err := parseDuration("IDEMPOTENCY_CLEANUP_INTERVAL")
+	if err != nil {
+		return nil, err
+	}
+	scheduledTransferInterval, err := parseDuration("SCHEDULED_TRANSFER_INTERVAL")
+	if err != nil {
+		return nil, err
+	}
+
+	transferMaxAmount, err := parseInt("TRANSFER_MAX_AMOUNT")
+	if err != nil {
+		return nil, err
+	}
+	transferDailyLimit, err := parseInt("TRANSFER_DAILY_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	transferMonthlyLimit, err := parseInt("TRANSFER_MONTHLY_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	transferRecipientLimit, err := parseInt("TRANSFER_RECIPIENT_LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	transferRecipientPeriod, err := parseDuration("TRANSFER_RECIPIENT_PERIOD")
+	if err != nil {
+		return nil, err
+	}
+	transferCancelWindow, err := parseDuration("TRANSFER_CANCEL_WINDOW")
+	if err != nil {
+		return nil, err
+	}
+
+	allowanceAmount, err := parseInt("ALLOWANCE_AMOUNT")
+	if err != nil {
+		return nil, err
+	}
+	allowanceInterval, err := parseDuration("ALLOWANCE_INTERVAL")
+	if err != nil {
+		return nil, err
+	}
+	coinLifetimeMonths, err := parseInt("COIN_LIFETIME_MONTHS")
+	if err != nil {
+		return nil, err
+	}
+	coinExpiryInterval, err := parseDuration("COIN_EXPIRY_INTERVAL")
+	if err != nil {
+		return nil, err
+	}
+
+	authProviders := splitList(os.Getenv("AUTH_PROVIDERS"))
+	if len(authProviders) == 0 {
+		authProviders = []string{"local"}
+	}
+
+	jwtConfigPath := os.Getenv("CONFIG_PATH")
+	if jwtConfigPath == "" {
+		jwtConfigPath = "pkg/auth/jwt_key/config.yaml"
+	}
+
 	return &Config{
-		DatabaseURL: dbURL,
+		DatabaseURL:                dbURL,
+		JWTConfigPath:              jwtConfigPath,
+		AutoProvisionMode:          os.Getenv("AUTO_PROVISION_MODE"),
+		AutoProvisionAllowList:     splitList(os.Getenv("AUTO_PROVISION_ALLOWLIST")),
+		AutoProvisionPattern:       os.Getenv("AUTO_PROVISION_PATTERN"),
+		RefreshTokenTTL:            refreshTokenTTL,
+		PasswordResetTokenTTL:      passwordResetTokenTTL,
+		LoginMaxFailures:           loginMaxFailures,
+		LoginLockoutDuration:       loginLockoutDuration,
+		LoginIPMaxFailures:         loginIPMaxFailures,
+		APIKeyRateLimit:            apiKeyRateLimit,
+		IdempotencyKeyTTL:          idempotencyKeyTTL,
+		IdempotencyCleanupInterval: idempotencyCleanupInterval,
+		KudosCategories:            splitList(os.Getenv("KUDOS_CATEGORIES")),
+		ScheduledTransferInterval:  scheduledTransferInterval,
+		TransferMaxAmount:          transferMaxAmount,
+		TransferDailyLimit:         transferDailyLimit,
+		TransferMonthlyLimit:       transferMonthlyLimit,
+		TransferRecipientLimit:     transferRecipientLimit,
+		TransferRecipientPeriod:    transferRecipientPeriod,
+		TransferCancelWindow:       transferCancelWindow,
+		AllowanceAmount:            allowanceAmount,
+		AllowancePeriod:            os.Getenv("ALLOWANCE_PERIOD"),
+		AllowanceInterval:          allowanceInterval,
+		CoinLifetimeMonths:         coinLifetimeMonths,
+		CoinExpiryInterval:         coinExpiryInterval,
+		AuthProviders:              authProviders,
+		LDAPURL:                    os.Getenv("LDAP_URL"),
+		LDAPBindDN:                 os.Getenv("LDAP_BIND_DN"),
+		LDAPBindPassword:           os.Getenv("LDAP_BIND_PASSWORD"),
+		LDAPBaseDN:                 os.Getenv("LDAP_BASE_DN"),
+		LDAPUserFilter:             os.Getenv("LDAP_USER_FILTER"),
+		LDAPUsernameAttribute:      os.Getenv("LDAP_USERNAME_ATTRIBUTE"),
+		OIDCIssuer:                 os.Getenv("OIDC_ISSUER"),
+		OIDCClientID:               os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:           os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:            os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCUsernameClaim:          os.Getenv("OIDC_USERNAME_CLAIM"),
 	}, nil
 }
+
+func parseInt(name string) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return n, nil
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseDuration(name string) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return d, nil
+}
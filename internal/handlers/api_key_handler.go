@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	RateLimit int        `json:"rateLimit"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey creates a key for the current user. The key itself is returned only in this response.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || len(req.Name) > 100 || req.RateLimit < 0 {
+		http.Error(w, "invalid name or rateLimit", http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		http.Error(w, "expiresAt must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(r.Context(), username, models.APIKey{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		RateLimit: req.RateLimit,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if errors.Is(err, models.ErrInvalidScopes) || errors.Is(err, models.ErrInvalidRateLimit) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating api key: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := h.apiKeyService.GetAPIKeys(r.Context(), username)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching api keys: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	keyID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid api key id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.apiKeyService.RevokeAPIKey(r.Context(), username, keyID)
+	if errors.Is(err, models.ErrAPIKeyNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error revoking api key: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
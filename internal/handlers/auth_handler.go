@@ -2,25 +2,46 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/avito-shop-service/internal/models"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
 )
 
+// maxUserAgentLen is the length of the user agent column of sessions and login events.
+const maxUserAgentLen = 512
+
+type AuthRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
 type UserHandler struct {
-	service services.UserServiceInterface
+	service            services.UserServiceInterface
+	provisioningPolicy *services.ProvisioningPolicy
 }
 
-func NewUserHandler(userService services.UserServiceInterface) *UserHandler {
-	return &UserHandler{service: userService}
+func NewUserHandler(userService services.UserServiceInterface, provisioningPolicy *services.ProvisioningPolicy) *UserHandler {
+	return &UserHandler{
+		service:            userService,
+		provisioningPolicy: provisioningPolicy,
+	}
 }
 
+// Auth is the legacy sign-in endpoint. Unknown usernames are provisioned on the fly
+// when the provisioning policy allows it. The others go through the regular login, so an
+// unknown name costs the same time and counts towards the login throttling as a known one.
 func (h *UserHandler) Auth(w http.ResponseWriter, r *http.Request) {
-	var req models.User
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	req, ok := h.decodeLoginRequest(w, r)
+	if !ok {
 		return
 	}
 
@@ -29,23 +50,173 @@ func (h *UserHandler) Auth(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Error fetching user: %v", err), http.StatusInternalServerError)
 		return
 	}
-	if user == nil {
+	if user == nil && h.provisioningPolicy.Allows(req.Username) {
 		_, err = h.service.CreateUser(r.Context(), req.Username, req.Password)
-		if err != nil {
+		if err != nil && !errors.Is(err, models.ErrUserAlreadyExists) {
 			http.Error(w, fmt.Sprintf("Error creating user: %v", err), http.StatusInternalServerError)
 			return
 		}
 	}
-	token, err := h.service.Authenticate(r.Context(), req.Username, req.Password)
+
+	h.login(w, r, req)
+}
+
+// Register creates an account and signs it in. Like Auth, it only creates the accounts the
+// provisioning policy allows.
+func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeAuthRequest(w, r)
+	if !ok {
+		return
+	}
+	if !h.provisioningPolicy.Allows(req.Username) {
+		http.Error(w, "registration of this username is not allowed", http.StatusForbidden)
+		return
+	}
+
+	_, err := h.service.CreateUser(r.Context(), req.Username, req.Password)
+	if errors.Is(err, models.ErrUserAlreadyExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	tokens, err := h.service.Authenticate(r.Context(), req.Username, req.Password, clientInfo(r))
 	if err != nil {
+		http.Error(w, fmt.Sprintf("Error authenticating user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, http.StatusCreated, tokens)
+}
+
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeLoginRequest(w, r)
+	if !ok {
+		return
+	}
+
+	h.login(w, r, req)
+}
+
+func (h *UserHandler) login(w http.ResponseWriter, r *http.Request, req AuthRequest) {
+	tokens, err := h.service.Authenticate(r.Context(), req.Username, req.Password, clientInfo(r))
+	var blockedErr *models.LoginBlockedError
+	if errors.As(err, &blockedErr) {
+		writeLoginBlocked(w, blockedErr)
+		return
+	}
+	if errors.Is(err, models.ErrInvalidCredentials) {
 		http.Error(w, fmt.Sprintf("Invalid username or password: %v", err), http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, models.ErrAccountInactive) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error authenticating user: %v", err), http.StatusInternalServerError)
+		return
 	}
 
+	writeTokens(w, http.StatusOK, tokens)
+}
+
+// Unlock lifts the lockout of the user named in the path after failed logins.
+func (h *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	err := h.service.UnlockUser(r.Context(), username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error unlocking user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeAuthRequest(w http.ResponseWriter, r *http.Request) (AuthRequest, bool) {
+	req, err := parseAuthRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// decodeLoginRequest is decodeAuthRequest for sign-ins: a malformed request is recorded in the
+// login audit trail like any other failed attempt.
+func (h *UserHandler) decodeLoginRequest(w http.ResponseWriter, r *http.Request) (AuthRequest, bool) {
+	req, err := parseAuthRequest(r)
+	if err != nil {
+		h.service.RecordFailedLogin(r.Context(), req.Username, clientInfo(r), err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func parseAuthRequest(r *http.Request) (AuthRequest, error) {
+	var req AuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return AuthRequest{}, fmt.Errorf("%w: invalid input", models.ErrInvalidLoginRequest)
+	}
+	if req.Username == "" || req.Password == "" {
+		return req, fmt.Errorf("%w: username and password are required", models.ErrInvalidLoginRequest)
+	}
+	return req, nil
+}
+
+func writeLoginBlocked(w http.ResponseWriter, err *models.LoginBlockedError) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	http.Error(w, err.Error(), http.StatusTooManyRequests)
+}
+
+func writeTokens(w http.ResponseWriter, status int, tokens *models.AuthTokens) {
+	writeJSON(w, status, tokens)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
 	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(map[string]string{
-		"token": token,
-	})
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error encoding response: %v", err), http.StatusInternalServerError)
 	}
 }
+
+// clientInfo describes the client by its direct peer address. X-Forwarded-For is not trusted,
+// since the service is not deployed behind a proxy that would overwrite it.
+func clientInfo(r *http.Request) models.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLen {
+		userAgent = userAgent[:maxUserAgentLen]
+	}
+	return models.ClientInfo{IP: ip, UserAgent: userAgent}
+}
+
+// parseTimeParam parses the RFC 3339 time in the query parameter name, or returns nil if it is
+// absent. Timestamps are stored without a zone holding UTC, so the time is converted to UTC
+// before it is compared with them.
+func parseTimeParam(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 time", name)
+	}
+	t = t.UTC()
+	return &t, nil
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
 	"github.com/avito-shop-service/internal/services"
 	"github.com/go-chi/chi/v5"
 )
@@ -39,7 +41,10 @@ func (h *BuyHandler) Buy(w http.ResponseWriter, r *http.Request) {
 	}
 
 	merch, err := h.merchService.GetMerchByName(r.Context(), itemName)
-
+	if errors.Is(err, models.ErrMerchNotFound) {
+		http.Error(w, "item not found", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error fetching merch: %v", err), http.StatusInternalServerError)
 		return
@@ -56,12 +61,11 @@ func (h *BuyHandler) Buy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user.Coins < merch.Price {
+	err = h.inventoryService.BuyItemToInventory(r.Context(), user.ID, merch.ID, 1, merch.Price)
+	if errors.Is(err, models.ErrInsufficientFunds) {
 		http.Error(w, "not enough coins", http.StatusBadRequest)
 		return
 	}
-
-	err = h.inventoryService.BuyItemToInventory(r.Context(), user.ID, merch.ID, 1, merch.Price)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error updating inventory: %v", err), http.StatusInternalServerError)
 		return
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
 	"github.com/avito-shop-service/internal/services"
 	"net/http"
 )
@@ -13,17 +14,27 @@ type InformationHandler struct {
 	merchService       services.MerchServiceInterface
 	inventoryService   services.InventoryServiceInterface
 	transactionService services.TransactionServiceInterface
+	coinExpiryService  services.CoinExpiryServiceInterface
 }
 
-func NewInformationHandler(userService services.UserServiceInterface, merchService services.MerchServiceInterface, inventoryService services.InventoryServiceInterface, transactionService services.TransactionServiceInterface) *InformationHandler {
+func NewInformationHandler(userService services.UserServiceInterface, merchService services.MerchServiceInterface, inventoryService services.InventoryServiceInterface, transactionService services.TransactionServiceInterface, coinExpiryService services.CoinExpiryServiceInterface) *InformationHandler {
 	return &InformationHandler{
 		userService:        userService,
 		transactionService: transactionService,
 		inventoryService:   inventoryService,
 		merchService:       merchService,
+		coinExpiryService:  coinExpiryService,
 	}
 }
 
+// purchaseHistoryItem is a purchase in the coin history, priced as it was bought.
+type purchaseHistoryItem struct {
+	Item      string `json:"item"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int    `json:"unitPrice"`
+	Total     int    `json:"total"`
+}
+
 func (h *InformationHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 	employeeUsername, ok := middleware.GetEmployeeUsername(r.Context())
 	if !ok {
@@ -54,8 +65,10 @@ func (h *InformationHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := struct {
-		Coins     int `json:"coins"`
-		Inventory []struct {
+		Coins int `json:"coins"`
+		// ExpiringCoins tells when the coins expire unless they are spent, the soonest first.
+		ExpiringCoins []models.CoinExpiration `json:"expiringCoins,omitempty"`
+		Inventory     []struct {
 			Type     string `json:"type"`
 			Quantity int    `json:"quantity"`
 		} `json:"inventory"`
@@ -63,11 +76,16 @@ func (h *InformationHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 			Received []struct {
 				FromUser string `json:"fromUser"`
 				Amount   int    `json:"amount"`
+				Message  string `json:"message,omitempty"`
+				Category string `json:"category,omitempty"`
 			} `json:"received"`
 			Sent []struct {
-				ToUser string `json:"toUser"`
-				Amount int    `json:"amount"`
+				ToUser   string `json:"toUser"`
+				Amount   int    `json:"amount"`
+				Message  string `json:"message,omitempty"`
+				Category string `json:"category,omitempty"`
 			} `json:"sent"`
+			Purchases []purchaseHistoryItem `json:"purchases"`
 		} `json:"coinHistory"`
 	}{
 		Coins: user.Coins,
@@ -78,17 +96,25 @@ func (h *InformationHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 			response.CoinHistory.Received = append(response.CoinHistory.Received, struct {
 				FromUser string `json:"fromUser"`
 				Amount   int    `json:"amount"`
+				Message  string `json:"message,omitempty"`
+				Category string `json:"category,omitempty"`
 			}{
 				FromUser: txn.CounterpartUser,
 				Amount:   txn.Amount,
+				Message:  txn.Message,
+				Category: txn.Category,
 			})
 		} else if txn.TransactionType == "sent" {
 			response.CoinHistory.Sent = append(response.CoinHistory.Sent, struct {
-				ToUser string `json:"toUser"`
-				Amount int    `json:"amount"`
+				ToUser   string `json:"toUser"`
+				Amount   int    `json:"amount"`
+				Message  string `json:"message,omitempty"`
+				Category string `json:"category,omitempty"`
 			}{
-				ToUser: txn.CounterpartUser,
-				Amount: txn.Amount,
+				ToUser:   txn.CounterpartUser,
+				Amount:   txn.Amount,
+				Message:  txn.Message,
+				Category: txn.Category,
 			})
 		}
 	}
@@ -113,6 +139,26 @@ func (h *InformationHandler) GetInfo(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	purchases, err := h.inventoryService.GetPurchasesByUserID(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching purchases: %v", err), http.StatusInternalServerError)
+		return
+	}
+	for _, purchase := range purchases {
+		response.CoinHistory.Purchases = append(response.CoinHistory.Purchases, purchaseHistoryItem{
+			Item:      purchase.ItemName,
+			Quantity:  purchase.Quantity,
+			UnitPrice: purchase.UnitPrice,
+			Total:     purchase.Total(),
+		})
+	}
+
+	response.ExpiringCoins, err = h.coinExpiryService.GetUpcomingExpirations(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching coin expirations: %v", err), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err = json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, fmt.Sprintf("Error encoding response: %v", err), http.StatusInternalServerError)
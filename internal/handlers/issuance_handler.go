@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+)
+
+// IssueCoinsRequest names either a user or a department whose active members all get the
+// same amount.
+type IssueCoinsRequest struct {
+	Username   string `json:"username"`
+	Department string `json:"department"`
+	Amount     int    `json:"amount"`
+	Reason     string `json:"reason"`
+}
+
+type IssuanceHandler struct {
+	issuanceService services.IssuanceServiceInterface
+}
+
+func NewIssuanceHandler(issuanceService services.IssuanceServiceInterface) *IssuanceHandler {
+	return &IssuanceHandler{issuanceService: issuanceService}
+}
+
+// GrantCoins mints coins for a user or a department.
+func (h *IssuanceHandler) GrantCoins(w http.ResponseWriter, r *http.Request) {
+	h.issue(w, r, models.IssuanceGrant)
+}
+
+// BurnCoins takes coins away from a user or a department.
+func (h *IssuanceHandler) BurnCoins(w http.ResponseWriter, r *http.Request) {
+	h.issue(w, r, models.IssuanceBurn)
+}
+
+func (h *IssuanceHandler) issue(w http.ResponseWriter, r *http.Request, operation string) {
+	admin, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req IssueCoinsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	issuance := &models.CoinIssuance{
+		Operation:  operation,
+		Username:   req.Username,
+		Department: req.Department,
+		Amount:     req.Amount,
+		Reason:     req.Reason,
+		IssuedBy:   admin,
+	}
+	err := h.issuanceService.Issue(r.Context(), issuance)
+	if errors.Is(err, models.ErrInvalidIssuance) || errors.Is(err, models.ErrInsufficientFunds) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error issuing coins: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, issuance)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+type JWKSHandler struct {
+	tokenManager auth.TokenManager
+}
+
+func NewJWKSHandler(tokenManager auth.TokenManager) *JWKSHandler {
+	return &JWKSHandler{tokenManager: tokenManager}
+}
+
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(h.tokenManager.JWKS()); err != nil {
+		http.Error(w, fmt.Sprintf("Error encoding response: %v", err), http.StatusInternalServerError)
+	}
+}
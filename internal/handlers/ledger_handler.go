@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/avito-shop-service/internal/services"
+)
+
+type LedgerHandler struct {
+	ledgerService services.LedgerServiceInterface
+}
+
+func NewLedgerHandler(ledgerService services.LedgerServiceInterface) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+// VerifyLedger reports whether the ledger invariants hold. The report is returned with 200
+// either way; its balanced field tells the outcome.
+func (h *LedgerHandler) VerifyLedger(w http.ResponseWriter, r *http.Request) {
+	report, err := h.ledgerService.VerifyLedger(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error verifying ledger: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+)
+
+type CreateMerchRequest struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+type MerchHandler struct {
+	merchService services.MerchServiceInterface
+}
+
+func NewMerchHandler(merchService services.MerchServiceInterface) *MerchHandler {
+	return &MerchHandler{merchService: merchService}
+}
+
+func (h *MerchHandler) CreateMerch(w http.ResponseWriter, r *http.Request) {
+	var req CreateMerchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || req.Price < 0 {
+		http.Error(w, "invalid name or price", http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.merchService.GetMerchByName(r.Context(), req.Name)
+	if err == nil {
+		http.Error(w, "merch with this name already exists", http.StatusConflict)
+		return
+	}
+	if !errors.Is(err, models.ErrMerchNotFound) {
+		http.Error(w, fmt.Sprintf("Error fetching merch: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	err = h.merchService.CreateMerch(r.Context(), &models.Merch{ItemName: req.Name, Price: req.Price})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating merch: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(map[string]string{"message": "merch created"})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error encoding response: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+)
+
+const (
+	oidcCookieName   = "oidc_login"
+	oidcCookiePath   = "/api/auth/oidc"
+	oidcCookieMaxAge = 600
+)
+
+type OIDCHandler struct {
+	userService   services.UserServiceInterface
+	authenticator services.AuthCodeAuthenticator
+}
+
+func NewOIDCHandler(userService services.UserServiceInterface, authenticator services.AuthCodeAuthenticator) *OIDCHandler {
+	return &OIDCHandler{userService: userService, authenticator: authenticator}
+}
+
+// Login redirects to the identity provider. The state, nonce and PKCE verifier of the login
+// are kept in a short-lived cookie until the provider redirects back to Callback.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	state, nonce, verifier, err := newOIDCLoginSecrets()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error starting login: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	redirectURL, err := h.authenticator.AuthCodeURL(r.Context(), state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error starting login: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    strings.Join([]string{state, nonce, verifier}, "."),
+		Path:     oidcCookiePath,
+		MaxAge:   oidcCookieMaxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// Callback completes the login started by Login and responds with the shop tokens.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(oidcCookieName)
+	if err != nil {
+		http.Error(w, "login session not found", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcCookieName, Path: oidcCookiePath, MaxAge: -1, HttpOnly: true})
+
+	parts := strings.Split(cookie.Value, ".")
+	query := r.URL.Query()
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(query.Get("state"))) != 1 {
+		http.Error(w, "invalid login state", http.StatusBadRequest)
+		return
+	}
+	if providerErr := query.Get("error"); providerErr != "" {
+		http.Error(w, fmt.Sprintf("Login failed: %s", providerErr), http.StatusUnauthorized)
+		return
+	}
+
+	tokens, err := h.userService.AuthenticateExternal(r.Context(), h.authenticator.Name(), models.Credentials{
+		Code:         query.Get("code"),
+		Nonce:        parts[1],
+		CodeVerifier: parts[2],
+	}, clientInfo(r))
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, models.ErrAccountInactive) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error authenticating user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, http.StatusOK, tokens)
+}
+
+func newOIDCLoginSecrets() (state, nonce, verifier string, err error) {
+	values := make([]string, 3)
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err = rand.Read(b); err != nil {
+			return "", "", "", fmt.Errorf("error generating login secrets: %v", err)
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return values[0], values[1], values[2], nil
+}
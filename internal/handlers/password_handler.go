@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+type ResetPasswordRequest struct {
+	ResetToken  string `json:"resetToken"`
+	NewPassword string `json:"newPassword"`
+}
+
+type PasswordHandler struct {
+	passwordService services.PasswordServiceInterface
+}
+
+func NewPasswordHandler(passwordService services.PasswordServiceInterface) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService}
+}
+
+// ChangePassword sets a new password for the current user. All sessions of the user end,
+// including the one the request was made with.
+func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.OldPassword == "" || req.NewPassword == "" {
+		http.Error(w, "oldPassword and newPassword are required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.passwordService.ChangePassword(r.Context(), username, req.OldPassword, req.NewPassword, clientInfo(r))
+	var blockedErr *models.LoginBlockedError
+	if errors.As(err, &blockedErr) {
+		writeLoginBlocked(w, blockedErr)
+		return
+	}
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		http.Error(w, "invalid old password", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, models.ErrNoLocalPassword) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error changing password: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateResetToken issues a password reset token for the user named in the path. The token
+// is returned only in this response and has to be handed to the user out of band.
+func (h *PasswordHandler) CreateResetToken(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := h.passwordService.CreateResetToken(r.Context(), chi.URLParam(r, "username"), admin)
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, models.ErrNoLocalPassword) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating password reset token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, token)
+}
+
+// ResetPassword sets a new password with a reset token. It does not require authentication.
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ResetToken == "" || req.NewPassword == "" {
+		http.Error(w, "resetToken and newPassword are required", http.StatusBadRequest)
+		return
+	}
+
+	err := h.passwordService.ResetPassword(r.Context(), req.ResetToken, req.NewPassword)
+	if errors.Is(err, models.ErrInvalidResetToken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error resetting password: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
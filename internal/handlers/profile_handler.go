@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// UpdateProfileRequest changes the fields that are present. An empty string clears a field.
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"displayName"`
+	Email       *string `json:"email"`
+	Department  *string `json:"department"`
+	Manager     *string `json:"manager"`
+	HireDate    *string `json:"hireDate"`
+	Status      *string `json:"status"`
+}
+
+type SetStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type SetDepartmentRequest struct {
+	Department string `json:"department"`
+}
+
+type ProfileResponse struct {
+	Username string `json:"username"`
+	models.Profile
+}
+
+type ProfileHandler struct {
+	profileService services.ProfileServiceInterface
+}
+
+func NewProfileHandler(profileService services.ProfileServiceInterface) *ProfileHandler {
+	return &ProfileHandler{profileService: profileService}
+}
+
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	profile, err := h.profileService.GetProfile(r.Context(), username)
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ProfileResponse{Username: username, Profile: *profile})
+}
+
+// UpdateProfile changes the profile of the current user. The account status and the department
+// are managed by administrators only.
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Status != nil {
+		http.Error(w, "status can only be changed by an administrator", http.StatusForbidden)
+		return
+	}
+	if req.Department != nil {
+		http.Error(w, "department can only be changed by an administrator", http.StatusForbidden)
+		return
+	}
+
+	profile, err := h.profileService.UpdateProfile(r.Context(), username, models.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+		Manager:     req.Manager,
+		HireDate:    req.HireDate,
+	})
+	if errors.Is(err, models.ErrInvalidProfile) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ProfileResponse{Username: username, Profile: *profile})
+}
+
+// SetUserStatus suspends, terminates or reactivates the user named in the path.
+func (h *ProfileHandler) SetUserStatus(w http.ResponseWriter, r *http.Request) {
+	var req SetStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.profileService.SetStatus(r.Context(), chi.URLParam(r, "username"), req.Status)
+	if errors.Is(err, models.ErrInvalidStatus) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetUserDepartment moves the user named in the path to another department.
+func (h *ProfileHandler) SetUserDepartment(w http.ResponseWriter, r *http.Request) {
+	var req SetDepartmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.profileService.SetDepartment(r.Context(), chi.URLParam(r, "username"), req.Department)
+	if errors.Is(err, models.ErrInvalidProfile) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating department: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type CreateScheduledTransferRequest struct {
+	ToUser   string `json:"toUser"`
+	Amount   int    `json:"amount"`
+	Message  string `json:"message,omitempty"`
+	Category string `json:"category,omitempty"`
+	Schedule string `json:"schedule"`
+}
+
+// UpdateScheduledTransferRequest changes the fields that are present; active pauses or resumes
+// the transfer.
+type UpdateScheduledTransferRequest struct {
+	Amount   *int    `json:"amount"`
+	Message  *string `json:"message"`
+	Category *string `json:"category"`
+	Schedule *string `json:"schedule"`
+	Active   *bool   `json:"active"`
+}
+
+type ScheduledTransferHandler struct {
+	scheduledTransferService services.ScheduledTransferServiceInterface
+}
+
+func NewScheduledTransferHandler(scheduledTransferService services.ScheduledTransferServiceInterface) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{scheduledTransferService: scheduledTransferService}
+}
+
+func (h *ScheduledTransferHandler) CreateScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	owner, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateScheduledTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transfer := &models.ScheduledTransfer{
+		Owner:    owner,
+		ToUser:   req.ToUser,
+		Amount:   req.Amount,
+		Message:  strings.TrimSpace(req.Message),
+		Category: req.Category,
+		Schedule: req.Schedule,
+	}
+	err := h.scheduledTransferService.CreateScheduledTransfer(r.Context(), transfer)
+	if errors.Is(err, models.ErrInvalidScheduledTransfer) || errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating scheduled transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, transfer)
+}
+
+func (h *ScheduledTransferHandler) GetScheduledTransfers(w http.ResponseWriter, r *http.Request) {
+	owner, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	transfers, err := h.scheduledTransferService.GetScheduledTransfers(r.Context(), owner)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching scheduled transfers: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if transfers == nil {
+		transfers = []models.ScheduledTransfer{}
+	}
+
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+func (h *ScheduledTransferHandler) GetScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := scheduledTransferTarget(w, r)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.scheduledTransferService.GetScheduledTransfer(r.Context(), owner, id)
+	if errors.Is(err, models.ErrScheduledTransferNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching scheduled transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transfer)
+}
+
+func (h *ScheduledTransferHandler) UpdateScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := scheduledTransferTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateScheduledTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Message != nil {
+		message := strings.TrimSpace(*req.Message)
+		req.Message = &message
+	}
+
+	transfer, err := h.scheduledTransferService.UpdateScheduledTransfer(r.Context(), owner, id, models.ScheduledTransferUpdate{
+		Amount:   req.Amount,
+		Message:  req.Message,
+		Category: req.Category,
+		Schedule: req.Schedule,
+		Active:   req.Active,
+	})
+	if errors.Is(err, models.ErrInvalidScheduledTransfer) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrScheduledTransferNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error updating scheduled transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transfer)
+}
+
+func (h *ScheduledTransferHandler) DeleteScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := scheduledTransferTarget(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.scheduledTransferService.DeleteScheduledTransfer(r.Context(), owner, id)
+	if errors.Is(err, models.ErrScheduledTransferNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting scheduled transfer: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetScheduledTransferRuns returns the latest runs of a scheduled transfer, including failed ones.
+func (h *ScheduledTransferHandler) GetScheduledTransferRuns(w http.ResponseWriter, r *http.Request) {
+	owner, id, ok := scheduledTransferTarget(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.scheduledTransferService.GetScheduledTransferRuns(r.Context(), owner, id)
+	if errors.Is(err, models.ErrScheduledTransferNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching scheduled transfer runs: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if runs == nil {
+		runs = []models.ScheduledTransferRun{}
+	}
+
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// scheduledTransferTarget returns the current user and the scheduled transfer id from the path,
+// or writes an error response.
+func scheduledTransferTarget(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	owner, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid scheduled transfer id", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return owner, id, true
+}
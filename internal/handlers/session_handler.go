@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionHandler struct {
+	sessionService services.SessionServiceInterface
+}
+
+func NewSessionHandler(sessionService services.SessionServiceInterface) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// GetSessions lists the active sessions of the current user.
+func (h *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+	h.writeSessions(w, r, claims.EmployeeUsername, claims.SessionID)
+}
+
+// RevokeSession ends a session of the current user, e.g. on a lost device.
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+	h.revokeSession(w, r, username)
+}
+
+// GetUserSessions lists the active sessions of the user named in the path.
+func (h *SessionHandler) GetUserSessions(w http.ResponseWriter, r *http.Request) {
+	h.writeSessions(w, r, chi.URLParam(r, "username"), 0)
+}
+
+// RevokeUserSession ends a session of the user named in the path.
+func (h *SessionHandler) RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	h.revokeSession(w, r, chi.URLParam(r, "username"))
+}
+
+// GetLoginEvents returns the login audit trail, optionally filtered by username, client address
+// and time, newest first.
+func (h *SessionHandler) GetLoginEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.LoginEventFilter{Username: query.Get("username"), IP: query.Get("ip")}
+	since, err := parseTimeParam(query, "since")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Since = since
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := h.sessionService.GetLoginEvents(r.Context(), filter)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching login events: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if events == nil {
+		events = []models.LoginEvent{}
+	}
+
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (h *SessionHandler) writeSessions(w http.ResponseWriter, r *http.Request, username string, currentSessionID int64) {
+	sessions, err := h.sessionService.GetSessions(r.Context(), username, currentSessionID)
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching sessions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) revokeSession(w http.ResponseWriter, r *http.Request, username string) {
+	sessionID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid session id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.sessionService.RevokeSession(r.Context(), username, sessionID)
+	if errors.Is(err, models.ErrUserNotFound) || errors.Is(err, models.ErrSessionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error revoking session: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
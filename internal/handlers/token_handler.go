@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type TokenHandler struct {
+	tokenService services.TokenServiceInterface
+}
+
+func NewTokenHandler(tokenService services.TokenServiceInterface) *TokenHandler {
+	return &TokenHandler{tokenService: tokenService}
+}
+
+func (h *TokenHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "refresh token is required", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.tokenService.Refresh(r.Context(), req.RefreshToken, clientInfo(r))
+	if errors.Is(err, models.ErrInvalidRefreshToken) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, models.ErrAccountInactive) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error refreshing token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeTokens(w, http.StatusOK, tokens)
+}
+
+// Logout revokes the access token used for the request and, when it is passed in the body,
+// the refresh token of the same session.
+func (h *TokenHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tokenService.Logout(r.Context(), claims, req.RefreshToken); err != nil {
+		http.Error(w, fmt.Sprintf("Error logging out: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/avito-shop-service/internal/models"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/avito-shop-service/internal/middleware"
@@ -12,8 +15,35 @@ import (
 )
 
 type SendCoinRequest struct {
-	ToUser string `json:"toUser"`
-	Amount int    `json:"amount"`
+	ToUser   string `json:"toUser"`
+	Amount   int    `json:"amount"`
+	Message  string `json:"message,omitempty"`
+	Category string `json:"category,omitempty"`
+}
+
+type SendCoinBatchRequest struct {
+	Transfers []SendCoinRequest `json:"transfers"`
+}
+
+// Statuses of the transfers of a batch: all are sent, or the failed one is reported and the
+// others are skipped.
+const (
+	BatchTransferSent    = "sent"
+	BatchTransferFailed  = "failed"
+	BatchTransferSkipped = "skipped"
+)
+
+type BatchTransferResult struct {
+	ToUser     string `json:"toUser"`
+	Amount     int    `json:"amount"`
+	Status     string `json:"status"`
+	TransferID int64  `json:"transferId,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type SendCoinBatchResponse struct {
+	Error   string                `json:"error,omitempty"`
+	Results []BatchTransferResult `json:"results"`
 }
 
 type TransactionHandler struct {
@@ -51,18 +81,29 @@ func (h *TransactionHandler) SendCoin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user.Coins < req.Amount {
-		http.Error(w, "not enough money to send", http.StatusBadRequest)
-		return
-	}
-
 	err = h.transactionService.CreateTransaction(r.Context(), &models.CoinTransaction{
 		UserID:          user.ID,
+		FromUser:        fromUser,
 		CounterpartUser: req.ToUser,
 		Amount:          req.Amount,
 		TransactionType: "send",
+		Message:         strings.TrimSpace(req.Message),
+		Category:        req.Category,
 		CreatedAt:       time.Now(),
 	})
+	if errors.Is(err, models.ErrInsufficientFunds) {
+		http.Error(w, "not enough money to send", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrTransferLimitExceeded) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrAccountInactive) || errors.Is(err, models.ErrUserNotFound) ||
+		errors.Is(err, models.ErrUnknownCategory) || errors.Is(err, models.ErrMessageTooLong) || errors.Is(err, models.ErrSelfTransfer) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to send coins: %v", err), http.StatusInternalServerError)
 		return
@@ -73,3 +114,194 @@ func (h *TransactionHandler) SendCoin(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Error encoding response: %v", err), http.StatusInternalServerError)
 	}
 }
+
+// SendCoinBatch sends coins to several recipients in one database transaction and reports the
+// result of every transfer. If one of them fails, none is sent.
+func (h *TransactionHandler) SendCoinBatch(w http.ResponseWriter, r *http.Request) {
+	fromUser, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SendCoinBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Transfers) == 0 || len(req.Transfers) > services.MaxBatchTransfers {
+		http.Error(w, fmt.Sprintf("transfers must have 1 to %d entries", services.MaxBatchTransfers), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.GetUserByUsername(r.Context(), fromUser)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	transactions := make([]models.CoinTransaction, len(req.Transfers))
+	for i, transfer := range req.Transfers {
+		transactions[i] = models.CoinTransaction{
+			UserID:          user.ID,
+			FromUser:        fromUser,
+			CounterpartUser: transfer.ToUser,
+			Amount:          transfer.Amount,
+			TransactionType: "send",
+			Message:         strings.TrimSpace(transfer.Message),
+			Category:        transfer.Category,
+			CreatedAt:       now,
+		}
+	}
+
+	err = h.transactionService.CreateTransactions(r.Context(), transactions)
+	var batchErr *models.TransferBatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		if status := transferErrorStatus(err); status != http.StatusInternalServerError {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		http.Error(w, fmt.Sprintf("failed to send coins: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := SendCoinBatchResponse{Results: make([]BatchTransferResult, len(transactions))}
+	for i, transaction := range transactions {
+		result := BatchTransferResult{ToUser: transaction.CounterpartUser, Amount: transaction.Amount, Status: BatchTransferSent, TransferID: transaction.ID}
+		if batchErr != nil {
+			result.Status, result.TransferID = BatchTransferSkipped, 0
+			if i == batchErr.Index {
+				result.Status, result.Error = BatchTransferFailed, batchErr.Err.Error()
+			}
+		}
+		response.Results[i] = result
+	}
+	if batchErr != nil {
+		response.Error = err.Error()
+		writeJSON(w, transferErrorStatus(err), response)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// transferErrorStatus is the response status of a failed transfer: 400 for the errors the
+// sender can fix, 500 otherwise.
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrInsufficientFunds), errors.Is(err, models.ErrTransferLimitExceeded),
+		errors.Is(err, models.ErrAccountInactive), errors.Is(err, models.ErrUserNotFound),
+		errors.Is(err, models.ErrUnknownCategory), errors.Is(err, models.ErrMessageTooLong), errors.Is(err, models.ErrSelfTransfer),
+		errors.Is(err, models.ErrInvalidBatchTransfer):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetCategories lists the kudos categories a transfer may be tagged with.
+func (h *TransactionHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.transactionService.Categories())
+}
+
+// GetTransfers returns transfers between users, optionally filtered by a party, the kudos
+// category and time, newest first.
+func (h *TransactionHandler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.TransferFilter{Username: query.Get("username"), Category: query.Get("category")}
+	var err error
+	if filter.Since, err = parseTimeParam(query, "since"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Until, err = parseTimeParam(query, "until"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	transfers, err := h.transactionService.GetTransfers(r.Context(), filter)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching transfers: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if transfers == nil {
+		transfers = []models.Transfer{}
+	}
+
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+// GetTransactions returns a page of the coin history of the user. The history can be filtered by
+// direction, counterpart, type and time and is sorted newest first unless order=asc; the
+// nextCursor of a page is passed as cursor to fetch the next one.
+func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.TransactionFilter{
+		Direction:   query.Get("direction"),
+		Counterpart: query.Get("counterpart"),
+		Type:        query.Get("type"),
+	}
+	var err error
+	if filter.Since, err = parseTimeParam(query, "since"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Until, err = parseTimeParam(query, "until"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		http.Error(w, "order must be asc or desc", http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	user, err := h.userService.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching user: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	filter.UserID = user.ID
+
+	page, err := h.transactionService.GetHistory(r.Context(), filter, query.Get("cursor"))
+	if errors.Is(err, models.ErrInvalidHistoryQuery) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching transactions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetTransferLimitExceptionRequest overrides the limits that are present; 0 lifts a limit.
+type SetTransferLimitExceptionRequest struct {
+	MaxPerTransfer *int       `json:"maxPerTransfer"`
+	DailyLimit     *int       `json:"dailyLimit"`
+	MonthlyLimit   *int       `json:"monthlyLimit"`
+	RecipientLimit *int       `json:"recipientLimit"`
+	Reason         string     `json:"reason"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+}
+
+type TransferLimitHandler struct {
+	transferLimitService services.TransferLimitServiceInterface
+}
+
+func NewTransferLimitHandler(transferLimitService services.TransferLimitServiceInterface) *TransferLimitHandler {
+	return &TransferLimitHandler{transferLimitService: transferLimitService}
+}
+
+// GetTransferLimits returns the transfer limits of the user named in the path and their exception.
+func (h *TransferLimitHandler) GetTransferLimits(w http.ResponseWriter, r *http.Request) {
+	limits, err := h.transferLimitService.GetLimits(r.Context(), chi.URLParam(r, "username"))
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error fetching transfer limits: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, limits)
+}
+
+// SetTransferLimitException grants the user named in the path an exception to the transfer
+// limits, replacing the previous one.
+func (h *TransferLimitHandler) SetTransferLimitException(w http.ResponseWriter, r *http.Request) {
+	admin, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req SetTransferLimitExceptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	exception := &models.TransferLimitException{
+		Username:       chi.URLParam(r, "username"),
+		MaxPerTransfer: req.MaxPerTransfer,
+		DailyLimit:     req.DailyLimit,
+		MonthlyLimit:   req.MonthlyLimit,
+		RecipientLimit: req.RecipientLimit,
+		Reason:         req.Reason,
+		ExpiresAt:      req.ExpiresAt,
+		CreatedBy:      admin,
+	}
+	err := h.transferLimitService.SetException(r.Context(), exception)
+	if errors.Is(err, models.ErrInvalidLimitException) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, models.ErrUserNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error saving transfer limit exception: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, exception)
+}
+
+// DeleteTransferLimitException puts the user named in the path back on the configured limits.
+func (h *TransferLimitHandler) DeleteTransferLimitException(w http.ResponseWriter, r *http.Request) {
+	err := h.transferLimitService.DeleteException(r.Context(), chi.URLParam(r, "username"))
+	if errors.Is(err, models.ErrUserNotFound) || errors.Is(err, models.ErrLimitExceptionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting transfer limit exception: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ReverseTransferRequest struct {
+	Reason string `json:"reason"`
+}
+
+type TransferReversalHandler struct {
+	transferReversalService services.TransferReversalServiceInterface
+}
+
+func NewTransferReversalHandler(transferReversalService services.TransferReversalServiceInterface) *TransferReversalHandler {
+	return &TransferReversalHandler{transferReversalService: transferReversalService}
+}
+
+// CancelTransfer returns the coins of a recent transfer of the current user to them.
+func (h *TransferReversalHandler) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	username, id, ok := transferTarget(w, r)
+	if !ok {
+		return
+	}
+
+	reversal, err := h.transferReversalService.CancelTransfer(r.Context(), username, id)
+	if err != nil {
+		writeReversalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reversal)
+}
+
+// ReverseTransfer returns the coins of any transfer to its sender on behalf of an admin.
+func (h *TransferReversalHandler) ReverseTransfer(w http.ResponseWriter, r *http.Request) {
+	admin, id, ok := transferTarget(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReverseTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reversal, err := h.transferReversalService.ReverseTransfer(r.Context(), admin, id, req.Reason)
+	if err != nil {
+		writeReversalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reversal)
+}
+
+func writeReversalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidReversal):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrTransferAlreadyReversed), errors.Is(err, models.ErrTransferNotReversible):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, fmt.Sprintf("Error reversing transfer: %v", err), http.StatusInternalServerError)
+	}
+}
+
+// transferTarget returns the current user and the transfer id from the path, or writes an
+// error response.
+func transferTarget(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	username, ok := middleware.GetEmployeeUsername(r.Context())
+	if !ok {
+		http.Error(w, "user not authorized", http.StatusUnauthorized)
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid transfer id", http.StatusBadRequest)
+		return "", 0, false
+	}
+	return username, id, true
+}
@@ -2,9 +2,15 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
 	"github.com/avito-shop-service/pkg/auth"
 )
 
@@ -12,27 +18,103 @@ type key string
 
 const EmployeeUsernameKey key = "username"
 
-func AuthMiddleware(next http.Handler) http.Handler {
+const ClaimsKey key = "claims"
+
+const APIKeyHeader = "X-Api-Key"
+
+type AuthMiddleware struct {
+	tokenService  services.TokenServiceInterface
+	apiKeyService services.APIKeyServiceInterface
+}
+
+func NewAuthMiddleware(tokenService services.TokenServiceInterface, apiKeyService services.APIKeyServiceInterface) *AuthMiddleware {
+	return &AuthMiddleware{tokenService: tokenService, apiKeyService: apiKeyService}
+}
+
+// Handle authenticates the request with an API key from the X-Api-Key header or, when there
+// is none, with a bearer token.
+func (m *AuthMiddleware) Handle(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+			m.handleAPIKey(w, r, next, apiKey)
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
 			http.Error(w, "missing authorization header", http.StatusUnauthorized)
 			return
 		}
 
-		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		claims, err := auth.ParseToken(tokenStr)
+		scheme, tokenStr, _ := strings.Cut(authHeader, " ")
+		tokenStr = strings.TrimSpace(tokenStr)
+		if !strings.EqualFold(scheme, "Bearer") || tokenStr == "" {
+			writeUnauthorized(w, "invalid_request", "authorization header must be \"Bearer <token>\"")
+			return
+		}
+
+		claims, err := m.tokenService.ValidateAccessToken(r.Context(), tokenStr)
+		if errors.Is(err, models.ErrAccountInactive) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, models.ErrTokenRevoked) || errors.Is(err, models.ErrInvalidToken) {
+			writeUnauthorized(w, "invalid_token", err.Error())
+			return
+		}
 		if err != nil {
-			http.Error(w, "invalid token", http.StatusUnauthorized)
+			http.Error(w, fmt.Sprintf("Error validating token: %v", err), http.StatusInternalServerError)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), EmployeeUsernameKey, claims.EmployeeUsername)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		serveWithClaims(w, r, next, claims)
 	})
 }
 
+func (m *AuthMiddleware) handleAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, apiKey string) {
+	claims, err := m.apiKeyService.ValidateAPIKey(r.Context(), apiKey)
+	var rateLimitedErr *models.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitedErr.RetryAfter.Seconds()))))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, models.ErrAccountInactive) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, models.ErrInvalidAPIKey) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error validating api key: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	serveWithClaims(w, r, next, claims)
+}
+
+// writeUnauthorized explains in the body and, as RFC 6750 asks, in the WWW-Authenticate header
+// why the token was rejected.
+func writeUnauthorized(w http.ResponseWriter, code, reason string) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error=%q, error_description=%q`, code, reason))
+	http.Error(w, reason, http.StatusUnauthorized)
+}
+
+func serveWithClaims(w http.ResponseWriter, r *http.Request, next http.Handler, claims *auth.Claims) {
+	ctx := context.WithValue(r.Context(), EmployeeUsernameKey, claims.EmployeeUsername)
+	ctx = context.WithValue(ctx, ClaimsKey, claims)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 func GetEmployeeUsername(ctx context.Context) (string, bool) {
 	employeeUsername, ok := ctx.Value(EmployeeUsernameKey).(string)
 	return employeeUsername, ok
 }
+
+func GetClaims(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(ClaimsKey).(*auth.Claims)
+	return claims, ok
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// RequireRole lets the request through when the token carries at least one of the roles.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok {
+				http.Error(w, "user not authorized", http.StatusUnauthorized)
+				return
+			}
+			for _, role := range roles {
+				if claims.HasRole(role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "insufficient role", http.StatusForbidden)
+		})
+	}
+}
+
+// RequireScope lets the request through only when the token carries every one of the scopes.
+func RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r.Context())
+			if !ok {
+				http.Error(w, "user not authorized", http.StatusUnauthorized)
+				return
+			}
+			for _, scope := range scopes {
+				if !claims.HasScope(scope) {
+					http.Error(w, "missing scope "+scope, http.StatusForbidden)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireToken refuses requests authenticated with an API key, so that a key cannot be used
+// to manage credentials of its owner.
+func RequireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetClaims(r.Context())
+		if !ok {
+			http.Error(w, "user not authorized", http.StatusUnauthorized)
+			return
+		}
+		if claims.APIKeyID != 0 {
+			http.Error(w, "api keys are not allowed for this endpoint", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
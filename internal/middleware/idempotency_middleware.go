@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+type IdempotencyMiddleware struct {
+	idempotencyService services.IdempotencyServiceInterface
+}
+
+func NewIdempotencyMiddleware(idempotencyService services.IdempotencyServiceInterface) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{idempotencyService: idempotencyService}
+}
+
+// Handle makes requests with an Idempotency-Key header safe to retry: the first request with
+// a key is processed and its response stored, later ones with the same key and payload get the
+// stored response replayed. The key is scoped to the authenticated user, so the middleware
+// must run after AuthMiddleware. Requests without the header are passed through.
+func (m *IdempotencyMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		username, ok := GetEmployeeUsername(r.Context())
+		if !ok {
+			http.Error(w, "user not authorized", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		if err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := m.idempotencyService.Begin(r.Context(), username, key, fingerprint(r, body))
+		switch {
+		case errors.Is(err, models.ErrInvalidIdempotency):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, models.ErrIdempotencyMismatch):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, models.ErrIdempotencyConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, fmt.Sprintf("Error checking idempotency key: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if stored != nil {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(stored.StatusCode)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// A client that timed out and went away will retry, so the outcome must be stored even
+		// though its request context is cancelled.
+		ctx := context.WithoutCancel(r.Context())
+
+		// Server errors are stored and replayed too: a handler may fail after the coins have
+		// moved, and releasing the key would let a retry move them again.
+		err = m.idempotencyService.Complete(ctx, &models.IdempotencyRecord{
+			Username:    username,
+			Key:         key,
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			log.Printf("error storing idempotent response of %s: %v", username, err)
+		}
+	})
+}
+
+// fingerprint identifies the request a key was used for by its method, path and body.
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
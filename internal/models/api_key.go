@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// APIKey is a long-lived credential of a user for bots and integrations. Only the hash of
+// the key is stored; Prefix identifies the key in listings.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rateLimit"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreatedAPIKey carries the plain key, which is shown only once, on creation.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
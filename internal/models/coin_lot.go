@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// CoinLot is a credit to a user: Amount coins granted at GrantedAt by the ledger entry EntryID,
+// of which Remaining are left. Debits consume the oldest lots first.
+type CoinLot struct {
+	ID        int64     `json:"id"`
+	EntryID   *int64    `json:"entryId,omitempty"`
+	Amount    int       `json:"amount"`
+	Remaining int       `json:"remaining"`
+	GrantedAt time.Time `json:"grantedAt"`
+}
+
+// CoinExpiration is an amount of coins that expires at ExpiresAt unless it is spent before.
+type CoinExpiration struct {
+	Amount    int       `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrUserAlreadyExists         = errors.New("user with this username already exists")
+	ErrInvalidCredentials        = errors.New("invalid username or password")
+	ErrInvalidLoginRequest       = errors.New("invalid login request")
+	ErrInvalidRefreshToken       = errors.New("invalid refresh token")
+	ErrInvalidToken              = errors.New("invalid token")
+	ErrTokenRevoked              = errors.New("token has been revoked")
+	ErrMerchNotFound             = errors.New("merch not found")
+	ErrUserNotFound              = errors.New("user not found")
+	ErrLoginBlocked              = errors.New("too many failed login attempts, try again later")
+	ErrInvalidAPIKey             = errors.New("invalid api key")
+	ErrAPIKeyNotFound            = errors.New("api key not found")
+	ErrInvalidScopes             = errors.New("requested scopes are not granted to the user")
+	ErrInvalidRateLimit          = errors.New("invalid rate limit")
+	ErrRateLimitExceeded         = errors.New("rate limit exceeded")
+	ErrUnknownProvider           = errors.New("unknown identity provider")
+	ErrInvalidResetToken         = errors.New("invalid or expired password reset token")
+	ErrNoLocalPassword           = errors.New("account signs in with an external provider and has no local password")
+	ErrSessionNotFound           = errors.New("session not found")
+	ErrAccountInactive           = errors.New("account is not active")
+	ErrInvalidProfile            = errors.New("invalid profile")
+	ErrInvalidStatus             = errors.New("invalid account status")
+	ErrInsufficientFunds         = errors.New("not enough coins")
+	ErrInvalidIdempotency        = errors.New("invalid idempotency key")
+	ErrIdempotencyMismatch       = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyConflict       = errors.New("a request with this idempotency key is still being processed")
+	ErrUnknownCategory           = errors.New("unknown kudos category")
+	ErrMessageTooLong            = errors.New("transfer message is too long")
+	ErrSelfTransfer              = errors.New("cannot send coins to yourself")
+	ErrInvalidHistoryQuery       = errors.New("invalid transaction history query")
+	ErrInvalidScheduledTransfer  = errors.New("invalid scheduled transfer")
+	ErrScheduledTransferNotFound = errors.New("scheduled transfer not found")
+	ErrTransferLimitExceeded     = errors.New("transfer limit exceeded")
+	ErrInvalidLimitException     = errors.New("invalid transfer limit exception")
+	ErrLimitExceptionNotFound    = errors.New("transfer limit exception not found")
+	ErrTransferNotFound          = errors.New("transfer not found")
+	ErrTransferAlreadyReversed   = errors.New("transfer has already been reversed")
+	ErrTransferNotReversible     = errors.New("transfer cannot be reversed")
+	ErrInvalidReversal           = errors.New("invalid transfer reversal")
+	ErrInvalidIssuance           = errors.New("invalid coin issuance")
+	ErrInvalidBatchTransfer      = errors.New("invalid batch transfer")
+)
+
+// LoginBlockedError is returned while the username or the client address is blocked after
+// failed login attempts. It matches ErrLoginBlocked.
+type LoginBlockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return ErrLoginBlocked.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return ErrLoginBlocked
+}
+
+// RateLimitedError is returned when a client has used up its request quota. It matches
+// ErrRateLimitExceeded.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return ErrRateLimitExceeded.Error()
+}
+
+func (e *RateLimitedError) Unwrap() error {
+	return ErrRateLimitExceeded
+}
+
+// AccountInactiveError is returned for a suspended or terminated account. It matches
+// ErrAccountInactive.
+type AccountInactiveError struct {
+	Username string
+	Status   string
+}
+
+func (e *AccountInactiveError) Error() string {
+	if e.Username == "" {
+		return fmt.Sprintf("account is %s", e.Status)
+	}
+	return fmt.Sprintf("account %s is %s", e.Username, e.Status)
+}
+
+func (e *AccountInactiveError) Unwrap() error {
+	return ErrAccountInactive
+}
+
+// InsufficientFundsError is returned when a transfer or purchase would overdraw the balance. It
+// matches ErrInsufficientFunds.
+type InsufficientFundsError struct {
+	Balance  int
+	Required int
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("%s: balance is %d, %d required", ErrInsufficientFunds, e.Balance, e.Required)
+}
+
+func (e *InsufficientFundsError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// TransferLimitError is returned when a transfer would break the transfer limit rule named by
+// Rule. Used is what the sender already sent in the period of the rule. It matches
+// ErrTransferLimitExceeded.
+type TransferLimitError struct {
+	Rule  string
+	Limit int
+	Used  int
+}
+
+func (e *TransferLimitError) Error() string {
+	if e.Rule == RuleMaxPerTransfer {
+		return fmt.Sprintf("%s: %s is %d coins", ErrTransferLimitExceeded, e.Rule, e.Limit)
+	}
+	return fmt.Sprintf("%s: %s is %d coins, %d already sent", ErrTransferLimitExceeded, e.Rule, e.Limit, e.Used)
+}
+
+func (e *TransferLimitError) Unwrap() error {
+	return ErrTransferLimitExceeded
+}
+
+// TransferBatchError is returned when the transfer at Index of a batch, the one to ToUser, fails
+// and with it the whole batch. It matches the error of the transfer.
+type TransferBatchError struct {
+	Index  int
+	ToUser string
+	Err    error
+}
+
+func (e *TransferBatchError) Error() string {
+	return fmt.Sprintf("transfer to %s: %v", e.ToUser, e.Err)
+}
+
+func (e *TransferBatchError) Unwrap() error {
+	return e.Err
+}
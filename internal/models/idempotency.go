@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is the outcome of a request sent with an Idempotency-Key header. The
+// fingerprint identifies the request, so a key cannot be reused for a different one.
+type IdempotencyRecord struct {
+	Username    string
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the response of the request has been stored. Until then the
+// request is still being processed.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,20 @@
+package models
+
+// Credentials are passed to an identity provider. Password providers use Username and
+// Password, authorization code providers use Code, CodeVerifier and Nonce.
+type Credentials struct {
+	Username     string
+	Password     string
+	Code         string
+	CodeVerifier string
+	Nonce        string
+}
+
+// Identity is a user confirmed by an identity provider.
+type Identity struct {
+	Provider string
+	Subject  string
+	Username string
+	// User is the local account, when the provider had to load it anyway.
+	User *User
+}
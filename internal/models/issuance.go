@@ -0,0 +1,34 @@
+package models
+
+// Operations of a coin issuance.
+const (
+	IssuanceGrant = "grant"
+	IssuanceBurn  = "burn"
+)
+
+// CoinIssuance grants Amount coins to, or burns Amount coins of, the user named by Username or
+// every active member of Department. IssuedBy is the admin who did it; Entries holds the
+// resulting ledger entry of every user.
+type CoinIssuance struct {
+	Operation  string          `json:"operation"`
+	Username   string          `json:"username,omitempty"`
+	Department string          `json:"department,omitempty"`
+	Amount     int             `json:"amount"`
+	Reason     string          `json:"reason"`
+	IssuedBy   string          `json:"issuedBy"`
+	Entries    []IssuanceEntry `json:"entries"`
+}
+
+// IssuanceEntry is the ledger entry of an issuance for one user and the balance it left.
+type IssuanceEntry struct {
+	EntryID  int64  `json:"entryId"`
+	Username string `json:"username"`
+	Balance  int    `json:"balance"`
+}
+
+// Periods of the allowance.
+const (
+	AllowanceDaily   = "daily"
+	AllowanceWeekly  = "weekly"
+	AllowanceMonthly = "monthly"
+)
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Kinds of ledger entries.
+const (
+	EntryKindTransfer   = "transfer"
+	EntryKindPurchase   = "purchase"
+	EntryKindGrant      = "grant"
+	EntryKindRefund     = "refund"
+	EntryKindAdjustment = "adjustment"
+	EntryKindExpiry     = "expiry"
+)
+
+// System ledger accounts. Coins are issued from the issuance account, which therefore has a
+// negative balance, and coins spent on merch end up in the shop account.
+const (
+	AccountIssuance = "issuance"
+	AccountShop     = "shop"
+)
+
+// LedgerEntry is a journal entry of the coin ledger. The amounts of its postings sum to zero.
+// ReversesEntryID is set on the refund entry that compensates a transfer.
+type LedgerEntry struct {
+	ID              int64     `json:"id"`
+	Kind            string    `json:"kind"`
+	Description     string    `json:"description,omitempty"`
+	Message         string    `json:"message,omitempty"`
+	Category        string    `json:"category,omitempty"`
+	ReversesEntryID *int64    `json:"reversesEntryId,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Postings        []Posting `json:"postings"`
+}
+
+// Posting credits (positive amount) or debits (negative amount) the account of a user, or the
+// system account named by Account when UserID is 0.
+type Posting struct {
+	UserID  int64  `json:"userId,omitempty"`
+	Account string `json:"account,omitempty"`
+	Amount  int    `json:"amount"`
+}
+
+// Balanced reports whether the postings of the entry sum to zero.
+func (e *LedgerEntry) Balanced() bool {
+	sum := 0
+	for _, posting := range e.Postings {
+		sum += posting.Amount
+	}
+	return sum == 0
+}
+
+// LedgerReport is the result of checking the ledger invariants: every entry balances and the
+// cached balance of every user equals the sum of the postings on their account.
+type LedgerReport struct {
+	Balanced          bool              `json:"balanced"`
+	UnbalancedEntries []int64           `json:"unbalancedEntries"`
+	Mismatches        []BalanceMismatch `json:"mismatches"`
+}
+
+type BalanceMismatch struct {
+	Username      string `json:"username"`
+	Balance       int    `json:"balance"`
+	LedgerBalance int    `json:"ledgerBalance"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	LoginKindUsername = "username"
+	LoginKindIP       = "ip"
+)
+
+// LoginFailure counts consecutive failed logins for a username or a client address.
+type LoginFailure struct {
+	Kind          string     `json:"kind"`
+	Subject       string     `json:"subject"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	BlockedUntil  *time.Time `json:"blocked_until,omitempty"`
+}
+
+// ClientInfo describes the client a request came from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
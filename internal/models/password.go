@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token issued by an administrator to set a new password.
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	CreatedBy string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// CreatedPasswordResetToken is returned once, when the token is issued.
+type CreatedPasswordResetToken struct {
+	Token     string    `json:"resetToken"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,33 @@
+package models
+
+const (
+	UserStatusActive     = "active"
+	UserStatusSuspended  = "suspended"
+	UserStatusTerminated = "terminated"
+)
+
+// Profile is the employee data of a user.
+type Profile struct {
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+	Department  string `json:"department"`
+	// Manager is the username of the manager of the employee.
+	Manager string `json:"manager"`
+	// HireDate is formatted as YYYY-MM-DD.
+	HireDate string `json:"hireDate"`
+	Status   string `json:"status"`
+}
+
+// ProfileUpdate changes the fields of a profile that are not nil. An empty string clears
+// the field. The department is not part of it: coins are granted and burned by department, so
+// only administrators assign it.
+type ProfileUpdate struct {
+	DisplayName *string
+	Email       *string
+	Manager     *string
+	HireDate    *string
+}
+
+func IsValidUserStatus(status string) bool {
+	return status == UserStatusActive || status == UserStatusSuspended || status == UserStatusTerminated
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Purchase is a merch purchase as it was made: the price is the one at the time of purchase.
+type Purchase struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	ItemID    int64     `json:"itemId,omitempty"`
+	ItemName  string    `json:"item"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice int       `json:"unitPrice"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Total is the number of coins the purchase cost.
+func (p *Purchase) Total() int {
+	return p.Quantity * p.UnitPrice
+}
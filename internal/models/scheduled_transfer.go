@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Outcomes of a run of a scheduled transfer. A run is pending while its transfer is being sent;
+// one that stays pending was interrupted, and whether its coins moved has to be checked in the
+// ledger.
+const (
+	ScheduledRunPending   = "pending"
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunFailed    = "failed"
+)
+
+// ScheduledTransfer sends Amount coins from Owner to ToUser every time Schedule, a cron
+// expression evaluated in UTC, fires. NextRunAt is the next time it is due while Active.
+type ScheduledTransfer struct {
+	ID        int64                 `json:"id"`
+	OwnerID   int64                 `json:"-"`
+	Owner     string                `json:"owner"`
+	ToUser    string                `json:"toUser"`
+	Amount    int                   `json:"amount"`
+	Message   string                `json:"message,omitempty"`
+	Category  string                `json:"category,omitempty"`
+	Schedule  string                `json:"schedule"`
+	Active    bool                  `json:"active"`
+	NextRunAt time.Time             `json:"nextRunAt"`
+	LastRun   *ScheduledTransferRun `json:"lastRun,omitempty"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// ScheduledTransferUpdate changes the fields that are not nil.
+type ScheduledTransferUpdate struct {
+	Amount   *int
+	Message  *string
+	Category *string
+	Schedule *string
+	Active   *bool
+}
+
+// ScheduledTransferRun is the outcome of sending a scheduled transfer due at ScheduledFor.
+type ScheduledTransferRun struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduledTransferId"`
+	ScheduledFor        time.Time `json:"scheduledFor"`
+	Status              string    `json:"status"`
+	Error               string    `json:"error,omitempty"`
+	CreatedAt           time.Time `json:"createdAt"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Session is a sign-in on one device. It lasts while its refresh tokens are rotated and ends
+// when it is revoked or its latest refresh token expires.
+type Session struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"userAgent"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current,omitempty"`
+}
+
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureBlocked            = "blocked"
+	LoginFailureInactive           = "inactive"
+	LoginFailureError              = "error"
+	LoginFailureInvalidRequest     = "invalid_request"
+)
+
+// LoginEvent is an entry of the login audit trail.
+type LoginEvent struct {
+	ID            int64     `json:"id"`
+	Username      string    `json:"username"`
+	UserID        *int64    `json:"userId,omitempty"`
+	Provider      string    `json:"provider,omitempty"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failureReason,omitempty"`
+	IP            string    `json:"ip"`
+	UserAgent     string    `json:"userAgent"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// LoginEventFilter selects login events. Empty fields match any value.
+type LoginEventFilter struct {
+	Username string
+	IP       string
+	Since    *time.Time
+	Limit    int
+}
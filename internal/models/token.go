@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	SessionID int64      `json:"session_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	// SessionRevokedAt is set when the whole session of the token has been revoked.
+	SessionRevokedAt *time.Time `json:"-"`
+}
+
+type AuthTokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int64  `json:"expiresIn"`
+}
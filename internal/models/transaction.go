@@ -2,11 +2,101 @@ package models
 
 import "time"
 
+// CoinTransaction is a transfer as seen by one of its parties. When sending, UserID is the sender
+// and FromUser their username.
 type CoinTransaction struct {
 	ID              int64     `json:"id"`
 	UserID          int64     `json:"user_id"`
+	FromUser        string    `json:"-"`
 	CounterpartUser string    `json:"to_user"`
 	Amount          int       `json:"amount"`
 	TransactionType string    `json:"transaction_type"`
+	Message         string    `json:"message,omitempty"`
+	Category        string    `json:"category,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 }
+
+// Transfer is a transfer between two users as seen in reports, from neither side in particular.
+// ReversalID is the refund entry that reversed the transfer, if it was reversed.
+type Transfer struct {
+	ID         int64     `json:"id"`
+	FromUser   string    `json:"fromUser"`
+	ToUser     string    `json:"toUser"`
+	Amount     int       `json:"amount"`
+	Message    string    `json:"message,omitempty"`
+	Category   string    `json:"category,omitempty"`
+	ReversalID *int64    `json:"reversalId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// TransferReversal moves the coins of a transfer back from its recipient to its sender with a
+// compensating refund entry. ID is the id of that entry, FromUser and ToUser are the parties of
+// the reversed transfer, and ReversedBy is the sender who cancelled it or the admin who reversed it.
+type TransferReversal struct {
+	ID         int64     `json:"id"`
+	TransferID int64     `json:"transferId"`
+	FromUser   string    `json:"fromUser"`
+	ToUser     string    `json:"toUser"`
+	Amount     int       `json:"amount"`
+	ReversedBy string    `json:"reversedBy"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// TransferFilter selects transfers. Username matches either party; empty fields match any value.
+type TransferFilter struct {
+	Username string
+	Category string
+	Since    *time.Time
+	Until    *time.Time
+	Limit    int
+}
+
+// Directions of a coin movement as seen from the account holder.
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// HistoryEntry is one movement of coins on the account of a user: a posting of a ledger entry
+// together with the account on the other side of the entry.
+type HistoryEntry struct {
+	ID          int64     `json:"id"`
+	EntryID     int64     `json:"entryId"`
+	Type        string    `json:"type"`
+	Direction   string    `json:"direction"`
+	Counterpart string    `json:"counterpart"`
+	Amount      int       `json:"amount"`
+	Description string    `json:"description,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// HistoryCursor is the position of a history entry in the order of the history: the time and id
+// of the entry that ended the previous page.
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// TransactionFilter selects history entries of a user. Counterpart is a username or a system
+// account; empty fields match any value. Entries after the cursor are returned, oldest first
+// when Ascending is set and newest first otherwise.
+type TransactionFilter struct {
+	UserID      int64
+	Direction   string
+	Counterpart string
+	Type        string
+	Since       *time.Time
+	Until       *time.Time
+	Ascending   bool
+	After       *HistoryCursor
+	Limit       int
+}
+
+// TransactionPage is a page of history; NextCursor is empty on the last page.
+type TransactionPage struct {
+	Transactions []HistoryEntry `json:"transactions"`
+	NextCursor   string         `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,131 @@
+package models
+
+import "time"
+
+// Names of the transfer limit rules, reported when a transfer breaks one.
+const (
+	RuleMaxPerTransfer = "max_per_transfer"
+	RuleDailyLimit     = "daily_limit"
+	RuleMonthlyLimit   = "monthly_limit"
+	RuleRecipientLimit = "recipient_limit"
+)
+
+// TransferLimits caps the coins a user may send: in one transfer, per calendar day and month
+// (UTC), and to one recipient within the recipient period. A zero limit is no limit.
+type TransferLimits struct {
+	MaxPerTransfer int `json:"maxPerTransfer"`
+	DailyLimit     int `json:"dailyLimit"`
+	MonthlyLimit   int `json:"monthlyLimit"`
+	RecipientLimit int `json:"recipientLimit"`
+}
+
+// TransferLimitException overrides the limits of one user with the fields that are not nil
+// until ExpiresAt, or for good when it is nil.
+type TransferLimitException struct {
+	UserID         int64      `json:"-"`
+	Username       string     `json:"username"`
+	MaxPerTransfer *int       `json:"maxPerTransfer,omitempty"`
+	DailyLimit     *int       `json:"dailyLimit,omitempty"`
+	MonthlyLimit   *int       `json:"monthlyLimit,omitempty"`
+	RecipientLimit *int       `json:"recipientLimit,omitempty"`
+	Reason         string     `json:"reason"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	CreatedBy      string     `json:"createdBy"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// Active reports whether the exception applies at the given time.
+func (e *TransferLimitException) Active(now time.Time) bool {
+	return e.ExpiresAt == nil || now.Before(*e.ExpiresAt)
+}
+
+// Apply returns the limits with the fields of the exception in place of theirs.
+func (e *TransferLimitException) Apply(limits TransferLimits) TransferLimits {
+	if e.MaxPerTransfer != nil {
+		limits.MaxPerTransfer = *e.MaxPerTransfer
+	}
+	if e.DailyLimit != nil {
+		limits.DailyLimit = *e.DailyLimit
+	}
+	if e.MonthlyLimit != nil {
+		limits.MonthlyLimit = *e.MonthlyLimit
+	}
+	if e.RecipientLimit != nil {
+		limits.RecipientLimit = *e.RecipientLimit
+	}
+	return limits
+}
+
+// TransferUsage is what a user has already sent in the periods the limits apply to.
+type TransferUsage struct {
+	Daily     int
+	Monthly   int
+	Recipient int
+}
+
+// TransferLimitCheck is the limits of a sender at a point in time together with the start of the
+// periods they count: the calendar day and month and the recipient period. It is evaluated in the
+// database transaction that posts the transfer, once the balance of the sender is locked, so that
+// concurrent transfers cannot go over a limit together.
+type TransferLimitCheck struct {
+	Limits         TransferLimits
+	DayStart       time.Time
+	MonthStart     time.Time
+	RecipientStart time.Time
+}
+
+// transferRule is a cap on what a sender may have sent, including the transfer being checked,
+// in the period of the rule.
+type transferRule struct {
+	name  string
+	limit func(TransferLimits) int
+	used  func(TransferUsage) int
+}
+
+var transferRules = []transferRule{
+	{
+		name:  RuleMaxPerTransfer,
+		limit: func(l TransferLimits) int { return l.MaxPerTransfer },
+		used:  func(TransferUsage) int { return 0 },
+	},
+	{
+		name:  RuleDailyLimit,
+		limit: func(l TransferLimits) int { return l.DailyLimit },
+		used:  func(u TransferUsage) int { return u.Daily },
+	},
+	{
+		name:  RuleMonthlyLimit,
+		limit: func(l TransferLimits) int { return l.MonthlyLimit },
+		used:  func(u TransferUsage) int { return u.Monthly },
+	},
+	{
+		name:  RuleRecipientLimit,
+		limit: func(l TransferLimits) int { return l.RecipientLimit },
+		used:  func(u TransferUsage) int { return u.Recipient },
+	},
+}
+
+// CountsUsage reports whether any limit depends on what the sender already sent.
+func (c *TransferLimitCheck) CountsUsage() bool {
+	return c.Limits.DailyLimit > 0 || c.Limits.MonthlyLimit > 0 || c.Limits.RecipientLimit > 0
+}
+
+// Evaluate returns a *TransferLimitError naming the first rule a transfer of the amount breaks
+// when the sender already sent usage, or nil.
+func (c *TransferLimitCheck) Evaluate(usage TransferUsage, amount int) error {
+	for _, rule := range transferRules {
+		limit, used := rule.limit(c.Limits), rule.used(usage)
+		if limit > 0 && used+amount > limit {
+			return &TransferLimitError{Rule: rule.name, Limit: limit, Used: used}
+		}
+	}
+	return nil
+}
+
+// UserTransferLimits describes the limits that apply to a user and where they come from.
+type UserTransferLimits struct {
+	Username  string                  `json:"username"`
+	Limits    TransferLimits          `json:"limits"`
+	Defaults  TransferLimits          `json:"defaults"`
+	Exception *TransferLimitException `json:"exception,omitempty"`
+}
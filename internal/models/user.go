@@ -1,8 +1,22 @@
 package models
 
+import "time"
+
 type User struct {
-	ID       int64  `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Coins    int    `json:"coins"`
+	ID       int64    `json:"id"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	Coins    int      `json:"coins"`
+	Roles    []string `json:"roles"`
+	Scopes   []string `json:"scopes"`
+	// PasswordChangedAt is the time of the last password change. Access tokens issued
+	// before it are rejected.
+	PasswordChangedAt *time.Time `json:"-"`
+	Profile
+}
+
+// IsActive reports whether the user may sign in and send or receive coins. A user without
+// a status is active, as in the database.
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
 }
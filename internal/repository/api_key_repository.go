@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	GetAPIKeysByUserID(ctx context.Context, userID int64) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID int64, keyID int64) error
+	UpdateAPIKeyLastUsed(ctx context.Context, keyID int64, usedAt time.Time) error
+}
+
+type APIKeyRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{DB: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, rate_limit, created_at, expires_at, last_used_at, revoked_at`
+
+func scanAPIKey(row pgx.Row, key *models.APIKey) error {
+	return row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes, &key.RateLimit,
+		&key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt)
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, rate_limit, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+	err := r.DB.QueryRow(ctx, query, key.UserID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.RateLimit, key.ExpiresAt).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		log.Printf("error creating api key: %v", err)
+		return fmt.Errorf("error creating api key: %v", err)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	err := scanAPIKey(r.DB.QueryRow(ctx, query, keyHash), key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("error fetching api key: %v", err)
+		return nil, fmt.Errorf("error fetching api key: %v", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeysByUserID(ctx context.Context, userID int64) ([]models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	rows, err := r.DB.Query(ctx, query, userID)
+	if err != nil {
+		log.Printf("error fetching api keys: %v", err)
+		return nil, fmt.Errorf("error fetching api keys: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		if err = scanAPIKey(rows, &key); err != nil {
+			log.Printf("error scanning api key: %v", err)
+			return nil, fmt.Errorf("error scanning api key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating api keys: %v", err)
+		return nil, fmt.Errorf("error iterating api keys: %v", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an active key of the user. ErrAPIKeyNotFound is returned when the user
+// has no such active key.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, userID int64, keyID int64) error {
+	tag, err := r.DB.Exec(ctx, `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, keyID, userID)
+	if err != nil {
+		log.Printf("error revoking api key: %v", err)
+		return fmt.Errorf("error revoking api key: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, keyID int64, usedAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, usedAt, keyID)
+	if err != nil {
+		log.Printf("error updating api key last use: %v", err)
+		return fmt.Errorf("error updating api key last use: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CoinLotRepositoryInterface interface {
+	GetOpenLots(ctx context.Context, userID int64, limit int) ([]models.CoinLot, error)
+	GetUsersWithLotsGrantedBefore(ctx context.Context, cutoff time.Time, limit int) ([]int64, error)
+	ExpireLots(ctx context.Context, userID int64, cutoff time.Time, description string) (int, error)
+}
+
+type CoinLotRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewCoinLotRepository(db *pgxpool.Pool) *CoinLotRepository {
+	return &CoinLotRepository{DB: db}
+}
+
+// GetOpenLots returns up to limit lots of the user that have coins left, the oldest first.
+func (r *CoinLotRepository) GetOpenLots(ctx context.Context, userID int64, limit int) ([]models.CoinLot, error) {
+	rows, err := r.DB.Query(ctx, `SELECT id, entry_id, amount, remaining, granted_at FROM coin_lots
+        WHERE user_id = $1 AND remaining > 0 ORDER BY granted_at, id LIMIT $2`, userID, limit)
+	if err != nil {
+		log.Printf("error fetching coin lots: %v", err)
+		return nil, fmt.Errorf("error fetching coin lots: %v", err)
+	}
+	lots, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.CoinLot])
+	if err != nil {
+		log.Printf("error scanning coin lots: %v", err)
+		return nil, fmt.Errorf("error scanning coin lots: %v", err)
+	}
+	return lots, nil
+}
+
+// GetUsersWithLotsGrantedBefore returns the ids of up to limit users who have coins left in lots
+// granted before the cutoff.
+func (r *CoinLotRepository) GetUsersWithLotsGrantedBefore(ctx context.Context, cutoff time.Time, limit int) ([]int64, error) {
+	rows, err := r.DB.Query(ctx, `SELECT DISTINCT user_id FROM coin_lots
+        WHERE remaining > 0 AND granted_at < $1 ORDER BY user_id LIMIT $2`, cutoff, limit)
+	if err != nil {
+		log.Printf("error fetching users with stale coin lots: %v", err)
+		return nil, fmt.Errorf("error fetching users with stale coin lots: %v", err)
+	}
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		log.Printf("error scanning users with stale coin lots: %v", err)
+		return nil, fmt.Errorf("error scanning users with stale coin lots: %v", err)
+	}
+	return userIDs, nil
+}
+
+// ExpireLots takes the coins left in the lots of the user granted before the cutoff back to the
+// issuance account with an expiry entry, and returns how many coins expired. The stale lots are
+// the oldest ones, so the debit consumes exactly them.
+func (r *CoinLotRepository) ExpireLots(ctx context.Context, userID int64, cutoff time.Time, description string) (int, error) {
+	expired := 0
+	err := withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		var locked int64
+		err := tx.QueryRow(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&locked)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrUserNotFound
+		}
+		if err != nil {
+			log.Printf("failed to lock balance of user %d: %v", userID, err)
+			return fmt.Errorf("failed to lock balance of user %d: %v", userID, err)
+		}
+
+		err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(remaining), 0) FROM coin_lots
+            WHERE user_id = $1 AND remaining > 0 AND granted_at < $2`, userID, cutoff).Scan(&expired)
+		if err != nil {
+			log.Printf("error summing stale coin lots of user %d: %v", userID, err)
+			return fmt.Errorf("error summing stale coin lots of user %d: %v", userID, err)
+		}
+		if expired == 0 {
+			return nil
+		}
+		return postEntry(ctx, tx, &models.LedgerEntry{
+			Kind:        models.EntryKindExpiry,
+			Description: description,
+			Postings: []models.Posting{
+				{UserID: userID, Amount: -expired},
+				{Account: models.AccountIssuance, Amount: expired},
+			},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
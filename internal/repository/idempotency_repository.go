@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyRepositoryInterface interface {
+	CreateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) (bool, error)
+	GetIdempotencyRecord(ctx context.Context, username, key string, now time.Time) (*models.IdempotencyRecord, error)
+	CompleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error
+	DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{DB: db}
+}
+
+// CreateIdempotencyRecord claims the key for a new request and reports whether it did. It fails
+// to when an unexpired record for the key exists; an expired one is replaced.
+func (r *IdempotencyRepository) CreateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	query := `INSERT INTO idempotency_keys (username, idempotency_key, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (username, idempotency_key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, content_type = '', response_body = NULL,
+				created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`
+	tag, err := r.DB.Exec(ctx, query, record.Username, record.Key, record.Fingerprint, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		log.Printf("error creating idempotency record: %v", err)
+		return false, fmt.Errorf("error creating idempotency record: %v", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetIdempotencyRecord returns the unexpired record for the key, or nil if there is none.
+func (r *IdempotencyRepository) GetIdempotencyRecord(ctx context.Context, username, key string, now time.Time) (*models.IdempotencyRecord, error) {
+	record := &models.IdempotencyRecord{}
+	var statusCode *int
+	query := `SELECT username, idempotency_key, fingerprint, status_code, content_type, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE username = $1 AND idempotency_key = $2 AND expires_at > $3`
+	err := r.DB.QueryRow(ctx, query, username, key, now).Scan(&record.Username, &record.Key, &record.Fingerprint,
+		&statusCode, &record.ContentType, &record.Body, &record.CreatedAt, &record.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("error fetching idempotency record: %v", err)
+		return nil, fmt.Errorf("error fetching idempotency record: %v", err)
+	}
+	if statusCode != nil {
+		record.StatusCode = *statusCode
+	}
+	return record, nil
+}
+
+// CompleteIdempotencyRecord stores the response of the request that claimed the key.
+func (r *IdempotencyRepository) CompleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	query := `UPDATE idempotency_keys SET status_code = $3, content_type = $4, response_body = $5
+		WHERE username = $1 AND idempotency_key = $2`
+	_, err := r.DB.Exec(ctx, query, record.Username, record.Key, record.StatusCode, record.ContentType, record.Body)
+	if err != nil {
+		log.Printf("error completing idempotency record: %v", err)
+		return fmt.Errorf("error completing idempotency record: %v", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyRecords deletes the records that expired by now and returns how many
+// it deleted.
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		log.Printf("error deleting expired idempotency records: %v", err)
+		return 0, fmt.Errorf("error deleting expired idempotency records: %v", err)
+	}
+	return tag.RowsAffected(), nil
+}
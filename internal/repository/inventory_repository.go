@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 
 	"github.com/avito-shop-service/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -14,6 +13,7 @@ import (
 
 type InventoryRepositoryInterface interface {
 	GetInventoryByUserID(ctx context.Context, userID int64) ([]models.Inventory, error)
+	GetPurchasesByUserID(ctx context.Context, userID int64) ([]models.Purchase, error)
 	BuyItemToInventory(ctx context.Context, userID int64, itemID int64, quantity int, price int) error
 	UpdateItemQuantity(ctx context.Context, userID int, itemID int, quantity int) error
 	GetItemFromInventory(ctx context.Context, userID int, itemID int) (*models.Inventory, error)
@@ -57,54 +57,88 @@ func (r *InventoryRepository) GetInventoryByUserID(ctx context.Context, userID i
 	return inventoryItems, nil
 }
 
-func (r *InventoryRepository) BuyItemToInventory(ctx context.Context, userID int64, itemID int64, quantity int, merchPrice int) error {
-	var userCoins int64
-	err := r.DB.QueryRow(ctx, "SELECT coins FROM users WHERE id = $1", userID).Scan(&userCoins)
-	if err != nil {
-		log.Printf("error fetching user balance: %v", err)
-		return fmt.Errorf("error fetching user balance: %v", err)
-	}
-	if userCoins < int64(merchPrice*quantity) {
-		log.Printf("not enough coins")
-		return fmt.Errorf("not enough coins")
-	}
+// GetPurchasesByUserID returns the purchases of the user, newest first.
+func (r *InventoryRepository) GetPurchasesByUserID(ctx context.Context, userID int64) ([]models.Purchase, error) {
+	var purchases []models.Purchase
+	query := `SELECT id, user_id, COALESCE(item_id, 0), item_name, quantity, unit_price, created_at
+              FROM purchases WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
 
-	tx, err := r.DB.BeginTx(ctx, pgx.TxOptions{})
+	rows, err := r.DB.Query(ctx, query, userID)
 	if err != nil {
-		log.Printf("failed to start transaction: %v", err)
-		return fmt.Errorf("failed to start transaction: %v", err)
+		log.Printf("error fetching purchases: %v", err)
+		return nil, fmt.Errorf("error fetching purchases: %v", err)
 	}
-	defer func() {
-		err = tx.Rollback(ctx)
-		if strings.Contains(err.Error(), "tx is closed") {
-			return
-		}
-		log.Printf("error rolling back transaction: %v", err)
-	}()
+	defer rows.Close()
 
-	_, err = r.DB.Exec(ctx, "UPDATE users SET coins = coins - $1 WHERE id = $2", merchPrice*quantity, userID)
-	if err != nil {
-		log.Printf("error updating user coins: %v", err)
-		return fmt.Errorf("error updating user coins: %v", err)
+	for rows.Next() {
+		purchase := models.Purchase{}
+		err = rows.Scan(&purchase.ID, &purchase.UserID, &purchase.ItemID, &purchase.ItemName, &purchase.Quantity, &purchase.UnitPrice, &purchase.CreatedAt)
+		if err != nil {
+			log.Printf("error scanning row: %v", err)
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		purchases = append(purchases, purchase)
 	}
 
-	query := `INSERT INTO inventory (user_id, item_id, quantity) 
-              VALUES ($1, $2, $3)
-              ON CONFLICT (user_id, item_id) 
-              DO UPDATE SET quantity = inventory.quantity + $3`
-	_, err = tx.Exec(ctx, query, userID, itemID, quantity)
-	if err != nil {
-		log.Printf("error adding item to inventory: %v", err)
-		return fmt.Errorf("error adding item to inventory: %v", err)
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating rows: %v", err)
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		log.Printf("error committing transaction: %v", err)
-		return fmt.Errorf("error committing transaction: %v", err)
-	}
+	return purchases, nil
+}
 
-	return nil
+// BuyItemToInventory posts the purchase to the ledger, records it in the purchase history and
+// adds the item to the inventory in one transaction. The balance row is locked while it is checked, so a balance that does not cover
+// the price yields a *models.InsufficientFundsError and nothing changes.
+func (r *InventoryRepository) BuyItemToInventory(ctx context.Context, userID int64, itemID int64, quantity int, merchPrice int) error {
+	total := merchPrice * quantity
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		var balance int
+		err := tx.QueryRow(ctx, "SELECT coins FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&balance)
+		if err != nil {
+			log.Printf("error fetching user balance: %v", err)
+			return fmt.Errorf("error fetching user balance: %v", err)
+		}
+		if balance < total {
+			return &models.InsufficientFundsError{Balance: balance, Required: total}
+		}
+
+		var itemName string
+		if err = tx.QueryRow(ctx, "SELECT item_name FROM merch WHERE id = $1", itemID).Scan(&itemName); err != nil {
+			log.Printf("error fetching merch: %v", err)
+			return fmt.Errorf("error fetching merch: %v", err)
+		}
+		entry := &models.LedgerEntry{
+			Kind:        models.EntryKindPurchase,
+			Description: itemName,
+			Postings: []models.Posting{
+				{UserID: userID, Amount: -total},
+				{Account: models.AccountShop, Amount: total},
+			},
+		}
+		if err = postEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO purchases (entry_id, user_id, item_id, item_name, quantity, unit_price, created_at)
+                               VALUES ($1, $2, $3, $4, $5, $6, $7)`, entry.ID, userID, itemID, itemName, quantity, merchPrice, entry.CreatedAt)
+		if err != nil {
+			log.Printf("error recording purchase: %v", err)
+			return fmt.Errorf("error recording purchase: %v", err)
+		}
+
+		query := `INSERT INTO inventory (user_id, item_id, quantity) 
+                  VALUES ($1, $2, $3)
+                  ON CONFLICT (user_id, item_id) 
+                  DO UPDATE SET quantity = inventory.quantity + $3`
+		_, err = tx.Exec(ctx, query, userID, itemID, quantity)
+		if err != nil {
+			log.Printf("error adding item to inventory: %v", err)
+			return fmt.Errorf("error adding item to inventory: %v", err)
+		}
+		return nil
+	})
 }
 
 func (r *InventoryRepository) UpdateItemQuantity(ctx context.Context, userID int, itemID int, quantity int) error {
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IssuanceRepositoryInterface interface {
+	IssueCoins(ctx context.Context, issuance *models.CoinIssuance) error
+	GetUsersWithoutAllowance(ctx context.Context, period string, limit int) ([]int64, error)
+	GrantAllowance(ctx context.Context, userID int64, period string, amount int) (bool, error)
+}
+
+type IssuanceRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewIssuanceRepository(db *pgxpool.Pool) *IssuanceRepository {
+	return &IssuanceRepository{DB: db}
+}
+
+// IssueCoins grants or burns the coins of the issuance in one transaction, with a ledger entry
+// against the issuance account for every user, and fills in issuance.Entries. A burn that would
+// overdraw any of the users is refused with a *models.InsufficientFundsError and burns nothing.
+func (r *IssuanceRepository) IssueCoins(ctx context.Context, issuance *models.CoinIssuance) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		query := `SELECT id, username, coins FROM users WHERE username = $1 FOR UPDATE`
+		arg := issuance.Username
+		if issuance.Department != "" {
+			query = `SELECT id, username, coins FROM users WHERE department = $1 AND status = 'active' ORDER BY id FOR UPDATE`
+			arg = issuance.Department
+		}
+		rows, err := tx.Query(ctx, query, arg)
+		if err != nil {
+			log.Printf("failed to lock balances: %v", err)
+			return fmt.Errorf("failed to lock balances: %v", err)
+		}
+		var targets []issuanceTarget
+		for rows.Next() {
+			var target issuanceTarget
+			if err = rows.Scan(&target.id, &target.username, &target.coins); err != nil {
+				rows.Close()
+				log.Printf("error scanning row: %v", err)
+				return fmt.Errorf("error scanning row: %v", err)
+			}
+			targets = append(targets, target)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			log.Printf("error iterating rows: %v", err)
+			return fmt.Errorf("error iterating rows: %v", err)
+		}
+		if len(targets) == 0 {
+			if issuance.Department != "" {
+				return fmt.Errorf("%w: no active users in department %q", models.ErrUserNotFound, issuance.Department)
+			}
+			return fmt.Errorf("%w: %s", models.ErrUserNotFound, issuance.Username)
+		}
+
+		kind, amount, verb := models.EntryKindGrant, issuance.Amount, "granted"
+		if issuance.Operation == models.IssuanceBurn {
+			kind, amount, verb = models.EntryKindAdjustment, -issuance.Amount, "burned"
+		}
+		description := fmt.Sprintf("%s by %s: %s", verb, issuance.IssuedBy, issuance.Reason)
+
+		issuance.Entries = make([]models.IssuanceEntry, 0, len(targets))
+		for _, target := range targets {
+			if target.coins+amount < 0 {
+				return fmt.Errorf("%s: %w", target.username, &models.InsufficientFundsError{Balance: target.coins, Required: issuance.Amount})
+			}
+			entry := &models.LedgerEntry{
+				Kind:        kind,
+				Description: description,
+				Postings: []models.Posting{
+					{Account: models.AccountIssuance, Amount: -amount},
+					{UserID: target.id, Amount: amount},
+				},
+			}
+			if err = postEntry(ctx, tx, entry); err != nil {
+				return err
+			}
+			issuance.Entries = append(issuance.Entries, models.IssuanceEntry{EntryID: entry.ID, Username: target.username, Balance: target.coins + amount})
+		}
+		return nil
+	})
+}
+
+// GetUsersWithoutAllowance returns the ids of up to limit active users who have not been granted
+// the allowance of the period yet.
+func (r *IssuanceRepository) GetUsersWithoutAllowance(ctx context.Context, period string, limit int) ([]int64, error) {
+	rows, err := r.DB.Query(ctx, `SELECT u.id FROM users u
+        WHERE u.status = 'active' AND NOT EXISTS (SELECT 1 FROM allowance_grants g WHERE g.user_id = u.id AND g.period = $1)
+        ORDER BY u.id LIMIT $2`, period, limit)
+	if err != nil {
+		log.Printf("error fetching users without allowance: %v", err)
+		return nil, fmt.Errorf("error fetching users without allowance: %v", err)
+	}
+	userIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		log.Printf("error scanning users without allowance: %v", err)
+		return nil, fmt.Errorf("error scanning users without allowance: %v", err)
+	}
+	return userIDs, nil
+}
+
+// GrantAllowance grants the user the allowance of the period unless it has been granted already,
+// and reports whether it did.
+func (r *IssuanceRepository) GrantAllowance(ctx context.Context, userID int64, period string, amount int) (bool, error) {
+	granted := false
+	err := withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `INSERT INTO allowance_grants (user_id, period) VALUES ($1, $2) ON CONFLICT DO NOTHING`, userID, period)
+		if err != nil {
+			log.Printf("error recording allowance of user %d: %v", userID, err)
+			return fmt.Errorf("error recording allowance of user %d: %v", userID, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil
+		}
+		granted = true
+		return postEntry(ctx, tx, &models.LedgerEntry{
+			Kind:        models.EntryKindGrant,
+			Description: "allowance for " + period,
+			Postings: []models.Posting{
+				{Account: models.AccountIssuance, Amount: -amount},
+				{UserID: userID, Amount: amount},
+			},
+		})
+	})
+	return granted && err == nil, err
+}
+
+// issuanceTarget is the locked balance row of a user coins are granted to or burned from.
+type issuanceTarget struct {
+	id       int64
+	username string
+	coins    int
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LedgerRepositoryInterface interface {
+	VerifyLedger(ctx context.Context) (*models.LedgerReport, error)
+}
+
+type LedgerRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewLedgerRepository(db *pgxpool.Pool) *LedgerRepository {
+	return &LedgerRepository{DB: db}
+}
+
+// VerifyLedger lists the entries whose postings do not sum to zero and the users whose cached
+// balance differs from the sum of the postings on their account.
+func (r *LedgerRepository) VerifyLedger(ctx context.Context) (*models.LedgerReport, error) {
+	report := &models.LedgerReport{UnbalancedEntries: []int64{}, Mismatches: []models.BalanceMismatch{}}
+
+	rows, err := r.DB.Query(ctx, `SELECT entry_id FROM ledger_postings GROUP BY entry_id HAVING SUM(amount) <> 0 ORDER BY entry_id`)
+	if err != nil {
+		log.Printf("error checking ledger entries: %v", err)
+		return nil, fmt.Errorf("error checking ledger entries: %v", err)
+	}
+	entryIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		log.Printf("error scanning ledger entries: %v", err)
+		return nil, fmt.Errorf("error scanning ledger entries: %v", err)
+	}
+	report.UnbalancedEntries = append(report.UnbalancedEntries, entryIDs...)
+
+	rows, err = r.DB.Query(ctx, `SELECT u.username, u.coins, COALESCE(SUM(p.amount), 0)
+		FROM users u
+		LEFT JOIN ledger_accounts a ON a.user_id = u.id
+		LEFT JOIN ledger_postings p ON p.account_id = a.id
+		GROUP BY u.id HAVING u.coins <> COALESCE(SUM(p.amount), 0)
+		ORDER BY u.username`)
+	if err != nil {
+		log.Printf("error checking balances: %v", err)
+		return nil, fmt.Errorf("error checking balances: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var mismatch models.BalanceMismatch
+		if err = rows.Scan(&mismatch.Username, &mismatch.Balance, &mismatch.LedgerBalance); err != nil {
+			log.Printf("error scanning row: %v", err)
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+		report.Mismatches = append(report.Mismatches, mismatch)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating rows: %v", err)
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+	return report, nil
+}
+
+// postEntry writes the journal entry and its postings and applies the postings to the cached
+// balances and the coin lots of the users involved. It is the only way balances change, so callers lock and check
+// the balances they debit beforehand; a debit that would still overdraw a balance is refused
+// with models.ErrInsufficientFunds, and a second reversal of an entry with
+// models.ErrTransferAlreadyReversed.
+func postEntry(ctx context.Context, tx pgx.Tx, entry *models.LedgerEntry) error {
+	if len(entry.Postings) < 2 || !entry.Balanced() {
+		return fmt.Errorf("ledger entry %q does not balance", entry.Kind)
+	}
+
+	err := tx.QueryRow(ctx, `INSERT INTO ledger_entries (kind, description, message, category, reverses_entry_id)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		entry.Kind, entry.Description, entry.Message, entry.Category, entry.ReversesEntryID).Scan(&entry.ID, &entry.CreatedAt)
+	if entry.ReversesEntryID != nil && isUniqueViolation(err) {
+		return models.ErrTransferAlreadyReversed
+	}
+	if err != nil {
+		log.Printf("error creating ledger entry: %v", err)
+		return fmt.Errorf("error creating ledger entry: %v", err)
+	}
+
+	for _, posting := range entry.Postings {
+		query := `INSERT INTO ledger_postings (entry_id, account_id, amount, created_at) SELECT $1, id, $3, $4 FROM ledger_accounts WHERE code = $2`
+		var account any = posting.Account
+		if posting.UserID != 0 {
+			query = `INSERT INTO ledger_postings (entry_id, account_id, amount, created_at) SELECT $1, id, $3, $4 FROM ledger_accounts WHERE user_id = $2`
+			account = posting.UserID
+		}
+		tag, err := tx.Exec(ctx, query, entry.ID, account, posting.Amount, entry.CreatedAt)
+		if err != nil {
+			log.Printf("error creating ledger posting: %v", err)
+			return fmt.Errorf("error creating ledger posting: %v", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("ledger account of %v not found", account)
+		}
+
+		if posting.UserID == 0 {
+			continue
+		}
+		_, err = tx.Exec(ctx, `UPDATE users SET coins = coins + $1 WHERE id = $2`, posting.Amount, posting.UserID)
+		if isCheckViolation(err) {
+			return models.ErrInsufficientFunds
+		}
+		if err != nil {
+			log.Printf("error updating balance of user %d: %v", posting.UserID, err)
+			return fmt.Errorf("error updating balance of user %d: %v", posting.UserID, err)
+		}
+	}
+	return updateLots(ctx, tx, entry)
+}
+
+// updateLots applies the entry to the coin lots of the users involved, so that the open lots of a
+// user add up to their balance. Debits consume the oldest lots of the user first; a refund of a
+// transfer consumes the lots that transfer opened before any other. Credits open lots: the coins
+// of transfers and refunds keep the grant time of the lots they were taken from, so passing coins
+// around does not postpone their expiry, while coins from system accounts are granted now.
+func updateLots(ctx context.Context, tx pgx.Tx, entry *models.LedgerEntry) error {
+	var moved []lotSlice
+	for _, posting := range entry.Postings {
+		if posting.UserID == 0 || posting.Amount >= 0 {
+			continue
+		}
+		consumed, err := consumeLots(ctx, tx, posting.UserID, -posting.Amount, entry.ReversesEntryID)
+		if err != nil {
+			return err
+		}
+		moved = append(moved, consumed...)
+	}
+	if entry.Kind != models.EntryKindTransfer && entry.Kind != models.EntryKindRefund {
+		moved = nil
+	}
+
+	for _, posting := range entry.Postings {
+		if posting.UserID == 0 || posting.Amount <= 0 {
+			continue
+		}
+		for credit := posting.Amount; credit > 0; {
+			lot := lotSlice{GrantedAt: entry.CreatedAt, Amount: credit}
+			if len(moved) > 0 {
+				lot = lotSlice{GrantedAt: moved[0].GrantedAt, Amount: min(moved[0].Amount, credit)}
+				if moved[0].Amount -= lot.Amount; moved[0].Amount == 0 {
+					moved = moved[1:]
+				}
+			}
+			_, err := tx.Exec(ctx, `INSERT INTO coin_lots (user_id, entry_id, amount, remaining, granted_at) VALUES ($1, $2, $3, $3, $4)`,
+				posting.UserID, entry.ID, lot.Amount, lot.GrantedAt)
+			if err != nil {
+				log.Printf("error opening coin lot of user %d: %v", posting.UserID, err)
+				return fmt.Errorf("error opening coin lot of user %d: %v", posting.UserID, err)
+			}
+			credit -= lot.Amount
+		}
+	}
+	return nil
+}
+
+// consumeLots takes the debit from the open lots of the user, those opened by the entry
+// preferred is not nil, then the oldest, and returns what it took from each.
+func consumeLots(ctx context.Context, tx pgx.Tx, userID int64, debit int, preferred *int64) ([]lotSlice, error) {
+	rows, err := tx.Query(ctx, `SELECT id, remaining, granted_at FROM coin_lots WHERE user_id = $1 AND remaining > 0
+        ORDER BY COALESCE(entry_id = $2, false) DESC, granted_at, id FOR UPDATE`, userID, preferred)
+	if err != nil {
+		log.Printf("error fetching coin lots of user %d: %v", userID, err)
+		return nil, fmt.Errorf("error fetching coin lots of user %d: %v", userID, err)
+	}
+	lots, err := pgx.CollectRows(rows, pgx.RowToStructByPos[coinLot])
+	if err != nil {
+		log.Printf("error scanning coin lots of user %d: %v", userID, err)
+		return nil, fmt.Errorf("error scanning coin lots of user %d: %v", userID, err)
+	}
+
+	var consumed []lotSlice
+	for _, lot := range lots {
+		if debit == 0 {
+			break
+		}
+		amount := min(lot.Remaining, debit)
+		if _, err = tx.Exec(ctx, `UPDATE coin_lots SET remaining = remaining - $1 WHERE id = $2`, amount, lot.ID); err != nil {
+			log.Printf("error consuming coin lot %d: %v", lot.ID, err)
+			return nil, fmt.Errorf("error consuming coin lot %d: %v", lot.ID, err)
+		}
+		consumed = append(consumed, lotSlice{GrantedAt: lot.GrantedAt, Amount: amount})
+		debit -= amount
+	}
+	if debit > 0 {
+		// The balance covered the debit, so the lots disagree with it; the ledger stays the
+		// source of truth and the debit goes through.
+		log.Printf("coin lots of user %d are %d coins short of their balance", userID, debit)
+	}
+	return consumed, nil
+}
+
+// coinLot is an open lot as consumed by a debit.
+type coinLot struct {
+	ID        int64
+	Remaining int
+	GrantedAt time.Time
+}
+
+// lotSlice is part of a lot taken by a debit or given by a credit.
+type lotSlice struct {
+	GrantedAt time.Time
+	Amount    int
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LoginEventRepositoryInterface interface {
+	CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error
+	GetLoginEvents(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error)
+}
+
+type LoginEventRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewLoginEventRepository(db *pgxpool.Pool) *LoginEventRepository {
+	return &LoginEventRepository{DB: db}
+}
+
+func (r *LoginEventRepository) CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error {
+	query := `INSERT INTO login_events (username, user_id, provider, success, failure_reason, ip, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := r.DB.QueryRow(ctx, query, event.Username, event.UserID, event.Provider, event.Success, event.FailureReason,
+		event.IP, event.UserAgent, event.CreatedAt).Scan(&event.ID)
+	if err != nil {
+		log.Printf("error creating login event: %v", err)
+		return fmt.Errorf("error creating login event: %v", err)
+	}
+	return nil
+}
+
+// GetLoginEvents returns the events matching the filter, newest first.
+func (r *LoginEventRepository) GetLoginEvents(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.Username != "" {
+		args = append(args, filter.Username)
+		conditions = append(conditions, fmt.Sprintf("username = $%d", len(args)))
+	}
+	if filter.IP != "" {
+		args = append(args, filter.IP)
+		conditions = append(conditions, fmt.Sprintf("ip = $%d", len(args)))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	query := `SELECT id, username, user_id, provider, success, failure_reason, ip, user_agent, created_at FROM login_events`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("error fetching login events: %v", err)
+		return nil, fmt.Errorf("error fetching login events: %v", err)
+	}
+	defer rows.Close()
+
+	var events []models.LoginEvent
+	for rows.Next() {
+		var event models.LoginEvent
+		err = rows.Scan(&event.ID, &event.Username, &event.UserID, &event.Provider, &event.Success, &event.FailureReason,
+			&event.IP, &event.UserAgent, &event.CreatedAt)
+		if err != nil {
+			log.Printf("error scanning login event: %v", err)
+			return nil, fmt.Errorf("error scanning login event: %v", err)
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating login events: %v", err)
+		return nil, fmt.Errorf("error iterating login events: %v", err)
+	}
+	return events, nil
+}
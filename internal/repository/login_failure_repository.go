@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LoginFailureRepositoryInterface interface {
+	GetLoginFailure(ctx context.Context, kind, subject string) (*models.LoginFailure, error)
+	RecordLoginFailure(ctx context.Context, kind, subject string, now time.Time, window time.Duration) (int, error)
+	BlockLogin(ctx context.Context, kind, subject string, until time.Time) error
+	ResetLoginFailures(ctx context.Context, kind, subject string) error
+}
+
+type LoginFailureRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewLoginFailureRepository(db *pgxpool.Pool) *LoginFailureRepository {
+	return &LoginFailureRepository{DB: db}
+}
+
+func (r *LoginFailureRepository) GetLoginFailure(ctx context.Context, kind, subject string) (*models.LoginFailure, error) {
+	failure := &models.LoginFailure{}
+	query := `SELECT kind, subject, failures, last_failure_at, blocked_until FROM login_failures WHERE kind = $1 AND subject = $2`
+
+	err := r.DB.QueryRow(ctx, query, kind, subject).Scan(&failure.Kind, &failure.Subject, &failure.Failures,
+		&failure.LastFailureAt, &failure.BlockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("error fetching login failures: %v", err)
+		return nil, fmt.Errorf("error fetching login failures: %v", err)
+	}
+	return failure, nil
+}
+
+// RecordLoginFailure increments the failure counter and returns its new value. The counter
+// starts over when the previous failure is older than window.
+func (r *LoginFailureRepository) RecordLoginFailure(ctx context.Context, kind, subject string, now time.Time, window time.Duration) (int, error) {
+	query := `INSERT INTO login_failures (kind, subject, failures, last_failure_at) VALUES ($1, $2, 1, $3)
+		ON CONFLICT (kind, subject) DO UPDATE SET
+			failures = CASE WHEN login_failures.last_failure_at < $4 THEN 1 ELSE login_failures.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures`
+
+	var failures int
+	err := r.DB.QueryRow(ctx, query, kind, subject, now, now.Add(-window)).Scan(&failures)
+	if err != nil {
+		log.Printf("error recording login failure: %v", err)
+		return 0, fmt.Errorf("error recording login failure: %v", err)
+	}
+	return failures, nil
+}
+
+func (r *LoginFailureRepository) BlockLogin(ctx context.Context, kind, subject string, until time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE login_failures SET blocked_until = $1 WHERE kind = $2 AND subject = $3`, until, kind, subject)
+	if err != nil {
+		log.Printf("error blocking login: %v", err)
+		return fmt.Errorf("error blocking login: %v", err)
+	}
+	return nil
+}
+
+func (r *LoginFailureRepository) ResetLoginFailures(ctx context.Context, kind, subject string) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM login_failures WHERE kind = $1 AND subject = $2`, kind, subject)
+	if err != nil {
+		log.Printf("error resetting login failures: %v", err)
+		return fmt.Errorf("error resetting login failures: %v", err)
+	}
+	return nil
+}
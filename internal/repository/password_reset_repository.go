@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PasswordResetRepositoryInterface interface {
+	CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (*models.PasswordResetToken, error)
+}
+
+type PasswordResetRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewPasswordResetRepository(db *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{DB: db}
+}
+
+// CreatePasswordResetToken stores the token and invalidates the unused tokens issued to the
+// user before, so only the latest one works.
+func (r *PasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`, token.CreatedAt, token.UserID)
+		if err != nil {
+			log.Printf("error invalidating password reset tokens: %v", err)
+			return fmt.Errorf("error invalidating password reset tokens: %v", err)
+		}
+
+		query := `INSERT INTO password_reset_tokens (user_id, token_hash, created_by, created_at, expires_at)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		err = tx.QueryRow(ctx, query, token.UserID, token.TokenHash, token.CreatedBy, token.CreatedAt, token.ExpiresAt).Scan(&token.ID)
+		if err != nil {
+			log.Printf("error creating password reset token: %v", err)
+			return fmt.Errorf("error creating password reset token: %v", err)
+		}
+		return nil
+	})
+}
+
+// ConsumePasswordResetToken marks the token as used and returns it. The update is conditional,
+// so of two concurrent requests with the same token only one succeeds. Unknown, used and
+// expired tokens yield ErrInvalidResetToken.
+func (r *PasswordResetRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	token := &models.PasswordResetToken{}
+	query := `UPDATE password_reset_tokens SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, user_id, token_hash, created_by, created_at, expires_at, used_at`
+
+	err := r.DB.QueryRow(ctx, query, tokenHash, now).Scan(&token.ID, &token.UserID, &token.TokenHash,
+		&token.CreatedBy, &token.CreatedAt, &token.ExpiresAt, &token.UsedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, models.ErrInvalidResetToken
+	}
+	if err != nil {
+		log.Printf("error consuming password reset token: %v", err)
+		return nil, fmt.Errorf("error consuming password reset token: %v", err)
+	}
+	return token, nil
+}
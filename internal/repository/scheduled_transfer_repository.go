@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ScheduledTransferRepositoryInterface interface {
+	CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) error
+	GetScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, owner string, id int64) (*models.ScheduledTransfer, error)
+	UpdateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer, reschedule bool) error
+	DeleteScheduledTransfer(ctx context.Context, owner string, id int64) (bool, error)
+	GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]models.ScheduledTransfer, error)
+	ClaimScheduledTransferRun(ctx context.Context, run *models.ScheduledTransferRun, next time.Time) (bool, error)
+	FinishScheduledTransferRun(ctx context.Context, run *models.ScheduledTransferRun) error
+	GetScheduledTransferRuns(ctx context.Context, transferID int64, limit int) ([]models.ScheduledTransferRun, error)
+}
+
+type ScheduledTransferRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewScheduledTransferRepository(db *pgxpool.Pool) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{DB: db}
+}
+
+const scheduledTransferColumns = `SELECT t.id, t.owner_id, o.username, r.username, t.amount, t.message, t.category, t.schedule,
+        t.active, t.next_run_at, t.created_at, t.updated_at,
+        lr.id, lr.scheduled_for, lr.status, lr.error, lr.created_at
+    FROM scheduled_transfers t
+    JOIN users o ON o.id = t.owner_id
+    JOIN users r ON r.id = t.recipient_id
+    LEFT JOIN LATERAL (
+        SELECT id, scheduled_for, status, error, created_at FROM scheduled_transfer_runs
+        WHERE scheduled_transfer_id = t.id ORDER BY scheduled_for DESC LIMIT 1
+    ) lr ON true`
+
+// CreateScheduledTransfer stores a scheduled transfer of transfer.Owner to transfer.ToUser. It
+// fails with models.ErrUserNotFound when either of them does not exist.
+func (r *ScheduledTransferRepository) CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) error {
+	query := `INSERT INTO scheduled_transfers (owner_id, recipient_id, amount, message, category, schedule, active, next_run_at)
+        SELECT o.id, r.id, $3, $4, $5, $6, $7, $8 FROM users o, users r WHERE o.username = $1 AND r.username = $2
+        RETURNING id, owner_id, created_at, updated_at`
+	err := r.DB.QueryRow(ctx, query, transfer.Owner, transfer.ToUser, transfer.Amount, transfer.Message, transfer.Category,
+		transfer.Schedule, transfer.Active, transfer.NextRunAt).Scan(&transfer.ID, &transfer.OwnerID, &transfer.CreatedAt, &transfer.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %s", models.ErrUserNotFound, transfer.ToUser)
+	}
+	if err != nil {
+		log.Printf("error creating scheduled transfer: %v", err)
+		return fmt.Errorf("error creating scheduled transfer: %v", err)
+	}
+	return nil
+}
+
+// GetScheduledTransfers returns the scheduled transfers of the owner in the order they were created.
+func (r *ScheduledTransferRepository) GetScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error) {
+	return r.queryScheduledTransfers(ctx, scheduledTransferColumns+` WHERE o.username = $1 ORDER BY t.id`, owner)
+}
+
+// GetScheduledTransfer returns the scheduled transfer of the owner with the id, or nil if there is none.
+func (r *ScheduledTransferRepository) GetScheduledTransfer(ctx context.Context, owner string, id int64) (*models.ScheduledTransfer, error) {
+	transfers, err := r.queryScheduledTransfers(ctx, scheduledTransferColumns+` WHERE o.username = $1 AND t.id = $2`, owner, id)
+	if err != nil || len(transfers) == 0 {
+		return nil, err
+	}
+	return &transfers[0], nil
+}
+
+// UpdateScheduledTransfer stores the amount, message, category, schedule and state of the
+// transfer, and its next run only if reschedule is set. Otherwise next_run_at is left to
+// ClaimScheduledTransferRun, which may advance it meanwhile; transfer.NextRunAt gets the stored value.
+func (r *ScheduledTransferRepository) UpdateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer, reschedule bool) error {
+	query := `UPDATE scheduled_transfers
+        SET amount = $2, message = $3, category = $4, schedule = $5, active = $6,
+            next_run_at = CASE WHEN $8 THEN $7 ELSE next_run_at END, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 RETURNING next_run_at, updated_at`
+	err := r.DB.QueryRow(ctx, query, transfer.ID, transfer.Amount, transfer.Message, transfer.Category, transfer.Schedule,
+		transfer.Active, transfer.NextRunAt, reschedule).Scan(&transfer.NextRunAt, &transfer.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrScheduledTransferNotFound
+	}
+	if err != nil {
+		log.Printf("error updating scheduled transfer: %v", err)
+		return fmt.Errorf("error updating scheduled transfer: %v", err)
+	}
+	return nil
+}
+
+// DeleteScheduledTransfer deletes the scheduled transfer of the owner together with its runs
+// and reports whether there was one.
+func (r *ScheduledTransferRepository) DeleteScheduledTransfer(ctx context.Context, owner string, id int64) (bool, error) {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM scheduled_transfers t USING users o
+        WHERE o.id = t.owner_id AND o.username = $1 AND t.id = $2`, owner, id)
+	if err != nil {
+		log.Printf("error deleting scheduled transfer: %v", err)
+		return false, fmt.Errorf("error deleting scheduled transfer: %v", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetDueScheduledTransfers returns up to limit active transfers whose next run is not after now,
+// the longest overdue first.
+func (r *ScheduledTransferRepository) GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]models.ScheduledTransfer, error) {
+	return r.queryScheduledTransfers(ctx, scheduledTransferColumns+` WHERE t.active AND t.next_run_at <= $1
+        ORDER BY t.next_run_at, t.id LIMIT $2`, now, limit)
+}
+
+// ClaimScheduledTransferRun moves the next run of the transfer from run.ScheduledFor to next and
+// records the run as pending in the same transaction, reporting whether it did. Only one of
+// several executors racing for a run succeeds, and it is the one that sends the transfer; if it
+// stops before finishing the run, the pending run shows that the transfer may have been sent.
+func (r *ScheduledTransferRepository) ClaimScheduledTransferRun(ctx context.Context, run *models.ScheduledTransferRun, next time.Time) (bool, error) {
+	claimed := false
+	err := withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE scheduled_transfers SET next_run_at = $3
+            WHERE id = $1 AND next_run_at = $2 AND active`, run.ScheduledTransferID, run.ScheduledFor, next)
+		if err != nil {
+			log.Printf("error advancing scheduled transfer: %v", err)
+			return fmt.Errorf("error advancing scheduled transfer: %v", err)
+		}
+		if tag.RowsAffected() != 1 {
+			return nil
+		}
+
+		query := `INSERT INTO scheduled_transfer_runs (scheduled_transfer_id, scheduled_for, status, error)
+            VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+		err = tx.QueryRow(ctx, query, run.ScheduledTransferID, run.ScheduledFor, run.Status, run.Error).Scan(&run.ID, &run.CreatedAt)
+		if err != nil {
+			log.Printf("error recording scheduled transfer run: %v", err)
+			return fmt.Errorf("error recording scheduled transfer run: %v", err)
+		}
+		claimed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+// FinishScheduledTransferRun stores the outcome of a run claimed with ClaimScheduledTransferRun.
+func (r *ScheduledTransferRepository) FinishScheduledTransferRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+	_, err := r.DB.Exec(ctx, `UPDATE scheduled_transfer_runs SET status = $2, error = $3 WHERE id = $1`, run.ID, run.Status, run.Error)
+	if err != nil {
+		log.Printf("error finishing scheduled transfer run: %v", err)
+		return fmt.Errorf("error finishing scheduled transfer run: %v", err)
+	}
+	return nil
+}
+
+// GetScheduledTransferRuns returns up to limit runs of the transfer, the latest first.
+func (r *ScheduledTransferRepository) GetScheduledTransferRuns(ctx context.Context, transferID int64, limit int) ([]models.ScheduledTransferRun, error) {
+	rows, err := r.DB.Query(ctx, `SELECT id, scheduled_transfer_id, scheduled_for, status, error, created_at
+        FROM scheduled_transfer_runs WHERE scheduled_transfer_id = $1 ORDER BY scheduled_for DESC LIMIT $2`, transferID, limit)
+	if err != nil {
+		log.Printf("error fetching scheduled transfer runs: %v", err)
+		return nil, fmt.Errorf("error fetching scheduled transfer runs: %v", err)
+	}
+	defer rows.Close()
+
+	var runs []models.ScheduledTransferRun
+	for rows.Next() {
+		var run models.ScheduledTransferRun
+		if err = rows.Scan(&run.ID, &run.ScheduledTransferID, &run.ScheduledFor, &run.Status, &run.Error, &run.CreatedAt); err != nil {
+			log.Printf("error scanning scheduled transfer run: %v", err)
+			return nil, fmt.Errorf("error scanning scheduled transfer run: %v", err)
+		}
+		runs = append(runs, run)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating scheduled transfer runs: %v", err)
+		return nil, fmt.Errorf("error iterating scheduled transfer runs: %v", err)
+	}
+	return runs, nil
+}
+
+func (r *ScheduledTransferRepository) queryScheduledTransfers(ctx context.Context, query string, args ...interface{}) ([]models.ScheduledTransfer, error) {
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("error fetching scheduled transfers: %v", err)
+		return nil, fmt.Errorf("error fetching scheduled transfers: %v", err)
+	}
+	defer rows.Close()
+
+	var transfers []models.ScheduledTransfer
+	for rows.Next() {
+		var transfer models.ScheduledTransfer
+		var runID *int64
+		var runScheduledFor, runCreatedAt *time.Time
+		var runStatus, runError *string
+		err = rows.Scan(&transfer.ID, &transfer.OwnerID, &transfer.Owner, &transfer.ToUser, &transfer.Amount, &transfer.Message,
+			&transfer.Category, &transfer.Schedule, &transfer.Active, &transfer.NextRunAt, &transfer.CreatedAt, &transfer.UpdatedAt,
+			&runID, &runScheduledFor, &runStatus, &runError, &runCreatedAt)
+		if err != nil {
+			log.Printf("error scanning scheduled transfer: %v", err)
+			return nil, fmt.Errorf("error scanning scheduled transfer: %v", err)
+		}
+		if runID != nil {
+			transfer.LastRun = &models.ScheduledTransferRun{
+				ID:                  *runID,
+				ScheduledTransferID: transfer.ID,
+				ScheduledFor:        *runScheduledFor,
+				Status:              *runStatus,
+				Error:               *runError,
+				CreatedAt:           *runCreatedAt,
+			}
+		}
+		transfers = append(transfers, transfer)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating scheduled transfers: %v", err)
+		return nil, fmt.Errorf("error iterating scheduled transfers: %v", err)
+	}
+	return transfers, nil
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TokenRepositoryInterface interface {
+	CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldTokenID int64, newToken *models.RefreshToken, session *models.Session) error
+	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	GetActiveSessions(ctx context.Context, userID int64, now time.Time) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID int64) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string, sessionID int64) (bool, error)
+}
+
+type TokenRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewTokenRepository(db *pgxpool.Pool) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+// CreateSession starts a session with its first refresh token.
+func (r *TokenRepository) CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		if err := insertSession(ctx, tx, session); err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return insertRefreshToken(ctx, tx, token)
+	})
+}
+
+func insertSession(ctx context.Context, tx pgx.Tx, session *models.Session) error {
+	query := `INSERT INTO sessions (user_id, ip, user_agent, last_seen_at, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err := tx.QueryRow(ctx, query, session.UserID, session.IP, session.UserAgent, session.LastSeenAt, session.ExpiresAt).Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		log.Printf("error creating session: %v", err)
+		return fmt.Errorf("error creating session: %v", err)
+	}
+	return nil
+}
+
+func insertRefreshToken(ctx context.Context, tx pgx.Tx, token *models.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, session_id, token_hash, expires_at) VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	err := tx.QueryRow(ctx, query, token.UserID, token.SessionID, token.TokenHash, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		log.Printf("error creating refresh token: %v", err)
+		return fmt.Errorf("error creating refresh token: %v", err)
+	}
+	return nil
+}
+
+func (r *TokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{}
+	query := `SELECT t.id, t.user_id, COALESCE(t.session_id, 0), t.token_hash, t.expires_at, t.created_at, t.revoked_at, s.revoked_at
+		FROM refresh_tokens t LEFT JOIN sessions s ON s.id = t.session_id
+		WHERE t.token_hash = $1`
+
+	err := r.DB.QueryRow(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.SessionID, &token.TokenHash,
+		&token.ExpiresAt, &token.CreatedAt, &token.RevokedAt, &token.SessionRevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("error fetching refresh token: %v", err)
+		return nil, fmt.Errorf("error fetching refresh token: %v", err)
+	}
+	return token, nil
+}
+
+// RotateRefreshToken revokes the old token, stores its replacement and records the use of the
+// session in one transaction. Tokens issued before sessions were introduced have no session, so
+// one is started for them. If the old token has already been revoked by a concurrent request,
+// ErrInvalidRefreshToken is returned.
+func (r *TokenRepository) RotateRefreshToken(ctx context.Context, oldTokenID int64, newToken *models.RefreshToken, session *models.Session) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`, oldTokenID)
+		if err != nil {
+			log.Printf("error revoking refresh token: %v", err)
+			return fmt.Errorf("error revoking refresh token: %v", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return models.ErrInvalidRefreshToken
+		}
+
+		if session.ID == 0 {
+			err = insertSession(ctx, tx, session)
+		} else {
+			_, err = tx.Exec(ctx, `UPDATE sessions SET ip = $1, user_agent = $2, last_seen_at = $3, expires_at = $4 WHERE id = $5`,
+				session.IP, session.UserAgent, session.LastSeenAt, session.ExpiresAt, session.ID)
+			if err != nil {
+				log.Printf("error updating session %d: %v", session.ID, err)
+				err = fmt.Errorf("error updating session %d: %v", session.ID, err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		newToken.SessionID = session.ID
+		return insertRefreshToken(ctx, tx, newToken)
+	})
+}
+
+func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := r.DB.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE token_hash = $1 AND revoked_at IS NULL`, tokenHash)
+	if err != nil {
+		log.Printf("error revoking refresh token: %v", err)
+		return fmt.Errorf("error revoking refresh token: %v", err)
+	}
+	return nil
+}
+
+// GetActiveSessions returns the sessions of the user that are neither revoked nor expired,
+// most recently used first.
+func (r *TokenRepository) GetActiveSessions(ctx context.Context, userID int64, now time.Time) ([]models.Session, error) {
+	query := `SELECT id, user_id, ip, user_agent, created_at, last_seen_at, expires_at, revoked_at FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC, id DESC`
+	rows, err := r.DB.Query(ctx, query, userID, now)
+	if err != nil {
+		log.Printf("error fetching sessions: %v", err)
+		return nil, fmt.Errorf("error fetching sessions: %v", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		err = rows.Scan(&session.ID, &session.UserID, &session.IP, &session.UserAgent, &session.CreatedAt,
+			&session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt)
+		if err != nil {
+			log.Printf("error scanning session: %v", err)
+			return nil, fmt.Errorf("error scanning session: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating sessions: %v", err)
+		return nil, fmt.Errorf("error iterating sessions: %v", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends a session of the user. Its refresh tokens are refused from then on and its
+// access tokens are rejected. ErrSessionNotFound is returned for sessions of other users and for
+// sessions that have already been revoked.
+func (r *TokenRepository) RevokeSession(ctx context.Context, userID int64, sessionID int64) error {
+	tag, err := r.DB.Exec(ctx, `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, sessionID, userID)
+	if err != nil {
+		log.Printf("error revoking session %d: %v", sessionID, err)
+		return fmt.Errorf("error revoking session %d: %v", sessionID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions ends every session of the user, including refresh tokens issued before
+// sessions were introduced.
+func (r *TokenRepository) RevokeUserSessions(ctx context.Context, userID int64) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+		if err != nil {
+			log.Printf("error revoking sessions for user %d: %v", userID, err)
+			return fmt.Errorf("error revoking sessions for user %d: %v", userID, err)
+		}
+		_, err = tx.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+		if err != nil {
+			log.Printf("error revoking refresh tokens for user %d: %v", userID, err)
+			return fmt.Errorf("error revoking refresh tokens for user %d: %v", userID, err)
+		}
+		return nil
+	})
+}
+
+// RevokeAccessToken puts the token id on the deny-list until the token would have expired anyway.
+// Entries that are no longer needed are purged on the way.
+func (r *TokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`, jti, expiresAt)
+		if err != nil {
+			log.Printf("error revoking access token: %v", err)
+			return fmt.Errorf("error revoking access token: %v", err)
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP`)
+		if err != nil {
+			log.Printf("error purging revoked tokens: %v", err)
+			return fmt.Errorf("error purging revoked tokens: %v", err)
+		}
+		return nil
+	})
+}
+
+// IsAccessTokenRevoked reports whether the token has been revoked on its own or together with
+// its session. Tokens issued before sessions were introduced have session id 0.
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string, sessionID int64) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)
+		OR EXISTS(SELECT 1 FROM sessions WHERE id = $2 AND revoked_at IS NOT NULL)`
+	err := r.DB.QueryRow(ctx, query, jti, sessionID).Scan(&revoked)
+	if err != nil {
+		log.Printf("error checking revoked token: %v", err)
+		return false, fmt.Errorf("error checking revoked token: %v", err)
+	}
+	return revoked, nil
+}
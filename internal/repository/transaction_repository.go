@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/avito-shop-service/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -12,7 +13,12 @@ import (
 
 type TransactionRepositoryInterface interface {
 	GetTransactionsByUserID(ctx context.Context, userID int64) ([]models.CoinTransaction, error)
-	CreateTransaction(ctx context.Context, transaction *models.CoinTransaction) error
+	CreateTransaction(ctx context.Context, transaction *models.CoinTransaction, limits *models.TransferLimitCheck) error
+	CreateTransactions(ctx context.Context, transactions []models.CoinTransaction, limits *models.TransferLimitCheck) error
+	GetTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error)
+	GetTransfer(ctx context.Context, id int64) (*models.Transfer, error)
+	ReverseTransfer(ctx context.Context, reversal *models.TransferReversal, requireUnspent bool) error
+	GetHistory(ctx context.Context, filter models.TransactionFilter) ([]models.HistoryEntry, error)
 }
 
 type TransactionRepository struct {
@@ -23,10 +29,19 @@ func NewTransactionRepository(db *pgxpool.Pool) *TransactionRepository {
 	return &TransactionRepository{DB: db}
 }
 
+// GetTransactionsByUserID returns the transfers of the user, newest first, as seen from the user:
+// every transfer entry yields a 'sent' or 'received' transaction with the other party.
 func (r *TransactionRepository) GetTransactionsByUserID(ctx context.Context, userID int64) ([]models.CoinTransaction, error) {
 	var transactions []models.CoinTransaction
-	query := `SELECT id, user_id, counterpart_username, amount, transaction_type, created_at 
-              FROM coin_transactions WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT e.id, a.user_id, cu.username, p.amount, e.message, e.category, e.created_at
+              FROM ledger_postings p
+              JOIN ledger_accounts a ON a.id = p.account_id
+              JOIN ledger_entries e ON e.id = p.entry_id
+              JOIN ledger_postings cp ON cp.entry_id = e.id AND cp.id <> p.id
+              JOIN ledger_accounts ca ON ca.id = cp.account_id
+              JOIN users cu ON cu.id = ca.user_id
+              WHERE a.user_id = $1 AND e.kind = 'transfer'
+              ORDER BY e.created_at DESC, e.id DESC, p.amount`
 
 	rows, err := r.DB.Query(ctx, query, userID)
 	if err != nil {
@@ -35,11 +50,15 @@ func (r *TransactionRepository) GetTransactionsByUserID(ctx context.Context, use
 	defer rows.Close()
 
 	for rows.Next() {
-		transaction := models.CoinTransaction{}
-		err = rows.Scan(&transaction.ID, &transaction.UserID, &transaction.CounterpartUser, &transaction.Amount, &transaction.TransactionType, &transaction.CreatedAt)
+		transaction := models.CoinTransaction{TransactionType: "received"}
+		err = rows.Scan(&transaction.ID, &transaction.UserID, &transaction.CounterpartUser, &transaction.Amount, &transaction.Message, &transaction.Category, &transaction.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row: %v", err)
 		}
+		if transaction.Amount < 0 {
+			transaction.TransactionType = "sent"
+			transaction.Amount = -transaction.Amount
+		}
 		transactions = append(transactions, transaction)
 	}
 
@@ -50,65 +69,429 @@ func (r *TransactionRepository) GetTransactionsByUserID(ctx context.Context, use
 	return transactions, nil
 }
 
-func (r *TransactionRepository) CreateTransaction(ctx context.Context, transaction *models.CoinTransaction) error {
-	var anotherUserId int64
-	var currentUsername string
-	err := r.DB.QueryRow(ctx, `SELECT id FROM users WHERE username= $1`, transaction.CounterpartUser).Scan(&anotherUserId)
-	if err != nil {
-		log.Printf("failed to scan another user id: %v", err)
-		return fmt.Errorf("failed to scan another user id: %v", err)
+// GetHistory returns up to filter.Limit postings on the account of the user that match the
+// filter, in the order of (created_at, id) given by filter.Ascending and starting after
+// filter.After. The counterpart of a posting is the other party of its entry: a username, or
+// the code of a system account.
+func (r *TransactionRepository) GetHistory(ctx context.Context, filter models.TransactionFilter) ([]models.HistoryEntry, error) {
+	conditions := []string{"a.user_id = $1"}
+	args := []interface{}{filter.UserID}
+	switch filter.Direction {
+	case models.DirectionSent:
+		conditions = append(conditions, "p.amount < 0")
+	case models.DirectionReceived:
+		conditions = append(conditions, "p.amount > 0")
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("e.kind = $%d", len(args)))
+	}
+	if filter.Counterpart != "" {
+		args = append(args, filter.Counterpart)
+		conditions = append(conditions, fmt.Sprintf("c.name = $%d", len(args)))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("p.created_at >= $%d", len(args)))
+	}
+	if filter.Until != nil {
+		args = append(args, *filter.Until)
+		conditions = append(conditions, fmt.Sprintf("p.created_at < $%d", len(args)))
 	}
+	order, after := "DESC", "<"
+	if filter.Ascending {
+		order, after = "ASC", ">"
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.CreatedAt, filter.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(p.created_at, p.id) %s ($%d, $%d)", after, len(args)-1, len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := `SELECT p.id, e.id, e.kind, COALESCE(c.name, ''), p.amount, e.description, e.message, e.category, p.created_at
+              FROM ledger_accounts a
+              JOIN ledger_postings p ON p.account_id = a.id
+              JOIN ledger_entries e ON e.id = p.entry_id
+              LEFT JOIN LATERAL (
+                  SELECT COALESCE(cu.username, ca.code) AS name
+                  FROM ledger_postings cp
+                  JOIN ledger_accounts ca ON ca.id = cp.account_id
+                  LEFT JOIN users cu ON cu.id = ca.user_id
+                  WHERE cp.entry_id = p.entry_id AND cp.id <> p.id
+                  ORDER BY cp.id LIMIT 1
+              ) c ON true
+              WHERE ` + strings.Join(conditions, " AND ") + fmt.Sprintf(`
+              ORDER BY p.created_at %s, p.id %s LIMIT $%d`, order, order, len(args))
 
-	err = r.DB.QueryRow(ctx, `SELECT username FROM users WHERE id= $1`, transaction.UserID).Scan(&currentUsername)
+	rows, err := r.DB.Query(ctx, query, args...)
 	if err != nil {
-		log.Printf("failed to scan current user username id: %v", err)
-		return fmt.Errorf("failed to scan current user username id: %v", err)
+		log.Printf("error fetching transaction history: %v", err)
+		return nil, fmt.Errorf("error fetching transaction history: %v", err)
 	}
+	defer rows.Close()
 
-	tx, err := r.DB.BeginTx(ctx, pgx.TxOptions{})
-	if err != nil {
-		log.Printf("failed to start transaction: %v", err)
-		return fmt.Errorf("failed to start transaction: %v", err)
+	var history []models.HistoryEntry
+	for rows.Next() {
+		entry := models.HistoryEntry{Direction: models.DirectionReceived}
+		err = rows.Scan(&entry.ID, &entry.EntryID, &entry.Type, &entry.Counterpart, &entry.Amount,
+			&entry.Description, &entry.Message, &entry.Category, &entry.CreatedAt)
+		if err != nil {
+			log.Printf("error scanning history entry: %v", err)
+			return nil, fmt.Errorf("error scanning history entry: %v", err)
+		}
+		if entry.Amount < 0 {
+			entry.Direction = models.DirectionSent
+			entry.Amount = -entry.Amount
+		}
+		history = append(history, entry)
+	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating history entries: %v", err)
+		return nil, fmt.Errorf("error iterating history entries: %v", err)
 	}
-	defer func() {
-		err = tx.Rollback(ctx)
-		if strings.Contains(err.Error(), "tx is closed") {
-			return
+	return history, nil
+}
+
+// CreateTransaction moves coins from transaction.UserID to transaction.CounterpartUser. Both
+// balance rows are locked for the duration of the transfer, so concurrent transfers cannot
+// overdraw the sender; a balance that is too low yields a *models.InsufficientFundsError. Unless
+// limits is nil, the transfer must fit the transfer limits of the sender as well, counted under
+// the same lock, or it fails with a *models.TransferLimitError.
+func (r *TransactionRepository) CreateTransaction(ctx context.Context, transaction *models.CoinTransaction, limits *models.TransferLimitCheck) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		// Rows are locked in id order so that opposite transfers between two users cannot deadlock.
+		rows, err := tx.Query(ctx, `SELECT id, username, status, coins FROM users
+                                    WHERE id = $1 OR username = $2 ORDER BY id FOR UPDATE`, transaction.UserID, transaction.CounterpartUser)
+		if err != nil {
+			log.Printf("failed to lock balances: %v", err)
+			return fmt.Errorf("failed to lock balances: %v", err)
+		}
+		var sender, recipient *transferParty
+		for rows.Next() {
+			party := &transferParty{}
+			if err = rows.Scan(&party.id, &party.username, &party.status, &party.coins); err != nil {
+				rows.Close()
+				log.Printf("error scanning row: %v", err)
+				return fmt.Errorf("error scanning row: %v", err)
+			}
+			if party.id == transaction.UserID {
+				sender = party
+			}
+			if party.username == transaction.CounterpartUser {
+				recipient = party
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			log.Printf("error iterating rows: %v", err)
+			return fmt.Errorf("error iterating rows: %v", err)
+		}
+		if sender == nil {
+			return models.ErrUserNotFound
+		}
+		if recipient == nil {
+			return fmt.Errorf("%w: %s", models.ErrUserNotFound, transaction.CounterpartUser)
+		}
+		if sender == recipient {
+			return models.ErrSelfTransfer
 		}
-		log.Printf("error rolling back transaction: %v", err)
-	}()
 
-	updateCoinsQuery := `UPDATE users SET coins = coins + $1 WHERE id = $2`
-	_, err = tx.Exec(ctx, updateCoinsQuery, -transaction.Amount, transaction.UserID)
-	if err != nil {
-		log.Printf("error deducting coins: %v", err)
-		return fmt.Errorf("error deducting coins: %v", err)
+		// Suspended and terminated employees can neither send nor receive coins.
+		if sender.status != models.UserStatusActive {
+			return &models.AccountInactiveError{Username: sender.username, Status: sender.status}
+		}
+		if recipient.status != models.UserStatusActive {
+			return &models.AccountInactiveError{Username: recipient.username, Status: recipient.status}
+		}
+		if err = checkTransferLimits(ctx, tx, limits, sender.id, recipient.username, transaction.Amount, models.TransferUsage{}); err != nil {
+			return err
+		}
+		if sender.coins < transaction.Amount {
+			return &models.InsufficientFundsError{Balance: sender.coins, Required: transaction.Amount}
+		}
+
+		return postEntry(ctx, tx, &models.LedgerEntry{
+			Kind:     models.EntryKindTransfer,
+			Message:  transaction.Message,
+			Category: transaction.Category,
+			Postings: []models.Posting{
+				{UserID: sender.id, Amount: -transaction.Amount},
+				{UserID: recipient.id, Amount: transaction.Amount},
+			},
+		})
+	})
+}
+
+// CreateTransactions sends the transfers of a batch from one sender to distinct recipients in a
+// single database transaction: either all of them are posted or none is. A transfer that fails
+// fails the batch with a *models.TransferBatchError naming it. The limits, unless nil, count the
+// earlier transfers of the batch as sent. The id and time of each posted transfer are set on it.
+func (r *TransactionRepository) CreateTransactions(ctx context.Context, transactions []models.CoinTransaction, limits *models.TransferLimitCheck) error {
+	if len(transactions) == 0 {
+		return nil
 	}
-	_, err = tx.Exec(ctx, updateCoinsQuery, transaction.Amount, anotherUserId)
-	if err != nil {
-		log.Printf("error adding coins: %v", err)
-		return fmt.Errorf("error adding coins: %v", err)
+	recipients := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		recipients[i] = transaction.CounterpartUser
 	}
 
-	_, err = tx.Exec(ctx, `INSERT INTO coin_transactions (user_id, counterpart_username, amount, transaction_type) 
-                           VALUES ($1, $2, $3, 'sent')`, transaction.UserID, transaction.CounterpartUser, transaction.Amount)
-	if err != nil {
-		log.Printf("failed to log sender transaction: %v", err)
-		return fmt.Errorf("failed to log sender transaction: %v", err)
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		// Rows are locked in id order, as for single transfers, so that batches cannot deadlock.
+		rows, err := tx.Query(ctx, `SELECT id, username, status, coins FROM users
+                                    WHERE id = $1 OR username = ANY($2) ORDER BY id FOR UPDATE`, transactions[0].UserID, recipients)
+		if err != nil {
+			log.Printf("failed to lock balances: %v", err)
+			return fmt.Errorf("failed to lock balances: %v", err)
+		}
+		var sender *transferParty
+		parties := make(map[string]*transferParty, len(recipients))
+		for rows.Next() {
+			party := &transferParty{}
+			if err = rows.Scan(&party.id, &party.username, &party.status, &party.coins); err != nil {
+				rows.Close()
+				log.Printf("error scanning row: %v", err)
+				return fmt.Errorf("error scanning row: %v", err)
+			}
+			if party.id == transactions[0].UserID {
+				sender = party
+			}
+			parties[party.username] = party
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			log.Printf("error iterating rows: %v", err)
+			return fmt.Errorf("error iterating rows: %v", err)
+		}
+		if sender == nil {
+			return models.ErrUserNotFound
+		}
+		if sender.status != models.UserStatusActive {
+			return &models.AccountInactiveError{Username: sender.username, Status: sender.status}
+		}
+
+		balance := sender.coins
+		var pending models.TransferUsage
+		for i := range transactions {
+			transaction := &transactions[i]
+			fail := func(err error) error {
+				return &models.TransferBatchError{Index: i, ToUser: transaction.CounterpartUser, Err: err}
+			}
+
+			recipient := parties[transaction.CounterpartUser]
+			if recipient == nil {
+				return fail(fmt.Errorf("%w: %s", models.ErrUserNotFound, transaction.CounterpartUser))
+			}
+			if recipient == sender {
+				return fail(models.ErrSelfTransfer)
+			}
+			if recipient.status != models.UserStatusActive {
+				return fail(&models.AccountInactiveError{Username: recipient.username, Status: recipient.status})
+			}
+			if err = checkTransferLimits(ctx, tx, limits, sender.id, recipient.username, transaction.Amount, pending); err != nil {
+				return fail(err)
+			}
+			if balance < transaction.Amount {
+				return fail(&models.InsufficientFundsError{Balance: balance, Required: transaction.Amount})
+			}
+
+			entry := &models.LedgerEntry{
+				Kind:     models.EntryKindTransfer,
+				Message:  transaction.Message,
+				Category: transaction.Category,
+				Postings: []models.Posting{
+					{UserID: sender.id, Amount: -transaction.Amount},
+					{UserID: recipient.id, Amount: transaction.Amount},
+				},
+			}
+			if err = postEntry(ctx, tx, entry); err != nil {
+				return fail(err)
+			}
+			balance -= transaction.Amount
+			pending.Daily += transaction.Amount
+			pending.Monthly += transaction.Amount
+			transaction.ID, transaction.CreatedAt = entry.ID, entry.CreatedAt
+		}
+		return nil
+	})
+}
+
+const transferColumns = `SELECT e.id, su.username, ru.username, rp.amount, e.message, e.category, rv.id, e.created_at
+              FROM ledger_entries e
+              JOIN ledger_postings sp ON sp.entry_id = e.id AND sp.amount < 0
+              JOIN ledger_accounts sa ON sa.id = sp.account_id
+              JOIN users su ON su.id = sa.user_id
+              JOIN ledger_postings rp ON rp.entry_id = e.id AND rp.amount > 0
+              JOIN ledger_accounts ra ON ra.id = rp.account_id
+              JOIN users ru ON ru.id = ra.user_id
+              LEFT JOIN ledger_entries rv ON rv.reverses_entry_id = e.id`
+
+// GetTransfers returns the transfers matching the filter, newest first.
+func (r *TransactionRepository) GetTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error) {
+	conditions := []string{"e.kind = 'transfer'"}
+	var args []interface{}
+	if filter.Username != "" {
+		args = append(args, filter.Username)
+		conditions = append(conditions, fmt.Sprintf("(su.username = $%d OR ru.username = $%d)", len(args), len(args)))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf("e.category = $%d", len(args)))
 	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("e.created_at >= $%d", len(args)))
+	}
+	if filter.Until != nil {
+		args = append(args, *filter.Until)
+		conditions = append(conditions, fmt.Sprintf("e.created_at < $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
 
-	_, err = tx.Exec(ctx, `INSERT INTO coin_transactions (user_id, counterpart_username, amount, transaction_type) 
-                           VALUES ($1, $2, $3, 'received')`, anotherUserId, currentUsername, transaction.Amount)
-	if err != nil {
-		log.Printf("failed to log receiver transaction: %v", err)
-		return fmt.Errorf("failed to log receiver transaction: %v", err)
+	query := transferColumns + `
+              WHERE ` + strings.Join(conditions, " AND ") + fmt.Sprintf(`
+              ORDER BY e.created_at DESC, e.id DESC LIMIT $%d`, len(args))
+	return r.queryTransfers(ctx, query, args...)
+}
+
+// GetTransfer returns the transfer with the id, or nil if there is none.
+func (r *TransactionRepository) GetTransfer(ctx context.Context, id int64) (*models.Transfer, error) {
+	transfers, err := r.queryTransfers(ctx, transferColumns+` WHERE e.kind = 'transfer' AND e.id = $1`, id)
+	if err != nil || len(transfers) == 0 {
+		return nil, err
 	}
+	return &transfers[0], nil
+}
+
+// ReverseTransfer moves the coins of transfer reversal.TransferID back from the recipient to the
+// sender with a refund entry and fills in the rest of the reversal. The recipient must still
+// hold the coins; with requireUnspent the recipient must not have spent any coins since the
+// transfer either. Such refusals match models.ErrTransferNotReversible.
+func (r *TransactionRepository) ReverseTransfer(ctx context.Context, reversal *models.TransferReversal, requireUnspent bool) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		var senderID, recipientID int64
+		err := tx.QueryRow(ctx, `SELECT sa.user_id, su.username, ra.user_id, ru.username, rp.amount
+                                 FROM ledger_entries e
+                                 JOIN ledger_postings sp ON sp.entry_id = e.id AND sp.amount < 0
+                                 JOIN ledger_accounts sa ON sa.id = sp.account_id
+                                 JOIN users su ON su.id = sa.user_id
+                                 JOIN ledger_postings rp ON rp.entry_id = e.id AND rp.amount > 0
+                                 JOIN ledger_accounts ra ON ra.id = rp.account_id
+                                 JOIN users ru ON ru.id = ra.user_id
+                                 WHERE e.id = $1 AND e.kind = 'transfer' FOR UPDATE OF e`, reversal.TransferID).
+			Scan(&senderID, &reversal.FromUser, &recipientID, &reversal.ToUser, &reversal.Amount)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrTransferNotFound
+		}
+		if err != nil {
+			log.Printf("error fetching transfer: %v", err)
+			return fmt.Errorf("error fetching transfer: %v", err)
+		}
+
+		// The transfer row is locked, so this sees a reversal committed while waiting for the lock.
+		var reversed bool
+		err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM ledger_entries WHERE reverses_entry_id = $1)`, reversal.TransferID).Scan(&reversed)
+		if err != nil {
+			log.Printf("error checking transfer reversal: %v", err)
+			return fmt.Errorf("error checking transfer reversal: %v", err)
+		}
+		if reversed {
+			return models.ErrTransferAlreadyReversed
+		}
+
+		// Rows are locked in id order, as in CreateTransaction, so that reversals and transfers
+		// between the same users cannot deadlock.
+		rows, err := tx.Query(ctx, `SELECT id, coins FROM users WHERE id = $1 OR id = $2 ORDER BY id FOR UPDATE`, senderID, recipientID)
+		if err != nil {
+			log.Printf("failed to lock balances: %v", err)
+			return fmt.Errorf("failed to lock balances: %v", err)
+		}
+		var recipientCoins int
+		for rows.Next() {
+			var id int64
+			var coins int
+			if err = rows.Scan(&id, &coins); err != nil {
+				rows.Close()
+				log.Printf("error scanning row: %v", err)
+				return fmt.Errorf("error scanning row: %v", err)
+			}
+			if id == recipientID {
+				recipientCoins = coins
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			log.Printf("error iterating rows: %v", err)
+			return fmt.Errorf("error iterating rows: %v", err)
+		}
 
-	err = tx.Commit(ctx)
+		if requireUnspent {
+			var spent bool
+			err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM ledger_postings p JOIN ledger_accounts a ON a.id = p.account_id
+                                    WHERE a.user_id = $1 AND p.amount < 0 AND p.entry_id > $2)`, recipientID, reversal.TransferID).Scan(&spent)
+			if err != nil {
+				log.Printf("error checking spending of %s: %v", reversal.ToUser, err)
+				return fmt.Errorf("error checking spending of %s: %v", reversal.ToUser, err)
+			}
+			if spent {
+				return fmt.Errorf("%w: %s has spent coins since the transfer", models.ErrTransferNotReversible, reversal.ToUser)
+			}
+		}
+		if recipientCoins < reversal.Amount {
+			return fmt.Errorf("%w: %s has %d of the %d coins left", models.ErrTransferNotReversible, reversal.ToUser, recipientCoins, reversal.Amount)
+		}
+
+		description := "reversed by " + reversal.ReversedBy
+		if reversal.Reason != "" {
+			description += ": " + reversal.Reason
+		}
+		entry := &models.LedgerEntry{
+			Kind:            models.EntryKindRefund,
+			Description:     description,
+			ReversesEntryID: &reversal.TransferID,
+			Postings: []models.Posting{
+				{UserID: recipientID, Amount: -reversal.Amount},
+				{UserID: senderID, Amount: reversal.Amount},
+			},
+		}
+		if err = postEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+		reversal.ID = entry.ID
+		reversal.CreatedAt = entry.CreatedAt
+		return nil
+	})
+}
+
+func (r *TransactionRepository) queryTransfers(ctx context.Context, query string, args ...interface{}) ([]models.Transfer, error) {
+	rows, err := r.DB.Query(ctx, query, args...)
 	if err != nil {
-		log.Printf("error committing transaction: %v", err)
-		return fmt.Errorf("error committing transaction: %v", err)
+		log.Printf("error fetching transfers: %v", err)
+		return nil, fmt.Errorf("error fetching transfers: %v", err)
+	}
+	defer rows.Close()
+
+	var transfers []models.Transfer
+	for rows.Next() {
+		var transfer models.Transfer
+		err = rows.Scan(&transfer.ID, &transfer.FromUser, &transfer.ToUser, &transfer.Amount, &transfer.Message, &transfer.Category,
+			&transfer.ReversalID, &transfer.CreatedAt)
+		if err != nil {
+			log.Printf("error scanning transfer: %v", err)
+			return nil, fmt.Errorf("error scanning transfer: %v", err)
+		}
+		transfers = append(transfers, transfer)
 	}
+	if err = rows.Err(); err != nil {
+		log.Printf("error iterating transfers: %v", err)
+		return nil, fmt.Errorf("error iterating transfers: %v", err)
+	}
+	return transfers, nil
+}
 
-	return nil
+// transferParty is the locked balance row of the sender or the recipient of a transfer.
+type transferParty struct {
+	id       int64
+	username string
+	status   string
+	coins    int
 }
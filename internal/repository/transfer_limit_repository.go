@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TransferLimitRepositoryInterface interface {
+	GetTransferLimitException(ctx context.Context, userID int64) (*models.TransferLimitException, error)
+	SaveTransferLimitException(ctx context.Context, exception *models.TransferLimitException) error
+	DeleteTransferLimitException(ctx context.Context, userID int64) (bool, error)
+}
+
+type TransferLimitRepository struct {
+	DB *pgxpool.Pool
+}
+
+func NewTransferLimitRepository(db *pgxpool.Pool) *TransferLimitRepository {
+	return &TransferLimitRepository{DB: db}
+}
+
+// GetTransferLimitException returns the exception of the user, expired or not, or nil if there is none.
+func (r *TransferLimitRepository) GetTransferLimitException(ctx context.Context, userID int64) (*models.TransferLimitException, error) {
+	exception := &models.TransferLimitException{}
+	query := `SELECT x.user_id, u.username, x.max_per_transfer, x.daily_limit, x.monthly_limit, x.recipient_limit,
+                     x.reason, x.expires_at, x.created_by, x.created_at
+              FROM transfer_limit_exceptions x JOIN users u ON u.id = x.user_id WHERE x.user_id = $1`
+	err := r.DB.QueryRow(ctx, query, userID).Scan(&exception.UserID, &exception.Username, &exception.MaxPerTransfer,
+		&exception.DailyLimit, &exception.MonthlyLimit, &exception.RecipientLimit, &exception.Reason, &exception.ExpiresAt,
+		&exception.CreatedBy, &exception.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("error fetching transfer limit exception: %v", err)
+		return nil, fmt.Errorf("error fetching transfer limit exception: %v", err)
+	}
+	return exception, nil
+}
+
+// SaveTransferLimitException creates the exception of the user or replaces the existing one.
+func (r *TransferLimitRepository) SaveTransferLimitException(ctx context.Context, exception *models.TransferLimitException) error {
+	query := `INSERT INTO transfer_limit_exceptions
+                  (user_id, max_per_transfer, daily_limit, monthly_limit, recipient_limit, reason, expires_at, created_by)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+              ON CONFLICT (user_id) DO UPDATE SET max_per_transfer = EXCLUDED.max_per_transfer,
+                  daily_limit = EXCLUDED.daily_limit, monthly_limit = EXCLUDED.monthly_limit,
+                  recipient_limit = EXCLUDED.recipient_limit, reason = EXCLUDED.reason, expires_at = EXCLUDED.expires_at,
+                  created_by = EXCLUDED.created_by, created_at = CURRENT_TIMESTAMP
+              RETURNING created_at`
+	err := r.DB.QueryRow(ctx, query, exception.UserID, exception.MaxPerTransfer, exception.DailyLimit, exception.MonthlyLimit,
+		exception.RecipientLimit, exception.Reason, exception.ExpiresAt, exception.CreatedBy).Scan(&exception.CreatedAt)
+	if err != nil {
+		log.Printf("error saving transfer limit exception: %v", err)
+		return fmt.Errorf("error saving transfer limit exception: %v", err)
+	}
+	return nil
+}
+
+// DeleteTransferLimitException removes the exception of the user and reports whether there was one.
+func (r *TransferLimitRepository) DeleteTransferLimitException(ctx context.Context, userID int64) (bool, error) {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM transfer_limit_exceptions WHERE user_id = $1`, userID)
+	if err != nil {
+		log.Printf("error deleting transfer limit exception: %v", err)
+		return false, fmt.Errorf("error deleting transfer limit exception: %v", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// checkTransferLimits evaluates the limits of the sender against a transfer of the amount to the
+// recipient, counting pending as sent on top of the transfers in the database. Reversed transfers
+// do not count. It runs in the transaction that posts the transfer after the balance of the
+// sender is locked, so transfers of the sender that commit meanwhile are counted.
+func checkTransferLimits(ctx context.Context, tx pgx.Tx, limits *models.TransferLimitCheck, userID int64, recipient string, amount int, pending models.TransferUsage) error {
+	if limits == nil {
+		return nil
+	}
+	usage := pending
+	if limits.CountsUsage() {
+		query := `SELECT COALESCE(SUM(-p.amount) FILTER (WHERE p.created_at >= $2), 0),
+                         COALESCE(SUM(-p.amount) FILTER (WHERE p.created_at >= $3), 0),
+                         COALESCE(SUM(-p.amount) FILTER (WHERE p.created_at >= $4 AND ru.username = $5), 0)
+                  FROM ledger_accounts a
+                  JOIN ledger_postings p ON p.account_id = a.id AND p.amount < 0
+                  JOIN ledger_entries e ON e.id = p.entry_id AND e.kind = 'transfer'
+                  JOIN ledger_postings rp ON rp.entry_id = e.id AND rp.amount > 0
+                  JOIN ledger_accounts ra ON ra.id = rp.account_id
+                  JOIN users ru ON ru.id = ra.user_id
+                  WHERE a.user_id = $1 AND p.created_at >= LEAST($2::timestamp, $3::timestamp, $4::timestamp)
+                    AND NOT EXISTS (SELECT 1 FROM ledger_entries rv WHERE rv.reverses_entry_id = e.id)`
+		var sent models.TransferUsage
+		err := tx.QueryRow(ctx, query, userID, limits.DayStart, limits.MonthStart, limits.RecipientStart, recipient).
+			Scan(&sent.Daily, &sent.Monthly, &sent.Recipient)
+		if err != nil {
+			log.Printf("error fetching transfer usage: %v", err)
+			return fmt.Errorf("error fetching transfer usage: %v", err)
+		}
+		usage.Daily += sent.Daily
+		usage.Monthly += sent.Monthly
+		usage.Recipient += sent.Recipient
+	}
+	return limits.Evaluate(usage, amount)
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// withTx runs fn inside a transaction, committing when it returns nil and rolling back otherwise.
+// Errors returned by fn are passed through unchanged so callers can match them with errors.Is.
+func withTx(ctx context.Context, db *pgxpool.Pool, fn func(tx pgx.Tx) error) error {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		log.Printf("failed to start transaction: %v", err)
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			log.Printf("error rolling back transaction: %v", rollbackErr)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("error committing transaction: %v", err)
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
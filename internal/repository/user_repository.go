@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/avito-shop-service/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -13,8 +14,14 @@ import (
 
 type UserRepositoryInterface interface {
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
 	CreateUser(ctx context.Context, user *models.User) error
 	UpdateUserCoins(ctx context.Context, userID int64, coins int) error
+	UpdateUserPassword(ctx context.Context, userID int64, password string) error
+	ChangeUserPassword(ctx context.Context, userID int64, password string, changedAt time.Time) error
+	UpdateUserProfile(ctx context.Context, userID int64, profile models.Profile) error
+	UpdateUserStatus(ctx context.Context, userID int64, status string) error
+	UpdateUserDepartment(ctx context.Context, userID int64, department string) error
 }
 
 type UserRepository struct {
@@ -25,11 +32,23 @@ func NewUserRepository(db *pgxpool.Pool) *UserRepository {
 	return &UserRepository{DB: db}
 }
 
+const selectUserQuery = `SELECT u.id, u.username, u.password, u.coins, u.roles, u.scopes, u.password_changed_at,
+		u.display_name, u.email, u.department, COALESCE(m.username, ''), u.hire_date, u.status
+	FROM users u LEFT JOIN users m ON m.id = u.manager_id`
+
 func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
-	user := &models.User{}
-	query := `SELECT id, username, password, coins FROM users WHERE username = $1`
+	return r.getUser(ctx, selectUserQuery+` WHERE u.username = $1`, username)
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	return r.getUser(ctx, selectUserQuery+` WHERE u.id = $1`, userID)
+}
 
-	err := r.DB.QueryRow(ctx, query, username).Scan(&user.ID, &user.Username, &user.Password, &user.Coins)
+func (r *UserRepository) getUser(ctx context.Context, query string, arg interface{}) (*models.User, error) {
+	user := &models.User{}
+	var hireDate *time.Time
+	err := r.DB.QueryRow(ctx, query, arg).Scan(&user.ID, &user.Username, &user.Password, &user.Coins, &user.Roles, &user.Scopes,
+		&user.PasswordChangedAt, &user.DisplayName, &user.Email, &user.Department, &user.Manager, &hireDate, &user.Status)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -37,44 +56,120 @@ func (r *UserRepository) GetUserByUsername(ctx context.Context, username string)
 		log.Printf("error fetching user: %v", err)
 		return nil, fmt.Errorf("error fetching user: %v", err)
 	}
+	if hireDate != nil {
+		user.HireDate = hireDate.Format(time.DateOnly)
+	}
 	return user, nil
 }
 
+// CreateUser creates the user together with their ledger account. The initial balance in
+// user.Coins is granted through the ledger.
 func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
-	query := `INSERT INTO users (username, password, coins) VALUES ($1, $2, $3)`
-	_, err := r.DB.Exec(ctx, query, user.Username, user.Password, user.Coins)
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		query := `INSERT INTO users (username, password, coins, roles) VALUES ($1, $2, 0, $3) RETURNING id`
+		err := tx.QueryRow(ctx, query, user.Username, user.Password, user.Roles).Scan(&user.ID)
+		if isUniqueViolation(err) {
+			return models.ErrUserAlreadyExists
+		}
+		if err != nil {
+			log.Printf("error creating user: %v", err)
+			return fmt.Errorf("error creating user: %v", err)
+		}
+
+		if _, err = tx.Exec(ctx, `INSERT INTO ledger_accounts (user_id) VALUES ($1)`, user.ID); err != nil {
+			log.Printf("error creating ledger account: %v", err)
+			return fmt.Errorf("error creating ledger account: %v", err)
+		}
+		if user.Coins == 0 {
+			return nil
+		}
+		return postEntry(ctx, tx, &models.LedgerEntry{
+			Kind:        models.EntryKindGrant,
+			Description: "welcome grant",
+			Postings: []models.Posting{
+				{Account: models.AccountIssuance, Amount: -user.Coins},
+				{UserID: user.ID, Amount: user.Coins},
+			},
+		})
+	})
+}
+
+// UpdateUserCoins sets the balance of the user by posting the difference as an adjustment.
+func (r *UserRepository) UpdateUserCoins(ctx context.Context, userID int64, coins int) error {
+	return withTx(ctx, r.DB, func(tx pgx.Tx) error {
+		var balance int
+		err := tx.QueryRow(ctx, `SELECT coins FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&balance)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrUserNotFound
+		}
+		if err != nil {
+			log.Printf("error fetching balance of user %d: %v", userID, err)
+			return fmt.Errorf("error fetching balance of user %d: %v", userID, err)
+		}
+		if balance == coins {
+			return nil
+		}
+		return postEntry(ctx, tx, &models.LedgerEntry{
+			Kind:        models.EntryKindAdjustment,
+			Description: fmt.Sprintf("balance set to %d", coins),
+			Postings: []models.Posting{
+				{Account: models.AccountIssuance, Amount: balance - coins},
+				{UserID: userID, Amount: coins - balance},
+			},
+		})
+	})
+}
+
+func (r *UserRepository) UpdateUserPassword(ctx context.Context, userID int64, password string) error {
+	_, err := r.DB.Exec(ctx, `UPDATE users SET password = $1 WHERE id = $2`, password, userID)
 	if err != nil {
-		log.Printf("error creating user: %v", err)
-		return fmt.Errorf("error creating user: %v", err)
+		log.Printf("error updating password for user %d: %v", userID, err)
+		return fmt.Errorf("error updating password for user %d: %v", userID, err)
 	}
 	return nil
 }
 
-func (r *UserRepository) UpdateUserCoins(ctx context.Context, userID int64, coins int) error {
-	tx, err := r.DB.BeginTx(ctx, pgx.TxOptions{})
+// ChangeUserPassword stores a password set by the user or through a reset. Unlike
+// UpdateUserPassword, which only rehashes the same password, it records the change time.
+func (r *UserRepository) ChangeUserPassword(ctx context.Context, userID int64, password string, changedAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE users SET password = $1, password_changed_at = $2 WHERE id = $3`, password, changedAt, userID)
 	if err != nil {
-		log.Printf("error starting transaction: %v", err)
-		return fmt.Errorf("error starting transaction: %v", err)
+		log.Printf("error changing password for user %d: %v", userID, err)
+		return fmt.Errorf("error changing password for user %d: %v", userID, err)
 	}
+	return nil
+}
 
-	_, err = tx.Exec(ctx, `UPDATE users SET coins = $1 WHERE id = $2`, coins, userID)
+// UpdateUserProfile stores the fields users edit themselves. The manager is given by username
+// and must exist; the status and the department are changed only by UpdateUserStatus and
+// UpdateUserDepartment.
+func (r *UserRepository) UpdateUserProfile(ctx context.Context, userID int64, profile models.Profile) error {
+	query := `UPDATE users SET display_name = $1, email = $2,
+			manager_id = (SELECT id FROM users WHERE username = NULLIF($3, '')),
+			hire_date = NULLIF($4, '')::date
+		WHERE id = $5`
+	_, err := r.DB.Exec(ctx, query, profile.DisplayName, profile.Email, profile.Manager, profile.HireDate, userID)
 	if err != nil {
-		log.Printf("error updating coins for user %d: %v", userID, err)
-		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-			log.Printf("error rolling back transaction: %v", rollbackErr)
-			return fmt.Errorf("error rolling back transaction: %v, original error: %v", rollbackErr, err)
-		}
-		return fmt.Errorf("error updating coins for user %d: %v", userID, err)
+		log.Printf("error updating profile of user %d: %v", userID, err)
+		return fmt.Errorf("error updating profile of user %d: %v", userID, err)
 	}
-	err = tx.Commit(ctx)
+	return nil
+}
+
+func (r *UserRepository) UpdateUserStatus(ctx context.Context, userID int64, status string) error {
+	_, err := r.DB.Exec(ctx, `UPDATE users SET status = $1 WHERE id = $2`, status, userID)
 	if err != nil {
-		log.Printf("error committing transaction: %v", err)
-		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-			log.Printf("error rolling back transaction after commit failure: %v", rollbackErr)
-			return fmt.Errorf("error committing transaction: %v, error rolling back: %v", err, rollbackErr)
-		}
-		return fmt.Errorf("error committing transaction: %v", err)
+		log.Printf("error updating status of user %d: %v", userID, err)
+		return fmt.Errorf("error updating status of user %d: %v", userID, err)
 	}
+	return nil
+}
 
+func (r *UserRepository) UpdateUserDepartment(ctx context.Context, userID int64, department string) error {
+	_, err := r.DB.Exec(ctx, `UPDATE users SET department = $1 WHERE id = $2`, department, userID)
+	if err != nil {
+		log.Printf("error updating department of user %d: %v", userID, err)
+		return fmt.Errorf("error updating department of user %d: %v", userID, err)
+	}
 	return nil
 }
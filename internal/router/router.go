@@ -3,15 +3,78 @@ package router
 import (
 	"github.com/avito-shop-service/internal/handlers"
 	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/pkg/auth"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 )
 
-func NewRouter(transactionHandler *handlers.TransactionHandler, userHandler *handlers.UserHandler, buyHandler *handlers.BuyHandler, infoHandler *handlers.InformationHandler) http.Handler {
+func NewRouter(authMiddleware *middleware.AuthMiddleware, idempotencyMiddleware *middleware.IdempotencyMiddleware, transactionHandler *handlers.TransactionHandler, userHandler *handlers.UserHandler, tokenHandler *handlers.TokenHandler, jwksHandler *handlers.JWKSHandler, buyHandler *handlers.BuyHandler, infoHandler *handlers.InformationHandler, merchHandler *handlers.MerchHandler, apiKeyHandler *handlers.APIKeyHandler, passwordHandler *handlers.PasswordHandler, sessionHandler *handlers.SessionHandler, profileHandler *handlers.ProfileHandler, ledgerHandler *handlers.LedgerHandler, scheduledTransferHandler *handlers.ScheduledTransferHandler, transferLimitHandler *handlers.TransferLimitHandler, transferReversalHandler *handlers.TransferReversalHandler, issuanceHandler *handlers.IssuanceHandler, oidcHandler *handlers.OIDCHandler) http.Handler {
 	r := chi.NewRouter()
-	r.With(middleware.AuthMiddleware).Get("/api/info", infoHandler.GetInfo)
-	r.With(middleware.AuthMiddleware).Get("/api/buy/{item}", buyHandler.Buy)
-	r.With(middleware.AuthMiddleware).Post("/api/sendCoin", transactionHandler.SendCoin)
+	r.With(authMiddleware.Handle, middleware.RequireScope(auth.ScopeInfoRead)).Get("/api/info", infoHandler.GetInfo)
+	r.With(authMiddleware.Handle, middleware.RequireScope(auth.ScopeMerchBuy), idempotencyMiddleware.Handle).Get("/api/buy/{item}", buyHandler.Buy)
+	r.With(authMiddleware.Handle, middleware.RequireScope(auth.ScopeCoinsSend), idempotencyMiddleware.Handle).Post("/api/sendCoin", transactionHandler.SendCoin)
+	r.With(authMiddleware.Handle, middleware.RequireScope(auth.ScopeCoinsSend), idempotencyMiddleware.Handle).Post("/api/sendCoin/batch", transactionHandler.SendCoinBatch)
+	r.With(authMiddleware.Handle, middleware.RequireScope(auth.ScopeCoinsSend)).Post("/api/transfers/{id}/cancel", transferReversalHandler.CancelTransfer)
+	r.With(authMiddleware.Handle, middleware.RequireScope(auth.ScopeInfoRead)).Get("/api/transactions", transactionHandler.GetTransactions)
+	r.With(authMiddleware.Handle, middleware.RequireScope(auth.ScopeInfoRead)).Get("/api/kudos/categories", transactionHandler.GetCategories)
 	r.Post("/api/auth", userHandler.Auth)
+	r.Post("/api/register", userHandler.Register)
+	r.Post("/api/login", userHandler.Login)
+	r.Post("/api/auth/refresh", tokenHandler.Refresh)
+	r.With(authMiddleware.Handle).Post("/api/auth/logout", tokenHandler.Logout)
+	r.Post("/api/auth/password/reset", passwordHandler.ResetPassword)
+	r.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+	if oidcHandler != nil {
+		r.Get("/api/auth/oidc/login", oidcHandler.Login)
+		r.Get("/api/auth/oidc/callback", oidcHandler.Callback)
+	}
+
+	r.Route("/api/me", func(r chi.Router) {
+		r.Use(authMiddleware.Handle, middleware.RequireToken)
+		r.Get("/", profileHandler.GetProfile)
+		r.Patch("/", profileHandler.UpdateProfile)
+		r.Post("/api-keys", apiKeyHandler.CreateAPIKey)
+		r.Get("/api-keys", apiKeyHandler.GetAPIKeys)
+		r.Delete("/api-keys/{id}", apiKeyHandler.RevokeAPIKey)
+		r.Post("/password", passwordHandler.ChangePassword)
+		r.Get("/sessions", sessionHandler.GetSessions)
+		r.Delete("/sessions/{id}", sessionHandler.RevokeSession)
+	})
+
+	r.Route("/api/scheduled-transfers", func(r chi.Router) {
+		r.Use(authMiddleware.Handle, middleware.RequireScope(auth.ScopeCoinsSend))
+		r.Post("/", scheduledTransferHandler.CreateScheduledTransfer)
+		r.Get("/", scheduledTransferHandler.GetScheduledTransfers)
+		r.Get("/{id}", scheduledTransferHandler.GetScheduledTransfer)
+		r.Patch("/{id}", scheduledTransferHandler.UpdateScheduledTransfer)
+		r.Delete("/{id}", scheduledTransferHandler.DeleteScheduledTransfer)
+		r.Get("/{id}/runs", scheduledTransferHandler.GetScheduledTransferRuns)
+	})
+
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(authMiddleware.Handle)
+		r.With(middleware.RequireRole(auth.RoleMerchAdmin), middleware.RequireScope(auth.ScopeMerchWrite)).Post("/merch", merchHandler.CreateMerch)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(auth.RoleFinanceAdmin), middleware.RequireScope(auth.ScopeCoinsAdmin))
+			r.Get("/ledger/verify", ledgerHandler.VerifyLedger)
+			r.Get("/transfers", transactionHandler.GetTransfers)
+			r.Post("/transfers/{id}/reverse", transferReversalHandler.ReverseTransfer)
+			r.Post("/coins/grant", issuanceHandler.GrantCoins)
+			r.Post("/coins/burn", issuanceHandler.BurnCoins)
+			r.Get("/users/{username}/transfer-limits", transferLimitHandler.GetTransferLimits)
+			r.Put("/users/{username}/transfer-limits", transferLimitHandler.SetTransferLimitException)
+			r.Delete("/users/{username}/transfer-limits", transferLimitHandler.DeleteTransferLimitException)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(auth.RoleSecurityAdmin), middleware.RequireScope(auth.ScopeUsersAdmin))
+			r.Post("/users/{username}/unlock", userHandler.Unlock)
+			r.Post("/users/{username}/password-reset", passwordHandler.CreateResetToken)
+			r.Put("/users/{username}/status", profileHandler.SetUserStatus)
+			r.Put("/users/{username}/department", profileHandler.SetUserDepartment)
+			r.Get("/users/{username}/sessions", sessionHandler.GetUserSessions)
+			r.Delete("/users/{username}/sessions/{id}", sessionHandler.RevokeUserSession)
+			r.Get("/logins", sessionHandler.GetLoginEvents)
+		})
+	})
 	return r
 }
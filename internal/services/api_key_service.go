@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const (
+	DefaultAPIKeyRateLimit = 60
+
+	apiKeyPrefix       = "ask_"
+	apiKeyPrefixLen    = 8
+	lastUsedResolution = time.Minute
+)
+
+type APIKeyServiceInterface interface {
+	CreateAPIKey(ctx context.Context, username string, req models.APIKey) (*models.CreatedAPIKey, error)
+	GetAPIKeys(ctx context.Context, username string) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, username string, keyID int64) error
+	ValidateAPIKey(ctx context.Context, key string) (*auth.Claims, error)
+}
+
+type APIKeyService struct {
+	repository       repository.APIKeyRepositoryInterface
+	userRepository   repository.UserRepositoryInterface
+	rateLimiter      *RateLimiter
+	defaultRateLimit int
+	clock            auth.Clock
+}
+
+// NewAPIKeyService creates the service. defaultRateLimit, DefaultAPIKeyRateLimit if it is not
+// positive, is the rate limit of keys created without one and the highest one a key may have.
+func NewAPIKeyService(repo repository.APIKeyRepositoryInterface, userRepo repository.UserRepositoryInterface, rateLimiter *RateLimiter, defaultRateLimit int, clock auth.Clock) *APIKeyService {
+	if defaultRateLimit <= 0 {
+		defaultRateLimit = DefaultAPIKeyRateLimit
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &APIKeyService{
+		repository:       repo,
+		userRepository:   userRepo,
+		rateLimiter:      rateLimiter,
+		defaultRateLimit: defaultRateLimit,
+		clock:            clock,
+	}
+}
+
+// CreateAPIKey creates a key with the name, scopes, rate limit and expiry taken from req.
+// The scopes must be a subset of the scopes the user currently has, and the rate limit must not
+// exceed the configured one.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, username string, req models.APIKey) (*models.CreatedAPIKey, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	userScopes := auth.ResolveScopes(user.Roles, user.Scopes)
+	if len(req.Scopes) == 0 {
+		return nil, models.ErrInvalidScopes
+	}
+	for _, scope := range req.Scopes {
+		if !contains(userScopes, scope) {
+			return nil, models.ErrInvalidScopes
+		}
+	}
+
+	rateLimit := req.RateLimit
+	if rateLimit <= 0 {
+		rateLimit = s.defaultRateLimit
+	}
+	if rateLimit > s.defaultRateLimit {
+		return nil, fmt.Errorf("%w: must be at most %d requests per minute", models.ErrInvalidRateLimit, s.defaultRateLimit)
+	}
+
+	key, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := models.APIKey{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    key[:len(apiKeyPrefix)+apiKeyPrefixLen],
+		KeyHash:   hashAPIKey(key),
+		Scopes:    req.Scopes,
+		RateLimit: rateLimit,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err = s.repository.CreateAPIKey(ctx, &apiKey); err != nil {
+		return nil, fmt.Errorf("error creating api key: %v", err)
+	}
+
+	return &models.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(ctx context.Context, username string) ([]models.APIKey, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.GetAPIKeysByUserID(ctx, user.ID)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, username string, keyID int64) error {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	err = s.repository.RevokeAPIKey(ctx, user.ID, keyID)
+	if err != nil && !errors.Is(err, models.ErrAPIKeyNotFound) {
+		return fmt.Errorf("error revoking api key: %v", err)
+	}
+	return err
+}
+
+// ValidateAPIKey authenticates a request made with an API key. The returned claims carry the
+// key scopes that the owner still has, so revoking a role also narrows the keys. Requests over
+// the key rate limit are refused with a *models.RateLimitedError.
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, key string) (*auth.Claims, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, models.ErrInvalidAPIKey
+	}
+	apiKey, err := s.repository.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching api key: %v", err)
+	}
+	now := s.clock.Now()
+	if apiKey == nil || apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt)) {
+		return nil, models.ErrInvalidAPIKey
+	}
+
+	if ok, retryAfter := s.rateLimiter.Allow(apiKey.ID, apiKey.RateLimit); !ok {
+		return nil, &models.RateLimitedError{RetryAfter: retryAfter}
+	}
+
+	user, err := s.userRepository.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return nil, models.ErrInvalidAPIKey
+	}
+	if !user.IsActive() {
+		return nil, &models.AccountInactiveError{Status: user.Status}
+	}
+
+	// last_used_at is only needed with minute precision, so most requests skip the write.
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		if err = s.repository.UpdateAPIKeyLastUsed(ctx, apiKey.ID, now); err != nil {
+			log.Printf("error updating last use of api key %d: %v", apiKey.ID, err)
+		}
+	}
+
+	userScopes := auth.ResolveScopes(user.Roles, user.Scopes)
+	var scopes []string
+	for _, scope := range apiKey.Scopes {
+		if contains(userScopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &auth.Claims{
+		EmployeeUsername: user.Username,
+		Roles:            user.Roles,
+		Scopes:           scopes,
+		APIKeyID:         apiKey.ID,
+	}, nil
+}
+
+func (s *APIKeyService) getUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func newAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating api key: %v", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/avito-shop-service/internal/models"
+)
+
+const (
+	ProviderLocal = "local"
+	ProviderLDAP  = "ldap"
+	ProviderOIDC  = "oidc"
+)
+
+// Authenticator verifies credentials against an identity provider.
+type Authenticator interface {
+	// Name identifies the provider, e.g. "local", "ldap" or "oidc".
+	Name() string
+	// Authenticate returns the identity the credentials belong to. Credentials the provider
+	// does not accept, including ones of a kind it does not handle, give models.ErrInvalidCredentials.
+	Authenticate(ctx context.Context, credentials models.Credentials) (*models.Identity, error)
+}
+
+// AuthCodeAuthenticator is an Authenticator for the OAuth 2.0 authorization code flow.
+type AuthCodeAuthenticator interface {
+	Authenticator
+	// AuthCodeURL returns the provider URL the user is redirected to for signing in.
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+}
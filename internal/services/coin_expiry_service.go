@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const (
+	DefaultCoinLifetimeMonths = 12
+	DefaultCoinExpiryInterval = time.Hour
+	coinExpiryBatchSize       = 100
+	upcomingExpirationsLimit  = 10
+)
+
+type CoinExpiryServiceInterface interface {
+	GetUpcomingExpirations(ctx context.Context, userID int64) ([]models.CoinExpiration, error)
+}
+
+type CoinExpiryService struct {
+	repository     repository.CoinLotRepositoryInterface
+	lifetimeMonths int
+	clock          auth.Clock
+}
+
+// NewCoinExpiryService creates the service; coins expire lifetimeMonths after they were granted,
+// DefaultCoinLifetimeMonths if it is not positive. A nil clock means the system clock.
+func NewCoinExpiryService(repo repository.CoinLotRepositoryInterface, lifetimeMonths int, clock auth.Clock) *CoinExpiryService {
+	if lifetimeMonths <= 0 {
+		lifetimeMonths = DefaultCoinLifetimeMonths
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &CoinExpiryService{repository: repo, lifetimeMonths: lifetimeMonths, clock: clock}
+}
+
+// GetUpcomingExpirations returns when the coins of the user expire unless spent, the soonest
+// first and at most upcomingExpirationsLimit of them.
+func (s *CoinExpiryService) GetUpcomingExpirations(ctx context.Context, userID int64) ([]models.CoinExpiration, error) {
+	lots, err := s.repository.GetOpenLots(ctx, userID, upcomingExpirationsLimit)
+	if err != nil {
+		return nil, err
+	}
+	expirations := make([]models.CoinExpiration, 0, len(lots))
+	for _, lot := range lots {
+		expirations = append(expirations, models.CoinExpiration{
+			Amount:    lot.Remaining,
+			ExpiresAt: lot.GrantedAt.UTC().AddDate(0, s.lifetimeMonths, 0),
+		})
+	}
+	return expirations, nil
+}
+
+// ExpireCoins takes away the coins left in lots granted more than the lifetime ago, with an
+// expiry entry in the history of every user affected. An error with one user is logged and does
+// not hold up the others of the batch; the errors are returned together after the batch.
+func (s *CoinExpiryService) ExpireCoins(ctx context.Context) error {
+	// granted_at holds UTC without a zone.
+	cutoff := s.clock.Now().UTC().AddDate(0, -s.lifetimeMonths, 0)
+	description := fmt.Sprintf("coins granted before %s expired", cutoff.Format(time.DateOnly))
+
+	users, coins := 0, 0
+	var errs []error
+	for {
+		userIDs, err := s.repository.GetUsersWithLotsGrantedBefore(ctx, cutoff, coinExpiryBatchSize)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		for _, userID := range userIDs {
+			expired, err := s.repository.ExpireLots(ctx, userID, cutoff, description)
+			if err != nil {
+				log.Printf("error expiring coins of user %d: %v", userID, err)
+				errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+				continue
+			}
+			if expired > 0 {
+				users++
+				coins += expired
+			}
+		}
+		// The users that failed are still found by the next query, so the batches stop here
+		// rather than retry them until the next run.
+		if len(errs) > 0 || len(userIDs) < coinExpiryBatchSize {
+			break
+		}
+	}
+	if users > 0 {
+		log.Printf("expired %d coins of %d users", coins, users)
+	}
+	return errors.Join(errs...)
+}
+
+// Run expires stale coins every interval until the context is cancelled.
+func (s *CoinExpiryService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCoinExpiryInterval
+	}
+	RunPeriodically(ctx, "coin expiry", interval, s.ExpireCoins)
+}
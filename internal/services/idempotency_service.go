@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const (
+	DefaultIdempotencyKeyTTL          = 24 * time.Hour
+	DefaultIdempotencyCleanupInterval = time.Hour
+	maxIdempotencyKeyLen              = 255
+)
+
+type IdempotencyServiceInterface interface {
+	Begin(ctx context.Context, username, key, fingerprint string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+}
+
+type IdempotencyService struct {
+	repository repository.IdempotencyRepositoryInterface
+	ttl        time.Duration
+	clock      auth.Clock
+}
+
+func NewIdempotencyService(repo repository.IdempotencyRepositoryInterface, ttl time.Duration, clock auth.Clock) *IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &IdempotencyService{repository: repo, ttl: ttl, clock: clock}
+}
+
+// Begin claims the key of the user for the request with the fingerprint. It returns nil when the
+// request should be processed, and the stored response when the same request was already
+// completed with the key. A key used for a different request yields ErrIdempotencyMismatch,
+// a key whose request is still being processed ErrIdempotencyConflict.
+func (s *IdempotencyService) Begin(ctx context.Context, username, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	if key == "" || len(key) > maxIdempotencyKeyLen {
+		return nil, fmt.Errorf("%w: must be 1 to %d characters long", models.ErrInvalidIdempotency, maxIdempotencyKeyLen)
+	}
+
+	now := s.clock.Now()
+	created, err := s.repository.CreateIdempotencyRecord(ctx, &models.IdempotencyRecord{
+		Username:    username,
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return nil, nil
+	}
+
+	record, err := s.repository.GetIdempotencyRecord(ctx, username, key, now)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		// The record expired in between; the caller may retry.
+		return nil, models.ErrIdempotencyConflict
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, models.ErrIdempotencyMismatch
+	}
+	if !record.Completed() {
+		return nil, models.ErrIdempotencyConflict
+	}
+	return record, nil
+}
+
+// Complete stores the response of a request begun with Begin for replay.
+func (s *IdempotencyService) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	return s.repository.CompleteIdempotencyRecord(ctx, record)
+}
+
+// PurgeExpired deletes the records whose keys have expired.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) error {
+	purged, err := s.repository.DeleteExpiredIdempotencyRecords(ctx, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Printf("purged %d expired idempotency keys", purged)
+	}
+	return nil
+}
+
+// Run purges expired keys every interval until the context is cancelled.
+func (s *IdempotencyService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultIdempotencyCleanupInterval
+	}
+	RunPeriodically(ctx, "idempotency key cleanup", interval, s.PurgeExpired)
+}
@@ -9,6 +9,7 @@ import (
 
 type InventoryServiceInterface interface {
 	GetInventoryByUserID(ctx context.Context, userID int64) ([]models.Inventory, error)
+	GetPurchasesByUserID(ctx context.Context, userID int64) ([]models.Purchase, error)
 	BuyItemToInventory(ctx context.Context, userID int64, itemID int64, quantity int, price int) error
 	UpdateItemQuantity(ctx context.Context, userID int, itemID int, quantity int) error
 	GetItemFromInventory(ctx context.Context, userID int, itemID int) (*models.Inventory, error)
@@ -34,6 +35,17 @@ func (s *InventoryService) GetInventoryByUserID(ctx context.Context, userID int6
 	return inventoryItems, nil
 }
 
+func (s *InventoryService) GetPurchasesByUserID(ctx context.Context, userID int64) ([]models.Purchase, error) {
+	if userID < 0 {
+		return nil, fmt.Errorf("user id mustn't be negative")
+	}
+	purchases, err := s.repo.GetPurchasesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting purchases: %v", err)
+	}
+	return purchases, nil
+}
+
 func (s *InventoryService) BuyItemToInventory(ctx context.Context, userID int64, itemID int64, quantity int, price int) error {
 	if userID < 0 {
 		return fmt.Errorf("user id mustn't be negative")
@@ -46,7 +58,7 @@ func (s *InventoryService) BuyItemToInventory(ctx context.Context, userID int64,
 	}
 	err := s.repo.BuyItemToInventory(ctx, userID, itemID, quantity, price)
 	if err != nil {
-		return fmt.Errorf("error adding item to inventory: %v", err)
+		return fmt.Errorf("error adding item to inventory: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const (
+	DefaultAllowanceInterval = time.Hour
+	maxIssuanceReasonLen     = 200
+	allowanceBatchSize       = 100
+)
+
+// AllowancePolicy grants Amount coins to every active user once per Period: daily, weekly
+// (ISO weeks) or monthly, the default. Periods are calendar periods in UTC. A zero Amount
+// disables the allowance.
+type AllowancePolicy struct {
+	Amount int
+	Period string
+}
+
+type IssuanceServiceInterface interface {
+	Issue(ctx context.Context, issuance *models.CoinIssuance) error
+}
+
+type IssuanceService struct {
+	repository repository.IssuanceRepositoryInterface
+	allowance  AllowancePolicy
+	clock      auth.Clock
+}
+
+// NewIssuanceService creates the service. It fails when the allowance policy is invalid; a nil
+// clock means the system clock.
+func NewIssuanceService(repo repository.IssuanceRepositoryInterface, allowance AllowancePolicy, clock auth.Clock) (*IssuanceService, error) {
+	allowance.Period = strings.ToLower(strings.TrimSpace(allowance.Period))
+	if allowance.Period == "" {
+		allowance.Period = models.AllowanceMonthly
+	}
+	switch allowance.Period {
+	case models.AllowanceDaily, models.AllowanceWeekly, models.AllowanceMonthly:
+	default:
+		return nil, fmt.Errorf("unknown allowance period %q, must be %s, %s or %s", allowance.Period,
+			models.AllowanceDaily, models.AllowanceWeekly, models.AllowanceMonthly)
+	}
+	if allowance.Amount < 0 {
+		return nil, fmt.Errorf("allowance amount must not be negative")
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &IssuanceService{repository: repo, allowance: allowance, clock: clock}, nil
+}
+
+// Issue grants or burns coins of one user or of every active member of a department. Only
+// administrators assign departments, so employees cannot join or leave one to game it. The
+// operation is all or nothing: a burn that any of the users cannot cover burns nothing.
+func (s *IssuanceService) Issue(ctx context.Context, issuance *models.CoinIssuance) error {
+	if issuance.Operation != models.IssuanceGrant && issuance.Operation != models.IssuanceBurn {
+		return fmt.Errorf("%w: operation must be %s or %s", models.ErrInvalidIssuance, models.IssuanceGrant, models.IssuanceBurn)
+	}
+	if issuance.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", models.ErrInvalidIssuance)
+	}
+	issuance.Username = strings.TrimSpace(issuance.Username)
+	issuance.Department = strings.TrimSpace(issuance.Department)
+	if (issuance.Username == "") == (issuance.Department == "") {
+		return fmt.Errorf("%w: either username or department is required", models.ErrInvalidIssuance)
+	}
+	issuance.Reason = strings.TrimSpace(issuance.Reason)
+	if issuance.Reason == "" || len(issuance.Reason) > maxIssuanceReasonLen {
+		return fmt.Errorf("%w: reason must be 1 to %d characters long", models.ErrInvalidIssuance, maxIssuanceReasonLen)
+	}
+	return s.repository.IssueCoins(ctx, issuance)
+}
+
+// GrantAllowance grants the allowance of the current period to every active user who has not
+// received it yet. Running it again in the same period grants nothing, so users created during
+// a period get its allowance on the next run. An error with one user is logged and does not hold
+// up the others of the batch; the errors are returned together after the batch.
+func (s *IssuanceService) GrantAllowance(ctx context.Context) error {
+	if s.allowance.Amount <= 0 {
+		return nil
+	}
+	period := allowancePeriod(s.clock.Now().UTC(), s.allowance.Period)
+
+	granted := 0
+	var errs []error
+	for {
+		userIDs, err := s.repository.GetUsersWithoutAllowance(ctx, period, allowanceBatchSize)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		for _, userID := range userIDs {
+			ok, err := s.repository.GrantAllowance(ctx, userID, period, s.allowance.Amount)
+			if err != nil {
+				log.Printf("error granting the %s allowance to user %d: %v", period, userID, err)
+				errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+				continue
+			}
+			if ok {
+				granted++
+			}
+		}
+		// The users that failed are still found by the next query, so the batches stop here
+		// rather than retry them until the next run.
+		if len(errs) > 0 || len(userIDs) < allowanceBatchSize {
+			break
+		}
+	}
+	if granted > 0 {
+		log.Printf("granted the %s allowance of %d coins to %d users", period, s.allowance.Amount, granted)
+	}
+	return errors.Join(errs...)
+}
+
+// Run grants the allowance every interval until the context is cancelled. It returns at once
+// when the allowance is disabled.
+func (s *IssuanceService) Run(ctx context.Context, interval time.Duration) {
+	if s.allowance.Amount <= 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultAllowanceInterval
+	}
+	RunPeriodically(ctx, "allowance", interval, s.GrantAllowance)
+}
+
+// allowancePeriod names the period of the time, for example 2026-10 for a monthly allowance,
+// 2026-W42 for a weekly one and 2026-10-17 for a daily one.
+func allowancePeriod(t time.Time, period string) string {
+	switch period {
+	case models.AllowanceDaily:
+		return t.Format(time.DateOnly)
+	case models.AllowanceWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return t.Format("2006-01")
+	}
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunPeriodically calls job every interval until the context is cancelled. Errors are logged
+// and do not stop later runs.
+func RunPeriodically(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.Printf("%s failed: %v", name, err)
+			}
+		}
+	}
+}
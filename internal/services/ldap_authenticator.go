@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const defaultLDAPTimeout = 5 * time.Second
+
+// LDAPConfig describes the directory to authenticate against. The user entry is looked up
+// with the service account BindDN under BaseDN by UserFilter, where %s is replaced by the
+// escaped username, and the password is then checked by binding as that entry.
+type LDAPConfig struct {
+	URL               string
+	BindDN            string
+	BindPassword      string
+	BaseDN            string
+	UserFilter        string
+	UsernameAttribute string
+	Timeout           time.Duration
+}
+
+// LDAPAuthenticator checks passwords with an LDAP bind.
+type LDAPAuthenticator struct {
+	cfg LDAPConfig
+}
+
+// NewLDAPAuthenticator creates the authenticator. UserFilter defaults to (uid=%s) and
+// UsernameAttribute, the attribute the shop username is taken from, to uid.
+func NewLDAPAuthenticator(cfg LDAPConfig) (*LDAPAuthenticator, error) {
+	if cfg.URL == "" || cfg.BaseDN == "" {
+		return nil, fmt.Errorf("ldap url and base dn are required")
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.UsernameAttribute == "" {
+		cfg.UsernameAttribute = "uid"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultLDAPTimeout
+	}
+	return &LDAPAuthenticator{cfg: cfg}, nil
+}
+
+func (a *LDAPAuthenticator) Name() string {
+	return ProviderLDAP
+}
+
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, credentials models.Credentials) (*models.Identity, error) {
+	// An empty password would be an unauthenticated bind, which servers accept for any DN.
+	if credentials.Username == "" || credentials.Password == "" {
+		return nil, models.ErrInvalidCredentials
+	}
+
+	conn, err := ldap.DialURL(a.cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: a.cfg.Timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to ldap: %v", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(a.cfg.Timeout)
+
+	if a.cfg.BindDN != "" {
+		if err = conn.Bind(a.cfg.BindDN, a.cfg.BindPassword); err != nil {
+			return nil, fmt.Errorf("error binding ldap service account: %v", err)
+		}
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(a.cfg.Timeout.Seconds()), false,
+		fmt.Sprintf(a.cfg.UserFilter, ldap.EscapeFilter(credentials.Username)),
+		[]string{a.cfg.UsernameAttribute},
+		nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("error searching ldap user: %v", err)
+	}
+	if result == nil || len(result.Entries) != 1 {
+		return nil, models.ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	err = conn.Bind(entry.DN, credentials.Password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return nil, models.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error binding ldap user: %v", err)
+	}
+
+	username := entry.GetAttributeValue(a.cfg.UsernameAttribute)
+	if username == "" {
+		username = credentials.Username
+	}
+	return &models.Identity{Provider: ProviderLDAP, Subject: entry.DN, Username: username}, nil
+}
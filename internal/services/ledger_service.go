@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+)
+
+type LedgerServiceInterface interface {
+	VerifyLedger(ctx context.Context) (*models.LedgerReport, error)
+}
+
+type LedgerService struct {
+	repository repository.LedgerRepositoryInterface
+}
+
+func NewLedgerService(repo repository.LedgerRepositoryInterface) *LedgerService {
+	return &LedgerService{repository: repo}
+}
+
+// VerifyLedger checks that every journal entry balances and that every cached balance equals
+// the sum of the postings on the account. Violations are logged, since they mean balances were
+// changed outside the ledger.
+func (s *LedgerService) VerifyLedger(ctx context.Context) (*models.LedgerReport, error) {
+	report, err := s.repository.VerifyLedger(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Balanced = len(report.UnbalancedEntries) == 0 && len(report.Mismatches) == 0
+	if !report.Balanced {
+		log.Printf("ledger invariant violated: %d unbalanced entries, %d balance mismatches", len(report.UnbalancedEntries), len(report.Mismatches))
+	}
+	return report, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+)
+
+// LocalAuthenticator checks passwords stored in the shop database.
+type LocalAuthenticator struct {
+	repository repository.UserRepositoryInterface
+}
+
+func NewLocalAuthenticator(repo repository.UserRepositoryInterface) *LocalAuthenticator {
+	return &LocalAuthenticator{repository: repo}
+}
+
+func (a *LocalAuthenticator) Name() string {
+	return ProviderLocal
+}
+
+// Authenticate verifies the password. Unknown usernames are verified against a dummy hash,
+// so they take as long as wrong passwords.
+func (a *LocalAuthenticator) Authenticate(ctx context.Context, credentials models.Credentials) (*models.Identity, error) {
+	if credentials.Username == "" || credentials.Password == "" {
+		return nil, models.ErrInvalidCredentials
+	}
+
+	user, err := a.repository.GetUserByUsername(ctx, credentials.Username)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	hashedPassword := dummyPasswordHash()
+	if user != nil {
+		hashedPassword = user.Password
+	}
+	ok, needsRehash := VerifyPassword(credentials.Password, hashedPassword)
+	if !ok || user == nil {
+		return nil, models.ErrInvalidCredentials
+	}
+	if needsRehash {
+		a.rehashPassword(ctx, user, credentials.Password)
+	}
+
+	return &models.Identity{Provider: ProviderLocal, Subject: user.Username, Username: user.Username, User: user}, nil
+}
+
+// rehashPassword upgrades a stored hash to the current scheme. A failure here must not
+// block the login, so it is only logged and retried on the next successful authentication.
+func (a *LocalAuthenticator) rehashPassword(ctx context.Context, user *models.User, password string) {
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		log.Printf("error rehashing password for user %d: %v", user.ID, err)
+		return
+	}
+	if err = a.repository.UpdateUserPassword(ctx, user.ID, hashedPassword); err != nil {
+		log.Printf("error rehashing password for user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash returns an argon2id hash with the current parameters that no password matches
+// in practice. It is computed once, on the first login of an unknown user.
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		hash, err := HashPassword("dummy password for unknown users")
+		if err != nil {
+			log.Printf("error hashing dummy password: %v", err)
+			return
+		}
+		dummyHash = hash
+	})
+	return dummyHash
+}
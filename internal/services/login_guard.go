@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+// LoginGuardPolicy configures brute-force protection. Every failed login of a username blocks
+// further attempts for BackoffBase, doubling with each consecutive failure up to BackoffMax;
+// after MaxFailures the username is locked for LockoutDuration. Client addresses are shared
+// by whole offices, so they get no backoff and are only locked after IPMaxFailures.
+type LoginGuardPolicy struct {
+	MaxFailures     int
+	LockoutDuration time.Duration
+	IPMaxFailures   int
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+	FailureWindow   time.Duration
+}
+
+var DefaultLoginGuardPolicy = LoginGuardPolicy{
+	MaxFailures:     10,
+	LockoutDuration: 15 * time.Minute,
+	IPMaxFailures:   100,
+	BackoffBase:     time.Second,
+	BackoffMax:      time.Minute,
+	FailureWindow:   15 * time.Minute,
+}
+
+type LoginGuardInterface interface {
+	Check(ctx context.Context, username string, client models.ClientInfo) error
+	RecordFailure(ctx context.Context, username string, client models.ClientInfo)
+	RecordSuccess(ctx context.Context, username string)
+	Unlock(ctx context.Context, username string) error
+}
+
+type LoginGuard struct {
+	repository repository.LoginFailureRepositoryInterface
+	policy     LoginGuardPolicy
+	clock      auth.Clock
+}
+
+// NewLoginGuard creates a guard with the given policy; zero fields fall back to
+// DefaultLoginGuardPolicy. A nil clock means the system clock.
+func NewLoginGuard(repo repository.LoginFailureRepositoryInterface, policy LoginGuardPolicy, clock auth.Clock) *LoginGuard {
+	if policy.MaxFailures <= 0 {
+		policy.MaxFailures = DefaultLoginGuardPolicy.MaxFailures
+	}
+	if policy.LockoutDuration <= 0 {
+		policy.LockoutDuration = DefaultLoginGuardPolicy.LockoutDuration
+	}
+	if policy.IPMaxFailures <= 0 {
+		policy.IPMaxFailures = DefaultLoginGuardPolicy.IPMaxFailures
+	}
+	if policy.BackoffBase <= 0 {
+		policy.BackoffBase = DefaultLoginGuardPolicy.BackoffBase
+	}
+	if policy.BackoffMax <= 0 {
+		policy.BackoffMax = DefaultLoginGuardPolicy.BackoffMax
+	}
+	if policy.FailureWindow <= 0 {
+		policy.FailureWindow = DefaultLoginGuardPolicy.FailureWindow
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &LoginGuard{repository: repo, policy: policy, clock: clock}
+}
+
+// Check returns a *models.LoginBlockedError while the username or the client address is blocked.
+// Unknown usernames are tracked like existing ones, so the answer does not reveal which exist.
+func (g *LoginGuard) Check(ctx context.Context, username string, client models.ClientInfo) error {
+	now := g.clock.Now()
+	var retryAfter time.Duration
+	for _, key := range loginKeys(username, client) {
+		failure, err := g.repository.GetLoginFailure(ctx, key.kind, key.subject)
+		if err != nil {
+			return fmt.Errorf("error checking login failures: %v", err)
+		}
+		if failure != nil && failure.BlockedUntil != nil && failure.BlockedUntil.After(now) {
+			retryAfter = max(retryAfter, failure.BlockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return &models.LoginBlockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login and blocks the username or the address when the policy says so.
+// Errors are only logged: the caller already answers with invalid credentials.
+func (g *LoginGuard) RecordFailure(ctx context.Context, username string, client models.ClientInfo) {
+	now := g.clock.Now()
+	for _, key := range loginKeys(username, client) {
+		failures, err := g.repository.RecordLoginFailure(ctx, key.kind, key.subject, now, g.policy.FailureWindow)
+		if err != nil {
+			log.Printf("error recording login failure for %s %q: %v", key.kind, key.subject, err)
+			continue
+		}
+		if delay := g.blockDuration(key.kind, failures); delay > 0 {
+			if err = g.repository.BlockLogin(ctx, key.kind, key.subject, now.Add(delay)); err != nil {
+				log.Printf("error blocking login for %s %q: %v", key.kind, key.subject, err)
+			}
+		}
+	}
+}
+
+// RecordSuccess clears the failures of the username. Failures of the address are kept, so a
+// valid account of its own does not let an attacker reset the address counter.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, username string) {
+	if err := g.repository.ResetLoginFailures(ctx, models.LoginKindUsername, username); err != nil {
+		log.Printf("error resetting login failures for %q: %v", username, err)
+	}
+}
+
+// Unlock lifts the lockout of the username and clears its failures.
+func (g *LoginGuard) Unlock(ctx context.Context, username string) error {
+	return g.repository.ResetLoginFailures(ctx, models.LoginKindUsername, username)
+}
+
+func (g *LoginGuard) blockDuration(kind string, failures int) time.Duration {
+	if kind == models.LoginKindIP {
+		if failures >= g.policy.IPMaxFailures {
+			return g.policy.LockoutDuration
+		}
+		return 0
+	}
+	if failures >= g.policy.MaxFailures {
+		return g.policy.LockoutDuration
+	}
+
+	delay := g.policy.BackoffBase
+	for i := 1; i < failures && delay < g.policy.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, g.policy.BackoffMax)
+}
+
+type loginKey struct {
+	kind    string
+	subject string
+}
+
+func loginKeys(username string, client models.ClientInfo) []loginKey {
+	keys := []loginKey{{kind: models.LoginKindUsername, subject: username}}
+	if client.IP != "" {
+		keys = append(keys, loginKey{kind: models.LoginKindIP, subject: client.IP})
+	}
+	return keys
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
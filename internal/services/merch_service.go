@@ -40,7 +40,7 @@ func (s *MerchService) GetMerchByName(ctx context.Context, name string) (*models
 		return nil, err
 	}
 	if merch == nil {
-		return nil, fmt.Errorf("merch not found")
+		return nil, models.ErrMerchNotFound
 	}
 	return merch, nil
 }
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	defaultOIDCUsernameClaim = "preferred_username"
+	oidcJWKSRefreshInterval  = time.Minute
+)
+
+// OIDCConfig describes the OpenID Connect provider and the shop client registered with it.
+type OIDCConfig struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	UsernameClaim string
+}
+
+// OIDCAuthenticator signs users in with the OpenID Connect authorization code flow with PKCE.
+// Provider metadata and keys are discovered from the issuer on first use.
+type OIDCAuthenticator struct {
+	cfg        OIDCConfig
+	httpClient *http.Client
+	clock      auth.Clock
+
+	mu            sync.Mutex
+	metadata      *oidcProviderMetadata
+	jwks          auth.JWKS
+	jwksFetchedAt time.Time
+}
+
+type oidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCAuthenticator creates the authenticator. UsernameClaim, the ID token claim the shop
+// username is taken from, defaults to preferred_username. Nil httpClient and clock mean the
+// defaults.
+func NewOIDCAuthenticator(cfg OIDCConfig, httpClient *http.Client, clock auth.Clock) (*OIDCAuthenticator, error) {
+	if cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("oidc issuer, client id and redirect url are required")
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = defaultOIDCUsernameClaim
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &OIDCAuthenticator{cfg: cfg, httpClient: httpClient, clock: clock}, nil
+}
+
+func (a *OIDCAuthenticator) Name() string {
+	return ProviderOIDC
+}
+
+// AuthCodeURL returns the authorization endpoint URL with the S256 PKCE challenge.
+func (a *OIDCAuthenticator) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := a.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {a.cfg.ClientID},
+		"redirect_uri":          {a.cfg.RedirectURL},
+		"scope":                 {"openid profile email"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Authenticate exchanges the authorization code for an ID token and verifies its signature,
+// issuer, audience, expiry and nonce.
+func (a *OIDCAuthenticator) Authenticate(ctx context.Context, credentials models.Credentials) (*models.Identity, error) {
+	if credentials.Code == "" {
+		return nil, models.ErrInvalidCredentials
+	}
+	metadata, err := a.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := a.exchangeCode(ctx, metadata, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := a.verifyIDToken(ctx, metadata, idToken, credentials.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	username, _ := claims[a.cfg.UsernameClaim].(string)
+	if subject == "" || username == "" {
+		return nil, fmt.Errorf("oidc id token has no sub or %s claim", a.cfg.UsernameClaim)
+	}
+	return &models.Identity{Provider: ProviderOIDC, Subject: subject, Username: username}, nil
+}
+
+func (a *OIDCAuthenticator) exchangeCode(ctx context.Context, metadata *oidcProviderMetadata, credentials models.Credentials) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {credentials.Code},
+		"redirect_uri":  {a.cfg.RedirectURL},
+		"code_verifier": {credentials.CodeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("error creating oidc token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.cfg.ClientID), url.QueryEscape(a.cfg.ClientSecret))
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting oidc token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("error decoding oidc token response: %v", err)
+	}
+	// invalid_grant means the code is wrong, expired, already used or issued for another verifier.
+	if resp.StatusCode == http.StatusBadRequest && body.Error == "invalid_grant" {
+		return "", models.ErrInvalidCredentials
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oidc token endpoint returned %d: %s", resp.StatusCode, body.Error)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("oidc token response has no id_token")
+	}
+	return body.IDToken, nil
+}
+
+func (a *OIDCAuthenticator) verifyIDToken(ctx context.Context, metadata *oidcProviderMetadata, idToken, nonce string) (jwt.MapClaims, error) {
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		jwks, err := a.keys(ctx, metadata, token)
+		if err != nil {
+			return nil, err
+		}
+		return jwks.KeyFunc(token)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid oidc id token: %v", err)
+	}
+
+	now := a.clock.Now().Unix()
+	if !claims.VerifyIssuer(metadata.Issuer, true) {
+		return nil, errors.New("invalid oidc id token: unexpected issuer")
+	}
+	if !claims.VerifyAudience(a.cfg.ClientID, true) {
+		return nil, errors.New("invalid oidc id token: unexpected audience")
+	}
+	if !claims.VerifyExpiresAt(now, true) {
+		return nil, errors.New("invalid oidc id token: token is expired")
+	}
+	tokenNonce, _ := claims["nonce"].(string)
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return nil, errors.New("invalid oidc id token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// keys returns the provider keys. They are refetched when the token names an unknown key,
+// at most once per oidcJWKSRefreshInterval, so a key rotation at the provider is picked up.
+func (a *OIDCAuthenticator) keys(ctx context.Context, metadata *oidcProviderMetadata, token *jwt.Token) (auth.JWKS, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	a.mu.Lock()
+	jwks, fetchedAt := a.jwks, a.jwksFetchedAt
+	a.mu.Unlock()
+
+	if hasKey(jwks, kid) || a.clock.Now().Sub(fetchedAt) < oidcJWKSRefreshInterval {
+		return jwks, nil
+	}
+
+	if err := a.getJSON(ctx, metadata.JWKSURI, &jwks); err != nil {
+		return auth.JWKS{}, fmt.Errorf("error fetching oidc keys: %v", err)
+	}
+	a.mu.Lock()
+	a.jwks, a.jwksFetchedAt = jwks, a.clock.Now()
+	a.mu.Unlock()
+	return jwks, nil
+}
+
+func (a *OIDCAuthenticator) discover(ctx context.Context) (*oidcProviderMetadata, error) {
+	a.mu.Lock()
+	metadata := a.metadata
+	a.mu.Unlock()
+	if metadata != nil {
+		return metadata, nil
+	}
+
+	metadata = &oidcProviderMetadata{}
+	if err := a.getJSON(ctx, strings.TrimSuffix(a.cfg.Issuer, "/")+"/.well-known/openid-configuration", metadata); err != nil {
+		return nil, fmt.Errorf("error discovering oidc provider: %v", err)
+	}
+	if metadata.Issuer != a.cfg.Issuer {
+		return nil, fmt.Errorf("oidc provider reports issuer %q instead of %q", metadata.Issuer, a.cfg.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc provider metadata is incomplete")
+	}
+
+	a.mu.Lock()
+	a.metadata = metadata
+	a.mu.Unlock()
+	return metadata, nil
+}
+
+func (a *OIDCAuthenticator) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func hasKey(jwks auth.JWKS, kid string) bool {
+	for _, key := range jwks.Keys {
+		if key.Kid == kid {
+			return true
+		}
+	}
+	return false
+}
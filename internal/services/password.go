@@ -0,0 +1,84 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argonTime    uint32 = 1
+	argonMemory  uint32 = 64 * 1024
+	argonThreads uint8  = 4
+	argonKeyLen  uint32 = 32
+	argonSaltLen        = 16
+
+	argonPrefix = "$argon2id$"
+)
+
+// HashPassword returns an argon2id hash of the password in the PHC string format:
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>. Every call uses a fresh random salt.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %v", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argonPrefix, argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword checks the password against a stored hash. Both argon2id hashes and legacy
+// unsalted SHA-256 hex digests are accepted. needsRehash reports that the hash was produced
+// by the legacy scheme or with outdated argon2 parameters and should be replaced.
+func VerifyPassword(password, hashedPassword string) (ok bool, needsRehash bool) {
+	if !strings.HasPrefix(hashedPassword, argonPrefix) {
+		return checkLegacyPasswordHash(password, hashedPassword), true
+	}
+
+	var version int
+	var memory, iterations uint32
+	var threads uint8
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return false, false
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return false, false
+	}
+
+	needsRehash = memory != argonMemory || iterations != argonTime || threads != argonThreads || uint32(len(key)) != argonKeyLen
+	return true, needsRehash
+}
+
+func checkLegacyPasswordHash(password, hashedPassword string) bool {
+	hash := sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(hash[:])), []byte(hashedPassword)) == 1
+}
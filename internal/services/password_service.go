@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const DefaultPasswordResetTokenTTL = time.Hour
+
+type PasswordServiceInterface interface {
+	ChangePassword(ctx context.Context, username, oldPassword, newPassword string, client models.ClientInfo) error
+	CreateResetToken(ctx context.Context, username, createdBy string) (*models.CreatedPasswordResetToken, error)
+	ResetPassword(ctx context.Context, resetToken, newPassword string) error
+}
+
+type PasswordService struct {
+	userRepository  repository.UserRepositoryInterface
+	resetRepository repository.PasswordResetRepositoryInterface
+	tokenService    TokenServiceInterface
+	loginGuard      LoginGuardInterface
+	resetTokenTTL   time.Duration
+	clock           auth.Clock
+}
+
+func NewPasswordService(userRepo repository.UserRepositoryInterface, resetRepo repository.PasswordResetRepositoryInterface, tokenService TokenServiceInterface, loginGuard LoginGuardInterface, resetTokenTTL time.Duration, clock auth.Clock) *PasswordService {
+	if resetTokenTTL <= 0 {
+		resetTokenTTL = DefaultPasswordResetTokenTTL
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &PasswordService{
+		userRepository:  userRepo,
+		resetRepository: resetRepo,
+		tokenService:    tokenService,
+		loginGuard:      loginGuard,
+		resetTokenTTL:   resetTokenTTL,
+		clock:           clock,
+	}
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the old one. Wrong old
+// passwords count as failed logins, so a stolen access token cannot be used to guess the password.
+func (s *PasswordService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string, client models.ClientInfo) error {
+	if err := s.loginGuard.Check(ctx, username, client); err != nil {
+		return err
+	}
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user.Password == externalAccountPassword {
+		return models.ErrNoLocalPassword
+	}
+	if ok, _ := VerifyPassword(oldPassword, user.Password); !ok {
+		s.loginGuard.RecordFailure(ctx, username, client)
+		return models.ErrInvalidCredentials
+	}
+	s.loginGuard.RecordSuccess(ctx, username)
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// CreateResetToken issues a single-use token that lets the user set a new password without the
+// old one. Issuing a token invalidates the tokens issued to the user before.
+func (s *PasswordService) CreateResetToken(ctx context.Context, username, createdBy string) (*models.CreatedPasswordResetToken, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Password == externalAccountPassword {
+		return nil, models.ErrNoLocalPassword
+	}
+
+	token, tokenHash, err := newPasswordResetToken()
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	resetToken := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.resetTokenTTL),
+	}
+	if err = s.resetRepository.CreatePasswordResetToken(ctx, resetToken); err != nil {
+		return nil, fmt.Errorf("error storing password reset token: %v", err)
+	}
+	log.Printf("password reset token for user %q issued by %q", username, createdBy)
+
+	return &models.CreatedPasswordResetToken{Token: token, ExpiresAt: resetToken.ExpiresAt}, nil
+}
+
+// ResetPassword sets a new password with a reset token and lifts a login lockout of the user.
+func (s *PasswordService) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	if resetToken == "" {
+		return models.ErrInvalidResetToken
+	}
+	token, err := s.resetRepository.ConsumePasswordResetToken(ctx, hashPasswordResetToken(resetToken), s.clock.Now())
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepository.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return models.ErrInvalidResetToken
+	}
+	if err = s.setPassword(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	if err = s.loginGuard.Unlock(ctx, user.Username); err != nil {
+		log.Printf("error unlocking user %q after password reset: %v", user.Username, err)
+	}
+	return nil
+}
+
+// setPassword stores the new password and ends every session of the user. The change time is
+// truncated to whole seconds, the precision of the token issue time, so tokens issued right
+// after the change stay valid.
+func (s *PasswordService) setPassword(ctx context.Context, user *models.User, password string) error {
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %v", err)
+	}
+	if err = s.userRepository.ChangeUserPassword(ctx, user.ID, hashedPassword, s.clock.Now().Truncate(time.Second)); err != nil {
+		return fmt.Errorf("error changing password: %v", err)
+	}
+	return s.tokenService.RevokeSessions(ctx, user.ID)
+}
+
+func (s *PasswordService) getUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func newPasswordResetToken() (token string, tokenHash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("error generating password reset token: %v", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashPasswordResetToken(token), nil
+}
+
+func hashPasswordResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
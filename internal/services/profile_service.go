@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+)
+
+// maxProfileFieldLen is the length of the text columns of profiles.
+const maxProfileFieldLen = 255
+
+type ProfileServiceInterface interface {
+	GetProfile(ctx context.Context, username string) (*models.Profile, error)
+	UpdateProfile(ctx context.Context, username string, update models.ProfileUpdate) (*models.Profile, error)
+	SetStatus(ctx context.Context, username, status string) error
+	SetDepartment(ctx context.Context, username, department string) error
+}
+
+type ProfileService struct {
+	userRepository repository.UserRepositoryInterface
+}
+
+func NewProfileService(userRepo repository.UserRepositoryInterface) *ProfileService {
+	return &ProfileService{userRepository: userRepo}
+}
+
+func (s *ProfileService) GetProfile(ctx context.Context, username string) (*models.Profile, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return &user.Profile, nil
+}
+
+// UpdateProfile applies the update to the profile of the user and returns the result. Invalid
+// values are refused with an error matching models.ErrInvalidProfile.
+func (s *ProfileService) UpdateProfile(ctx context.Context, username string, update models.ProfileUpdate) (*models.Profile, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := user.Profile
+	for _, field := range []struct {
+		name  string
+		value *string
+		dest  *string
+	}{
+		{"displayName", update.DisplayName, &profile.DisplayName},
+		{"email", update.Email, &profile.Email},
+		{"manager", update.Manager, &profile.Manager},
+		{"hireDate", update.HireDate, &profile.HireDate},
+	} {
+		if field.value == nil {
+			continue
+		}
+		value := strings.TrimSpace(*field.value)
+		if utf8.RuneCountInString(value) > maxProfileFieldLen {
+			return nil, fmt.Errorf("%w: %s is longer than %d characters", models.ErrInvalidProfile, field.name, maxProfileFieldLen)
+		}
+		*field.dest = value
+	}
+
+	if update.Email != nil && profile.Email != "" {
+		address, err := mail.ParseAddress(profile.Email)
+		if err != nil || address.Address != profile.Email {
+			return nil, fmt.Errorf("%w: email is not a valid address", models.ErrInvalidProfile)
+		}
+	}
+	if update.HireDate != nil && profile.HireDate != "" {
+		if _, err := time.Parse(time.DateOnly, profile.HireDate); err != nil {
+			return nil, fmt.Errorf("%w: hireDate must be formatted as YYYY-MM-DD", models.ErrInvalidProfile)
+		}
+	}
+	if update.Manager != nil && profile.Manager != "" {
+		if profile.Manager == user.Username {
+			return nil, fmt.Errorf("%w: an employee cannot be their own manager", models.ErrInvalidProfile)
+		}
+		manager, err := s.userRepository.GetUserByUsername(ctx, profile.Manager)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching manager: %v", err)
+		}
+		if manager == nil {
+			return nil, fmt.Errorf("%w: manager %s not found", models.ErrInvalidProfile, profile.Manager)
+		}
+	}
+
+	if err = s.userRepository.UpdateUserProfile(ctx, user.ID, profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// SetStatus suspends, terminates or reactivates the user. Requests of inactive users are
+// refused from then on, but their sessions are kept so that reactivation restores them.
+func (s *ProfileService) SetStatus(ctx context.Context, username, status string) error {
+	if !models.IsValidUserStatus(status) {
+		return fmt.Errorf("%w: %q", models.ErrInvalidStatus, status)
+	}
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.userRepository.UpdateUserStatus(ctx, user.ID, status)
+}
+
+// SetDepartment moves the user to the department, or out of any department when it is empty.
+func (s *ProfileService) SetDepartment(ctx context.Context, username, department string) error {
+	department = strings.TrimSpace(department)
+	if utf8.RuneCountInString(department) > maxProfileFieldLen {
+		return fmt.Errorf("%w: department is longer than %d characters", models.ErrInvalidProfile, maxProfileFieldLen)
+	}
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.userRepository.UpdateUserDepartment(ctx, user.ID, department)
+}
+
+func (s *ProfileService) getUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+	return user, nil
+}
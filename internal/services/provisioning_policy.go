@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ProvisioningMode string
+
+const (
+	ProvisionAlways    ProvisioningMode = "always"
+	ProvisionNever     ProvisioningMode = "never"
+	ProvisionAllowList ProvisioningMode = "allowlist"
+)
+
+// ProvisioningPolicy decides whether the legacy /api/auth endpoint and /api/register may
+// create an account for a username it has never seen before.
+type ProvisioningPolicy struct {
+	mode      ProvisioningMode
+	allowList map[string]struct{}
+	pattern   *regexp.Regexp
+}
+
+// NewProvisioningPolicy builds a policy from its configuration. In allowlist mode a username
+// is accepted when it is listed explicitly or matches the whole pattern, if one is set.
+func NewProvisioningPolicy(mode string, allowList []string, pattern string) (*ProvisioningPolicy, error) {
+	policy := &ProvisioningPolicy{
+		mode:      ProvisioningMode(strings.ToLower(strings.TrimSpace(mode))),
+		allowList: make(map[string]struct{}, len(allowList)),
+	}
+	if policy.mode == "" {
+		policy.mode = ProvisionAlways
+	}
+
+	switch policy.mode {
+	case ProvisionAlways, ProvisionNever:
+	case ProvisionAllowList:
+		for _, username := range allowList {
+			username = strings.TrimSpace(username)
+			if username != "" {
+				policy.allowList[username] = struct{}{}
+			}
+		}
+		if pattern != "" {
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid provisioning pattern: %v", err)
+			}
+			policy.pattern = re
+		}
+	default:
+		return nil, fmt.Errorf("unknown provisioning mode %q, must be always, never or allowlist", mode)
+	}
+
+	return policy, nil
+}
+
+func (p *ProvisioningPolicy) Allows(username string) bool {
+	switch p.mode {
+	case ProvisionAlways:
+		return true
+	case ProvisionAllowList:
+		if _, ok := p.allowList[username]; ok {
+			return true
+		}
+		return p.pattern != nil && p.pattern.MatchString(username)
+	default:
+		return false
+	}
+}
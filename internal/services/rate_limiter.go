@@ -0,0 +1,58 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+// RateLimiter counts requests per key in fixed one-minute windows. Counters live in memory,
+// so every instance of the service enforces the limit on its own.
+type RateLimiter struct {
+	mu      sync.Mutex
+	clock   auth.Clock
+	windows map[int64]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+const rateLimitWindow = time.Minute
+
+func NewRateLimiter(clock auth.Clock) *RateLimiter {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &RateLimiter{clock: clock, windows: make(map[int64]*rateWindow)}
+}
+
+// Allow records a request for the key and reports whether it fits into limit requests per
+// minute. When it does not, retryAfter is the time left until the current window ends.
+func (l *RateLimiter) Allow(key int64, limit int) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	window := l.windows[key]
+	if window == nil || now.Sub(window.start) >= rateLimitWindow {
+		l.evictExpired(now)
+		window = &rateWindow{start: now.Truncate(rateLimitWindow)}
+		l.windows[key] = window
+	}
+	if window.count >= limit {
+		return false, window.start.Add(rateLimitWindow).Sub(now)
+	}
+	window.count++
+	return true, 0
+}
+
+func (l *RateLimiter) evictExpired(now time.Time) {
+	for key, window := range l.windows {
+		if now.Sub(window.start) >= rateLimitWindow {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/avito-shop-service/pkg/cron"
+)
+
+const (
+	DefaultScheduledTransferInterval = time.Minute
+	scheduledTransferBatchSize       = 100
+	scheduledTransferRunsLimit       = 50
+)
+
+type ScheduledTransferServiceInterface interface {
+	CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) error
+	GetScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error)
+	GetScheduledTransfer(ctx context.Context, owner string, id int64) (*models.ScheduledTransfer, error)
+	UpdateScheduledTransfer(ctx context.Context, owner string, id int64, update models.ScheduledTransferUpdate) (*models.ScheduledTransfer, error)
+	DeleteScheduledTransfer(ctx context.Context, owner string, id int64) error
+	GetScheduledTransferRuns(ctx context.Context, owner string, id int64) ([]models.ScheduledTransferRun, error)
+}
+
+type ScheduledTransferService struct {
+	repository         repository.ScheduledTransferRepositoryInterface
+	transactionService TransactionServiceInterface
+	clock              auth.Clock
+}
+
+func NewScheduledTransferService(repo repository.ScheduledTransferRepositoryInterface, transactionService TransactionServiceInterface, clock auth.Clock) *ScheduledTransferService {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &ScheduledTransferService{repository: repo, transactionService: transactionService, clock: clock}
+}
+
+// CreateScheduledTransfer schedules transfer.Amount coins from transfer.Owner to transfer.ToUser
+// on transfer.Schedule, starting with the first activation from now.
+func (s *ScheduledTransferService) CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) error {
+	schedule, err := s.validate(transfer)
+	if err != nil {
+		return err
+	}
+	transfer.Active = true
+	transfer.NextRunAt = schedule.Next(s.clock.Now().UTC())
+	return s.repository.CreateScheduledTransfer(ctx, transfer)
+}
+
+func (s *ScheduledTransferService) GetScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error) {
+	return s.repository.GetScheduledTransfers(ctx, owner)
+}
+
+func (s *ScheduledTransferService) GetScheduledTransfer(ctx context.Context, owner string, id int64) (*models.ScheduledTransfer, error) {
+	transfer, err := s.repository.GetScheduledTransfer(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, models.ErrScheduledTransferNotFound
+	}
+	return transfer, nil
+}
+
+// UpdateScheduledTransfer changes a scheduled transfer of the owner. A new schedule, or resuming
+// a paused transfer, starts again from the first activation from now; runs missed while paused
+// are not made up for.
+func (s *ScheduledTransferService) UpdateScheduledTransfer(ctx context.Context, owner string, id int64, update models.ScheduledTransferUpdate) (*models.ScheduledTransfer, error) {
+	transfer, err := s.GetScheduledTransfer(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reschedule := false
+	if update.Amount != nil {
+		transfer.Amount = *update.Amount
+	}
+	if update.Message != nil {
+		transfer.Message = *update.Message
+	}
+	if update.Category != nil {
+		transfer.Category = *update.Category
+	}
+	if update.Schedule != nil && *update.Schedule != transfer.Schedule {
+		transfer.Schedule = *update.Schedule
+		reschedule = true
+	}
+	if update.Active != nil && *update.Active != transfer.Active {
+		transfer.Active = *update.Active
+		reschedule = reschedule || transfer.Active
+	}
+
+	schedule, err := s.validate(transfer)
+	if err != nil {
+		return nil, err
+	}
+	if reschedule {
+		transfer.NextRunAt = schedule.Next(s.clock.Now().UTC())
+	}
+	if err = s.repository.UpdateScheduledTransfer(ctx, transfer, reschedule); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (s *ScheduledTransferService) DeleteScheduledTransfer(ctx context.Context, owner string, id int64) error {
+	deleted, err := s.repository.DeleteScheduledTransfer(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return models.ErrScheduledTransferNotFound
+	}
+	return nil
+}
+
+// GetScheduledTransferRuns returns the latest runs of a scheduled transfer of the owner, the
+// latest first.
+func (s *ScheduledTransferService) GetScheduledTransferRuns(ctx context.Context, owner string, id int64) ([]models.ScheduledTransferRun, error) {
+	if _, err := s.GetScheduledTransfer(ctx, owner, id); err != nil {
+		return nil, err
+	}
+	return s.repository.GetScheduledTransferRuns(ctx, id, scheduledTransferRunsLimit)
+}
+
+// RunDue sends the scheduled transfers that are due. Each transfer goes through
+// TransactionService.CreateTransaction like one sent by its owner. The run is claimed and
+// recorded as pending before the transfer is sent and gets its outcome afterwards; a transfer that fails, for example for lack of coins, is not retried until its next
+// activation. A transfer overdue by several activations is sent once.
+//
+// An error with one transfer is logged and does not hold up the others of the batch; the errors
+// are returned together after the batch, leaving the transfers that failed for the next tick.
+func (s *ScheduledTransferService) RunDue(ctx context.Context) error {
+	for {
+		// next_run_at holds UTC without a zone, like the activations of the schedules.
+		now := s.clock.Now().UTC()
+		due, err := s.repository.GetDueScheduledTransfers(ctx, now, scheduledTransferBatchSize)
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for _, transfer := range due {
+			if err = s.run(ctx, transfer, now); err != nil {
+				log.Printf("error running scheduled transfer %d: %v", transfer.ID, err)
+				errs = append(errs, fmt.Errorf("scheduled transfer %d: %w", transfer.ID, err))
+			}
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+		if len(due) < scheduledTransferBatchSize {
+			return nil
+		}
+	}
+}
+
+// Run sends due scheduled transfers every interval until the context is cancelled.
+func (s *ScheduledTransferService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultScheduledTransferInterval
+	}
+	RunPeriodically(ctx, "scheduled transfers", interval, s.RunDue)
+}
+
+func (s *ScheduledTransferService) run(ctx context.Context, transfer models.ScheduledTransfer, now time.Time) error {
+	next := time.Time{}
+	schedule, err := cron.Parse(transfer.Schedule)
+	if err == nil {
+		next = schedule.Next(now)
+	}
+	if next.IsZero() {
+		// Schedules are validated when saved, so this one was stored by other means; pause it
+		// rather than retry it on every tick.
+		log.Printf("pausing scheduled transfer %d with unusable schedule %q", transfer.ID, transfer.Schedule)
+		transfer.Active = false
+		return s.repository.UpdateScheduledTransfer(ctx, &transfer, false)
+	}
+
+	run := &models.ScheduledTransferRun{
+		ScheduledTransferID: transfer.ID,
+		ScheduledFor:        transfer.NextRunAt,
+		Status:              models.ScheduledRunPending,
+	}
+	claimed, err := s.repository.ClaimScheduledTransferRun(ctx, run, next)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		// Another executor took this run, or the owner changed the transfer meanwhile.
+		return nil
+	}
+
+	// The run is recorded as pending before the coins move, so a crash in between leaves a
+	// visible pending run instead of a transfer without one.
+	run.Status = models.ScheduledRunSucceeded
+	err = s.transactionService.CreateTransaction(ctx, &models.CoinTransaction{
+		FromUser:        transfer.Owner,
+		UserID:          transfer.OwnerID,
+		CounterpartUser: transfer.ToUser,
+		Amount:          transfer.Amount,
+		TransactionType: "send",
+		Message:         transfer.Message,
+		Category:        transfer.Category,
+		CreatedAt:       now,
+	})
+	if err != nil {
+		run.Status = models.ScheduledRunFailed
+		run.Error = err.Error()
+	}
+	// The outcome is stored even if the job is being stopped meanwhile.
+	return s.repository.FinishScheduledTransferRun(context.WithoutCancel(ctx), run)
+}
+
+func (s *ScheduledTransferService) validate(transfer *models.ScheduledTransfer) (*cron.Schedule, error) {
+	if transfer.ToUser == "" {
+		return nil, fmt.Errorf("%w: toUser is required", models.ErrInvalidScheduledTransfer)
+	}
+	if transfer.ToUser == transfer.Owner {
+		return nil, fmt.Errorf("%w: cannot schedule a transfer to yourself", models.ErrInvalidScheduledTransfer)
+	}
+	if transfer.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", models.ErrInvalidScheduledTransfer)
+	}
+	if utf8.RuneCountInString(transfer.Message) > maxTransferMessageLen {
+		return nil, fmt.Errorf("%w: message must be at most %d characters", models.ErrInvalidScheduledTransfer, maxTransferMessageLen)
+	}
+	if transfer.Category != "" && !contains(s.transactionService.Categories(), transfer.Category) {
+		return nil, fmt.Errorf("%w: unknown kudos category %q", models.ErrInvalidScheduledTransfer, transfer.Category)
+	}
+	schedule, err := cron.Parse(transfer.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidScheduledTransfer, err)
+	}
+	return schedule, nil
+}
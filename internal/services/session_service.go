@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const (
+	DefaultLoginEventsLimit = 100
+	MaxLoginEventsLimit     = 1000
+)
+
+// LoginRecorder keeps the audit trail of sign-in attempts.
+type LoginRecorder interface {
+	RecordLogin(ctx context.Context, event models.LoginEvent)
+}
+
+type SessionServiceInterface interface {
+	LoginRecorder
+	GetSessions(ctx context.Context, username string, currentSessionID int64) ([]models.Session, error)
+	RevokeSession(ctx context.Context, username string, sessionID int64) error
+	GetLoginEvents(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error)
+}
+
+type SessionService struct {
+	tokenRepository      repository.TokenRepositoryInterface
+	loginEventRepository repository.LoginEventRepositoryInterface
+	userRepository       repository.UserRepositoryInterface
+	clock                auth.Clock
+}
+
+func NewSessionService(tokenRepo repository.TokenRepositoryInterface, loginEventRepo repository.LoginEventRepositoryInterface, userRepo repository.UserRepositoryInterface, clock auth.Clock) *SessionService {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &SessionService{
+		tokenRepository:      tokenRepo,
+		loginEventRepository: loginEventRepo,
+		userRepository:       userRepo,
+		clock:                clock,
+	}
+}
+
+// RecordLogin stores a sign-in attempt. A failure to store it must not affect the login,
+// so it is only logged.
+func (s *SessionService) RecordLogin(ctx context.Context, event models.LoginEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = s.clock.Now().UTC()
+	}
+	if err := s.loginEventRepository.CreateLoginEvent(ctx, &event); err != nil {
+		log.Printf("error recording login of %q from %s: %v", event.Username, event.IP, err)
+	}
+}
+
+// GetSessions returns the active sessions of the user and marks the one with currentSessionID.
+func (s *SessionService) GetSessions(ctx context.Context, username string, currentSessionID int64) ([]models.Session, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := s.tokenRepository.GetActiveSessions(ctx, user.ID, s.clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sessions: %v", err)
+	}
+	for i := range sessions {
+		sessions[i].Current = currentSessionID != 0 && sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (s *SessionService) RevokeSession(ctx context.Context, username string, sessionID int64) error {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.tokenRepository.RevokeSession(ctx, user.ID, sessionID)
+}
+
+// GetLoginEvents returns the audit trail matching the filter, newest first. The limit defaults to
+// DefaultLoginEventsLimit and is capped at MaxLoginEventsLimit.
+func (s *SessionService) GetLoginEvents(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultLoginEventsLimit
+	}
+	if filter.Limit > MaxLoginEventsLimit {
+		filter.Limit = MaxLoginEventsLimit
+	}
+	events, err := s.loginEventRepository.GetLoginEvents(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching login events: %v", err)
+	}
+	return events, nil
+}
+
+func (s *SessionService) getUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+type TokenServiceInterface interface {
+	IssueTokens(ctx context.Context, user *models.User, client models.ClientInfo) (*models.AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.AuthTokens, error)
+	Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error
+	ValidateAccessToken(ctx context.Context, tokenStr string) (*auth.Claims, error)
+	RevokeSessions(ctx context.Context, userID int64) error
+}
+
+type TokenService struct {
+	repository      repository.TokenRepositoryInterface
+	userRepository  repository.UserRepositoryInterface
+	tokenManager    auth.TokenManager
+	refreshTokenTTL time.Duration
+}
+
+func NewTokenService(repo repository.TokenRepositoryInterface, userRepo repository.UserRepositoryInterface, tokenManager auth.TokenManager, refreshTokenTTL time.Duration) *TokenService {
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = DefaultRefreshTokenTTL
+	}
+	return &TokenService{
+		repository:      repo,
+		userRepository:  userRepo,
+		tokenManager:    tokenManager,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// IssueTokens starts a session of the user on the client and returns a short-lived access token
+// together with the first refresh token of the session.
+func (s *TokenService) IssueTokens(ctx context.Context, user *models.User, client models.ClientInfo) (*models.AuthTokens, error) {
+	refreshToken, refreshTokenHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := newSession(user.ID, client, now, now.Add(s.refreshTokenTTL))
+	err = s.repository.CreateSession(ctx, session, &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error storing refresh token: %v", err)
+	}
+
+	return s.newAuthTokens(user, session.ID, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new token pair. Every refresh token can be used once;
+// presenting an already rotated token revokes all refresh tokens of its owner, since it means
+// the token has leaked. Tokens of a revoked session are refused without that alarm, tokens of
+// a suspended or terminated user with a *models.AccountInactiveError.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.AuthTokens, error) {
+	stored, err := s.repository.GetRefreshTokenByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching refresh token: %v", err)
+	}
+	if stored == nil || stored.SessionRevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, models.ErrInvalidRefreshToken
+	}
+	if stored.RevokedAt != nil {
+		s.revokeAllRefreshTokens(ctx, stored.UserID)
+		return nil, models.ErrInvalidRefreshToken
+	}
+
+	// Roles may have changed since the refresh token was issued, so the user is reloaded.
+	user, err := s.userRepository.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return nil, models.ErrInvalidRefreshToken
+	}
+	if !user.IsActive() {
+		return nil, &models.AccountInactiveError{Status: user.Status}
+	}
+
+	newToken, newTokenHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := newSession(stored.UserID, client, now, now.Add(s.refreshTokenTTL))
+	session.ID = stored.SessionID
+	err = s.repository.RotateRefreshToken(ctx, stored.ID, &models.RefreshToken{
+		UserID:    stored.UserID,
+		TokenHash: newTokenHash,
+		ExpiresAt: session.ExpiresAt,
+	}, session)
+	if errors.Is(err, models.ErrInvalidRefreshToken) {
+		s.revokeAllRefreshTokens(ctx, stored.UserID)
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error rotating refresh token: %v", err)
+	}
+
+	return s.newAuthTokens(user, session.ID, newToken)
+}
+
+// Logout ends the session of the access token. The access token is also put on the deny-list
+// and the refresh token, if one is given, is revoked, which covers tokens issued before sessions
+// were introduced.
+func (s *TokenService) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	if claims.SessionID != 0 {
+		user, err := s.userRepository.GetUserByUsername(ctx, claims.EmployeeUsername)
+		if err != nil {
+			return fmt.Errorf("error fetching user: %v", err)
+		}
+		if user != nil {
+			err = s.repository.RevokeSession(ctx, user.ID, claims.SessionID)
+			if err != nil && !errors.Is(err, models.ErrSessionNotFound) {
+				return fmt.Errorf("error revoking session: %v", err)
+			}
+		}
+	}
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.repository.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return fmt.Errorf("error revoking access token: %v", err)
+		}
+	}
+	if refreshToken != "" {
+		if err := s.repository.RevokeRefreshToken(ctx, hashRefreshToken(refreshToken)); err != nil {
+			return fmt.Errorf("error revoking refresh token: %v", err)
+		}
+	}
+	return nil
+}
+
+// ValidateAccessToken parses the token and rejects it if it has been revoked, was issued
+// before the last password change of its owner or the owner is no longer active.
+func (s *TokenService) ValidateAccessToken(ctx context.Context, tokenStr string) (*auth.Claims, error) {
+	claims, err := s.tokenManager.ParseToken(tokenStr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidToken, err)
+	}
+	if claims.ID != "" || claims.SessionID != 0 {
+		revoked, err := s.repository.IsAccessTokenRevoked(ctx, claims.ID, claims.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("error checking token revocation: %v", err)
+		}
+		if revoked {
+			return nil, models.ErrTokenRevoked
+		}
+	}
+
+	// Changing the password ends every session, including access tokens issued before it.
+	user, err := s.userRepository.GetUserByUsername(ctx, claims.EmployeeUsername)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return nil, models.ErrInvalidToken
+	}
+	if !user.IsActive() {
+		return nil, &models.AccountInactiveError{Status: user.Status}
+	}
+	if user.PasswordChangedAt != nil && (claims.IssuedAt == nil || claims.IssuedAt.Before(*user.PasswordChangedAt)) {
+		return nil, models.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// RevokeSessions ends every session of the user together with its refresh and access tokens.
+func (s *TokenService) RevokeSessions(ctx context.Context, userID int64) error {
+	if err := s.repository.RevokeUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("error revoking sessions: %v", err)
+	}
+	return nil
+}
+
+func (s *TokenService) newAuthTokens(user *models.User, sessionID int64, refreshToken string) (*models.AuthTokens, error) {
+	accessToken, err := s.tokenManager.IssueToken(auth.Claims{
+		EmployeeUsername: user.Username,
+		Roles:            user.Roles,
+		Scopes:           auth.ResolveScopes(user.Roles, user.Scopes),
+		SessionID:        sessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error with generating token: %v", err)
+	}
+	return &models.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.tokenManager.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+func (s *TokenService) revokeAllRefreshTokens(ctx context.Context, userID int64) {
+	if err := s.repository.RevokeUserSessions(ctx, userID); err != nil {
+		log.Printf("error revoking sessions after refresh token reuse for user %d: %v", userID, err)
+	}
+}
+
+func newSession(userID int64, client models.ClientInfo, now, expiresAt time.Time) *models.Session {
+	return &models.Session{
+		UserID:     userID,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}
+}
+
+func newRefreshToken() (token string, tokenHash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("error generating refresh token: %v", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -2,22 +2,53 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/avito-shop-service/internal/models"
 	"github.com/avito-shop-service/internal/repository"
 )
 
+const (
+	DefaultTransfersLimit = 100
+	MaxTransfersLimit     = 1000
+	maxTransferMessageLen = 500
+	MaxBatchTransfers     = 100
+
+	DefaultTransactionsPageSize = 50
+	MaxTransactionsPageSize     = 500
+)
+
+// DefaultKudosCategories are offered when no categories are configured.
+var DefaultKudosCategories = []string{"helped with on-call", "great review", "mentoring", "teamwork", "going the extra mile"}
+
 type TransactionServiceInterface interface {
 	GetTransactionsByUserId(ctx context.Context, userID int64) ([]models.CoinTransaction, error)
 	CreateTransaction(ctx context.Context, transaction *models.CoinTransaction) error
+	CreateTransactions(ctx context.Context, transactions []models.CoinTransaction) error
+	GetTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error)
+	GetHistory(ctx context.Context, filter models.TransactionFilter, cursor string) (*models.TransactionPage, error)
+	Categories() []string
 }
 
 type TransactionService struct {
 	repository repository.TransactionRepositoryInterface
+	categories []string
+	limits     TransferLimitServiceInterface
 }
 
-func NewTransactionService(repo repository.TransactionRepositoryInterface) *TransactionService {
-	return &TransactionService{repository: repo}
+// NewTransactionService creates the service with the kudos categories, DefaultKudosCategories
+// when there are none. Transfers are checked against the limits unless they are nil; the
+// repository enforces them while it holds the lock on the balance of the sender.
+func NewTransactionService(repo repository.TransactionRepositoryInterface, categories []string, limits TransferLimitServiceInterface) *TransactionService {
+	if len(categories) == 0 {
+		categories = DefaultKudosCategories
+	}
+	return &TransactionService{repository: repo, categories: categories, limits: limits}
 }
 
 func (s *TransactionService) GetTransactionsByUserId(ctx context.Context, userID int64) ([]models.CoinTransaction, error) {
@@ -38,6 +69,166 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, transaction
 	if transaction.TransactionType != "received" && transaction.TransactionType != "send" {
 		return fmt.Errorf("wrong transaction type, must be send or received")
 	}
+	if transaction.CounterpartUser == transaction.FromUser {
+		return models.ErrSelfTransfer
+	}
+	if err := s.validateNote(transaction); err != nil {
+		return err
+	}
+	limits, err := s.limitCheck(ctx, transaction.UserID)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.CreateTransaction(ctx, transaction, limits)
+}
+
+// CreateTransactions sends the transfers of a batch, each from transactions[0].UserID to a
+// distinct recipient, all at once or not at all. A transfer that fails fails the batch with a
+// *models.TransferBatchError naming it. The limits count the earlier transfers of the batch as
+// already sent.
+func (s *TransactionService) CreateTransactions(ctx context.Context, transactions []models.CoinTransaction) error {
+	if len(transactions) == 0 || len(transactions) > MaxBatchTransfers {
+		return fmt.Errorf("%w: a batch must have 1 to %d transfers", models.ErrInvalidBatchTransfer, MaxBatchTransfers)
+	}
+
+	recipients := make(map[string]bool, len(transactions))
+	for i := range transactions {
+		transaction := &transactions[i]
+		var err error
+		switch {
+		case transaction.CounterpartUser == "" || transaction.Amount <= 0:
+			err = fmt.Errorf("%w: toUser and a positive amount are required", models.ErrInvalidBatchTransfer)
+		case recipients[transaction.CounterpartUser]:
+			err = fmt.Errorf("%w: recipient appears more than once", models.ErrInvalidBatchTransfer)
+		case transaction.UserID != transactions[0].UserID:
+			err = fmt.Errorf("%w: all transfers must be sent by the same user", models.ErrInvalidBatchTransfer)
+		case transaction.CounterpartUser == transaction.FromUser:
+			err = models.ErrSelfTransfer
+		default:
+			err = s.validateNote(transaction)
+		}
+		if err != nil {
+			return &models.TransferBatchError{Index: i, ToUser: transaction.CounterpartUser, Err: err}
+		}
+		recipients[transaction.CounterpartUser] = true
+	}
+	limits, err := s.limitCheck(ctx, transactions[0].UserID)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.CreateTransactions(ctx, transactions, limits)
+}
+
+// limitCheck returns the transfer limits of the sender for the repository to enforce, or nil
+// when transfers are not limited.
+func (s *TransactionService) limitCheck(ctx context.Context, userID int64) (*models.TransferLimitCheck, error) {
+	if s.limits == nil {
+		return nil, nil
+	}
+	return s.limits.LimitCheck(ctx, userID)
+}
+
+// validateNote checks the message and the kudos category of a transfer.
+func (s *TransactionService) validateNote(transaction *models.CoinTransaction) error {
+	if utf8.RuneCountInString(transaction.Message) > maxTransferMessageLen {
+		return fmt.Errorf("%w: at most %d characters", models.ErrMessageTooLong, maxTransferMessageLen)
+	}
+	if transaction.Category != "" && !contains(s.categories, transaction.Category) {
+		return fmt.Errorf("%w: %q", models.ErrUnknownCategory, transaction.Category)
+	}
+	return nil
+}
+
+// GetTransfers returns the transfers matching the filter, newest first. The limit defaults to
+// DefaultTransfersLimit and is capped at MaxTransfersLimit.
+func (s *TransactionService) GetTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultTransfersLimit
+	}
+	if filter.Limit > MaxTransfersLimit {
+		filter.Limit = MaxTransfersLimit
+	}
+	return s.repository.GetTransfers(ctx, filter)
+}
+
+// GetHistory returns a page of the history of filter.UserID. The page starts after the cursor
+// returned with the previous page, or at the beginning when the cursor is empty. The page size
+// defaults to DefaultTransactionsPageSize and is capped at MaxTransactionsPageSize.
+func (s *TransactionService) GetHistory(ctx context.Context, filter models.TransactionFilter, cursor string) (*models.TransactionPage, error) {
+	if filter.Direction != "" && filter.Direction != models.DirectionSent && filter.Direction != models.DirectionReceived {
+		return nil, fmt.Errorf("%w: direction must be %s or %s", models.ErrInvalidHistoryQuery, models.DirectionSent, models.DirectionReceived)
+	}
+	if filter.Type != "" && !contains(historyTypes, filter.Type) {
+		return nil, fmt.Errorf("%w: type must be one of %s", models.ErrInvalidHistoryQuery, strings.Join(historyTypes, ", "))
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Since.Before(*filter.Until) {
+		return nil, fmt.Errorf("%w: since must be before until", models.ErrInvalidHistoryQuery)
+	}
+	if cursor != "" {
+		after, err := decodeHistoryCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultTransactionsPageSize
+	}
+	if filter.Limit > MaxTransactionsPageSize {
+		filter.Limit = MaxTransactionsPageSize
+	}
+
+	// One entry more than requested tells whether there is a next page.
+	pageSize := filter.Limit
+	filter.Limit++
+	history, err := s.repository.GetHistory(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.TransactionPage{Transactions: history}
+	if len(history) > pageSize {
+		page.Transactions = history[:pageSize]
+		last := page.Transactions[pageSize-1]
+		page.NextCursor = encodeHistoryCursor(models.HistoryCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if page.Transactions == nil {
+		page.Transactions = []models.HistoryEntry{}
+	}
+	return page, nil
+}
+
+var historyTypes = []string{models.EntryKindTransfer, models.EntryKindPurchase, models.EntryKindGrant, models.EntryKindRefund, models.EntryKindAdjustment, models.EntryKindExpiry}
+
+// encodeHistoryCursor makes an opaque cursor of the position; clients only pass it back.
+func encodeHistoryCursor(cursor models.HistoryCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%d", cursor.CreatedAt.UnixNano(), cursor.ID)))
+}
+
+func decodeHistoryCursor(value string) (*models.HistoryCursor, error) {
+	invalid := fmt.Errorf("%w: malformed cursor", models.ErrInvalidHistoryQuery)
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, invalid
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	cursor := &models.HistoryCursor{CreatedAt: time.Unix(0, unixNano).UTC()}
+	if cursor.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, invalid
+	}
+	return cursor, nil
+}
 
-	return s.repository.CreateTransaction(ctx, transaction)
+// Categories returns the kudos categories a transfer may be tagged with.
+func (s *TransactionService) Categories() []string {
+	return s.categories
 }
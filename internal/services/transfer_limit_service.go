@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const (
+	DefaultTransferRecipientPeriod = 7 * 24 * time.Hour
+	maxLimitExceptionReasonLen     = 255
+)
+
+// TransferLimitPolicy configures the transfer limits that apply to everyone without an
+// exception. Zero limits are no limits; RecipientPeriod is the rolling window of RecipientLimit.
+type TransferLimitPolicy struct {
+	MaxPerTransfer  int
+	DailyLimit      int
+	MonthlyLimit    int
+	RecipientLimit  int
+	RecipientPeriod time.Duration
+}
+
+type TransferLimitServiceInterface interface {
+	LimitCheck(ctx context.Context, userID int64) (*models.TransferLimitCheck, error)
+	GetLimits(ctx context.Context, username string) (*models.UserTransferLimits, error)
+	SetException(ctx context.Context, exception *models.TransferLimitException) error
+	DeleteException(ctx context.Context, username string) error
+}
+
+type TransferLimitService struct {
+	repository      repository.TransferLimitRepositoryInterface
+	userRepository  repository.UserRepositoryInterface
+	limits          models.TransferLimits
+	recipientPeriod time.Duration
+	clock           auth.Clock
+}
+
+// NewTransferLimitService creates the limit checks of the policy. A zero RecipientPeriod falls
+// back to DefaultTransferRecipientPeriod; a nil clock means the system clock.
+func NewTransferLimitService(repo repository.TransferLimitRepositoryInterface, userRepo repository.UserRepositoryInterface, policy TransferLimitPolicy, clock auth.Clock) *TransferLimitService {
+	if policy.RecipientPeriod <= 0 {
+		policy.RecipientPeriod = DefaultTransferRecipientPeriod
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &TransferLimitService{
+		repository:     repo,
+		userRepository: userRepo,
+		limits: models.TransferLimits{
+			MaxPerTransfer: policy.MaxPerTransfer,
+			DailyLimit:     policy.DailyLimit,
+			MonthlyLimit:   policy.MonthlyLimit,
+			RecipientLimit: policy.RecipientLimit,
+		},
+		recipientPeriod: policy.RecipientPeriod,
+		clock:           clock,
+	}
+}
+
+// LimitCheck returns the limits that apply to the sender now, to be evaluated by the repository
+// in the transaction that posts the transfers, or nil if the sender has no limits. Days and
+// months are calendar periods in UTC.
+func (s *TransferLimitService) LimitCheck(ctx context.Context, userID int64) (*models.TransferLimitCheck, error) {
+	now := s.clock.Now().UTC()
+	limits, _, err := s.limitsOf(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if limits == (models.TransferLimits{}) {
+		return nil, nil
+	}
+	return &models.TransferLimitCheck{
+		Limits:         limits,
+		DayStart:       time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		MonthStart:     time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		RecipientStart: now.Add(-s.recipientPeriod),
+	}, nil
+}
+
+// GetLimits returns the limits that apply to the user, together with the configured ones and
+// the exception of the user if there is one.
+func (s *TransferLimitService) GetLimits(ctx context.Context, username string) (*models.UserTransferLimits, error) {
+	user, err := s.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, models.ErrUserNotFound
+	}
+
+	limits, exception, err := s.limitsOf(ctx, user.ID, s.clock.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserTransferLimits{Username: username, Limits: limits, Defaults: s.limits, Exception: exception}, nil
+}
+
+// SetException grants the user named by exception.Username an exception to the limits,
+// replacing the previous one.
+func (s *TransferLimitService) SetException(ctx context.Context, exception *models.TransferLimitException) error {
+	exception.Reason = strings.TrimSpace(exception.Reason)
+	if exception.Reason == "" || len(exception.Reason) > maxLimitExceptionReasonLen {
+		return fmt.Errorf("%w: reason must be 1 to %d characters long", models.ErrInvalidLimitException, maxLimitExceptionReasonLen)
+	}
+	limits := []*int{exception.MaxPerTransfer, exception.DailyLimit, exception.MonthlyLimit, exception.RecipientLimit}
+	set := false
+	for _, limit := range limits {
+		if limit != nil && *limit < 0 {
+			return fmt.Errorf("%w: limits must not be negative", models.ErrInvalidLimitException)
+		}
+		set = set || limit != nil
+	}
+	if !set {
+		return fmt.Errorf("%w: at least one limit is required", models.ErrInvalidLimitException)
+	}
+	if exception.ExpiresAt != nil && !exception.ExpiresAt.After(s.clock.Now()) {
+		return fmt.Errorf("%w: expiresAt must be in the future", models.ErrInvalidLimitException)
+	}
+
+	user, err := s.userRepository.GetUserByUsername(ctx, exception.Username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return models.ErrUserNotFound
+	}
+	exception.UserID = user.ID
+	if exception.ExpiresAt != nil {
+		expiresAt := exception.ExpiresAt.UTC()
+		exception.ExpiresAt = &expiresAt
+	}
+	return s.repository.SaveTransferLimitException(ctx, exception)
+}
+
+func (s *TransferLimitService) DeleteException(ctx context.Context, username string) error {
+	user, err := s.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return models.ErrUserNotFound
+	}
+
+	deleted, err := s.repository.DeleteTransferLimitException(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return models.ErrLimitExceptionNotFound
+	}
+	return nil
+}
+
+// limitsOf returns the limits of the user at the given time and the exception they come from,
+// if one applies.
+func (s *TransferLimitService) limitsOf(ctx context.Context, userID int64, now time.Time) (models.TransferLimits, *models.TransferLimitException, error) {
+	exception, err := s.repository.GetTransferLimitException(ctx, userID)
+	if err != nil {
+		return models.TransferLimits{}, nil, err
+	}
+	if exception == nil || !exception.Active(now) {
+		return s.limits, nil, nil
+	}
+	return exception.Apply(s.limits), exception, nil
+}
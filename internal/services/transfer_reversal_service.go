@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/pkg/auth"
+)
+
+const (
+	DefaultTransferCancelWindow = 15 * time.Minute
+	maxReversalReasonLen        = 200
+)
+
+type TransferReversalServiceInterface interface {
+	CancelTransfer(ctx context.Context, username string, transferID int64) (*models.TransferReversal, error)
+	ReverseTransfer(ctx context.Context, admin string, transferID int64, reason string) (*models.TransferReversal, error)
+}
+
+type TransferReversalService struct {
+	repository   repository.TransactionRepositoryInterface
+	cancelWindow time.Duration
+	clock        auth.Clock
+}
+
+// NewTransferReversalService creates the service; senders may cancel their transfers for
+// cancelWindow, DefaultTransferCancelWindow if it is not positive. A nil clock means the
+// system clock.
+func NewTransferReversalService(repo repository.TransactionRepositoryInterface, cancelWindow time.Duration, clock auth.Clock) *TransferReversalService {
+	if cancelWindow <= 0 {
+		cancelWindow = DefaultTransferCancelWindow
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &TransferReversalService{repository: repo, cancelWindow: cancelWindow, clock: clock}
+}
+
+// CancelTransfer reverses a transfer of the user within the cancellation window, provided the
+// recipient has not spent any coins since. Transfers of other users are reported as not found.
+func (s *TransferReversalService) CancelTransfer(ctx context.Context, username string, transferID int64) (*models.TransferReversal, error) {
+	transfer, err := s.repository.GetTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil || transfer.FromUser != username {
+		return nil, models.ErrTransferNotFound
+	}
+	if transfer.ReversalID != nil {
+		return nil, models.ErrTransferAlreadyReversed
+	}
+	deadline := transfer.CreatedAt.UTC().Add(s.cancelWindow)
+	if !s.clock.Now().UTC().Before(deadline) {
+		return nil, fmt.Errorf("%w: the cancellation window closed at %s", models.ErrTransferNotReversible, deadline.Format(time.RFC3339))
+	}
+
+	reversal := &models.TransferReversal{TransferID: transferID, ReversedBy: username}
+	if err = s.repository.ReverseTransfer(ctx, reversal, true); err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
+
+// ReverseTransfer reverses any transfer on behalf of an admin, who has to give a reason. The
+// recipient must still have the coins.
+func (s *TransferReversalService) ReverseTransfer(ctx context.Context, admin string, transferID int64, reason string) (*models.TransferReversal, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxReversalReasonLen {
+		return nil, fmt.Errorf("%w: reason must be 1 to %d characters long", models.ErrInvalidReversal, maxReversalReasonLen)
+	}
+
+	reversal := &models.TransferReversal{TransferID: transferID, ReversedBy: admin, Reason: reason}
+	if err := s.repository.ReverseTransfer(ctx, reversal, false); err != nil {
+		return nil, err
+	}
+	return reversal, nil
+}
@@ -2,26 +2,47 @@ package services
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/avito-shop-service/internal/models"
 	"github.com/avito-shop-service/internal/repository"
 	"github.com/avito-shop-service/pkg/auth"
+	"log"
 )
 
 type UserServiceInterface interface {
 	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
 	CreateUser(ctx context.Context, username, password string) (*models.User, error)
-	Authenticate(ctx context.Context, username, password string) (string, error)
+	Authenticate(ctx context.Context, username, password string, client models.ClientInfo) (*models.AuthTokens, error)
+	AuthenticateExternal(ctx context.Context, provider string, credentials models.Credentials, client models.ClientInfo) (*models.AuthTokens, error)
+	RecordFailedLogin(ctx context.Context, username string, client models.ClientInfo, err error)
+	UnlockUser(ctx context.Context, username string) error
 }
 
+const initialCoins = 1000
+
+// externalAccountPassword is stored for accounts provisioned by external providers.
+// It is not a valid hash, so such accounts cannot sign in with a local password.
+const externalAccountPassword = "!"
+
 type UserService struct {
-	repository repository.UserRepositoryInterface
+	repository     repository.UserRepositoryInterface
+	tokenService   TokenServiceInterface
+	loginGuard     LoginGuardInterface
+	loginRecorder  LoginRecorder
+	authenticators []Authenticator
 }
 
-func NewUserService(repository repository.UserRepositoryInterface) *UserService {
-	return &UserService{repository: repository}
+// NewUserService creates the service. Password logins try the authenticators in the given order.
+// Every sign-in attempt is recorded with loginRecorder.
+func NewUserService(repository repository.UserRepositoryInterface, tokenService TokenServiceInterface, loginGuard LoginGuardInterface, loginRecorder LoginRecorder, authenticators ...Authenticator) *UserService {
+	return &UserService{
+		repository:     repository,
+		tokenService:   tokenService,
+		loginGuard:     loginGuard,
+		loginRecorder:  loginRecorder,
+		authenticators: authenticators,
+	}
 }
 
 func (s *UserService) CreateUser(ctx context.Context, username, password string) (*models.User, error) {
@@ -30,18 +51,25 @@ func (s *UserService) CreateUser(ctx context.Context, username, password string)
 		return nil, fmt.Errorf("error checking user existence: %v", err)
 	}
 	if existingUser != nil {
-		return nil, fmt.Errorf("user with this username already exists")
+		return nil, models.ErrUserAlreadyExists
 	}
 
-	hashedPassword := HashPassword(password)
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %v", err)
+	}
 
 	user := &models.User{
 		Username: username,
 		Password: hashedPassword,
-		Coins:    1000,
+		Coins:    initialCoins,
+		Roles:    []string{auth.RoleEmployee},
 	}
 
 	err = s.repository.CreateUser(ctx, user)
+	if errors.Is(err, models.ErrUserAlreadyExists) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error creating user: %v", err)
 	}
@@ -56,36 +84,176 @@ func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*
 	return s.repository.GetUserByUsername(ctx, username)
 }
 
-func (s *UserService) Authenticate(ctx context.Context, username, password string) (string, error) {
-	user, err := s.repository.GetUserByUsername(ctx, username)
+// Authenticate checks the password with each password provider in turn and issues tokens.
+// Attempts are refused with a *models.LoginBlockedError while the username or the client is
+// blocked after failed logins.
+func (s *UserService) Authenticate(ctx context.Context, username, password string, client models.ClientInfo) (*models.AuthTokens, error) {
+	if err := s.loginGuard.Check(ctx, username, client); err != nil {
+		s.recordFailure(ctx, username, "", client, err)
+		return nil, err
+	}
+
+	identity, err := s.authenticatePassword(ctx, models.Credentials{Username: username, Password: password})
+	if errors.Is(err, models.ErrInvalidCredentials) {
+		s.loginGuard.RecordFailure(ctx, username, client)
+	}
 	if err != nil {
-		return "", fmt.Errorf("error fetching user: %v", err)
+		s.recordFailure(ctx, username, "", client, err)
+		return nil, err
+	}
+	s.loginGuard.RecordSuccess(ctx, username)
+
+	return s.issueTokens(ctx, identity, client)
+}
+
+// AuthenticateExternal completes a login with the named provider, e.g. the OIDC authorization
+// code flow, and issues tokens.
+func (s *UserService) AuthenticateExternal(ctx context.Context, provider string, credentials models.Credentials, client models.ClientInfo) (*models.AuthTokens, error) {
+	for _, authenticator := range s.authenticators {
+		if authenticator.Name() != provider {
+			continue
+		}
+		identity, err := authenticator.Authenticate(ctx, credentials)
+		if err != nil {
+			// The username is not known until the provider has vouched for it.
+			s.recordFailure(ctx, "", provider, client, err)
+			return nil, err
+		}
+		return s.issueTokens(ctx, identity, client)
+	}
+	return nil, models.ErrUnknownProvider
+}
+
+// authenticatePassword returns the identity from the first provider that accepts the password.
+// When none does, an error of an unavailable provider takes precedence over invalid credentials,
+// so an outage is not reported as a wrong password.
+func (s *UserService) authenticatePassword(ctx context.Context, credentials models.Credentials) (*models.Identity, error) {
+	var providerErr error
+	for _, authenticator := range s.authenticators {
+		identity, err := authenticator.Authenticate(ctx, credentials)
+		if err == nil {
+			return identity, nil
+		}
+		if !errors.Is(err, models.ErrInvalidCredentials) {
+			log.Printf("error authenticating with %s provider: %v", authenticator.Name(), err)
+			if providerErr == nil {
+				providerErr = err
+			}
+		}
 	}
-	if user == nil || !checkPasswordHash(password, user.Password) {
-		return "", fmt.Errorf("invalid username or password")
+	if providerErr != nil {
+		return nil, providerErr
 	}
+	return nil, models.ErrInvalidCredentials
+}
 
-	signedToken, err := auth.GenerateToken(user.Username)
+// issueTokens issues tokens for the local account of the identity and records the login. The
+// account is provisioned on the first login through an external provider. Suspended and
+// terminated accounts are refused with a *models.AccountInactiveError.
+func (s *UserService) issueTokens(ctx context.Context, identity *models.Identity, client models.ClientInfo) (*models.AuthTokens, error) {
+	user, err := s.accountFor(ctx, identity)
+	if err == nil && !user.IsActive() {
+		err = &models.AccountInactiveError{Status: user.Status}
+	}
 	if err != nil {
-		return "", fmt.Errorf("error with generating token: %v", err)
+		s.recordFailure(ctx, identity.Username, identity.Provider, client, err)
+		return nil, err
+	}
+	tokens, err := s.tokenService.IssueTokens(ctx, user, client)
+	if err != nil {
+		s.recordFailure(ctx, identity.Username, identity.Provider, client, err)
+		return nil, err
 	}
 
-	return signedToken, nil
+	s.loginRecorder.RecordLogin(ctx, models.LoginEvent{
+		Username:  user.Username,
+		UserID:    &user.ID,
+		Provider:  identity.Provider,
+		Success:   true,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	})
+	return tokens, nil
 }
 
-func (s *UserService) UpdateUserCoins(ctx context.Context, userID int64, coins int) error {
-	if coins < 0 {
-		return fmt.Errorf("negative amount of coins")
+func (s *UserService) accountFor(ctx context.Context, identity *models.Identity) (*models.User, error) {
+	if identity.User != nil {
+		return identity.User, nil
 	}
-	return s.repository.UpdateUserCoins(ctx, userID, coins)
+	user, err := s.repository.GetUserByUsername(ctx, identity.Username)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return s.provisionExternalUser(ctx, identity)
+	}
+	return user, nil
+}
+
+// RecordFailedLogin records a sign-in attempt that was refused before it reached Authenticate,
+// for example because the request was malformed.
+func (s *UserService) RecordFailedLogin(ctx context.Context, username string, client models.ClientInfo, err error) {
+	s.recordFailure(ctx, username, "", client, err)
+}
+
+func (s *UserService) recordFailure(ctx context.Context, username, provider string, client models.ClientInfo, err error) {
+	reason := models.LoginFailureError
+	switch {
+	case errors.Is(err, models.ErrInvalidCredentials):
+		reason = models.LoginFailureInvalidCredentials
+	case errors.Is(err, models.ErrLoginBlocked):
+		reason = models.LoginFailureBlocked
+	case errors.Is(err, models.ErrAccountInactive):
+		reason = models.LoginFailureInactive
+	case errors.Is(err, models.ErrInvalidLoginRequest):
+		reason = models.LoginFailureInvalidRequest
+	}
+	s.loginRecorder.RecordLogin(ctx, models.LoginEvent{
+		Username:      username,
+		Provider:      provider,
+		FailureReason: reason,
+		IP:            client.IP,
+		UserAgent:     client.UserAgent,
+	})
+}
+
+func (s *UserService) provisionExternalUser(ctx context.Context, identity *models.Identity) (*models.User, error) {
+	user := &models.User{
+		Username: identity.Username,
+		Password: externalAccountPassword,
+		Coins:    initialCoins,
+		Roles:    []string{auth.RoleEmployee},
+	}
+	err := s.repository.CreateUser(ctx, user)
+	if errors.Is(err, models.ErrUserAlreadyExists) {
+		// A concurrent login has provisioned the account first.
+		return s.repository.GetUserByUsername(ctx, identity.Username)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error provisioning user: %v", err)
+	}
+	log.Printf("provisioned user %q on first login with %s provider", identity.Username, identity.Provider)
+	return user, nil
 }
 
-func HashPassword(password string) string {
-	hash := sha256.New()
-	hash.Write([]byte(password))
-	return hex.EncodeToString(hash.Sum(nil))
+// UnlockUser lifts a lockout caused by failed logins.
+func (s *UserService) UnlockUser(ctx context.Context, username string) error {
+	user, err := s.repository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return fmt.Errorf("error fetching user: %v", err)
+	}
+	if user == nil {
+		return models.ErrUserNotFound
+	}
+	if err = s.loginGuard.Unlock(ctx, username); err != nil {
+		return fmt.Errorf("error unlocking user: %v", err)
+	}
+	return nil
 }
 
-func checkPasswordHash(password, hashedPassword string) bool {
-	return HashPassword(password) == hashedPassword
+func (s *UserService) UpdateUserCoins(ctx context.Context, userID int64, coins int) error {
+	if coins < 0 {
+		return fmt.Errorf("negative amount of coins")
+	}
+	return s.repository.UpdateUserCoins(ctx, userID, coins)
 }
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS session_id INT REFERENCES sessions(id);
+
+CREATE TABLE IF NOT EXISTS login_events (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    user_id INT,
+    provider VARCHAR(32) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(32) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_username_created_at ON login_events(username, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_events_ip_created_at ON login_events(ip, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at);
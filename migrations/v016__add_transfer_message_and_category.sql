@@ -0,0 +1,5 @@
+-- Transfers double as peer recognition: the sender may add a message and a kudos category.
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS message VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS category VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_category ON ledger_entries (category, created_at) WHERE kind = 'transfer';
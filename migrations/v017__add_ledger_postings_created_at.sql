@@ -0,0 +1,11 @@
+-- The transaction history API pages through the postings of one account by time. The time of
+-- the entry is copied onto its postings so that the account, time and posting id of the keyset
+-- are served by a single index.
+ALTER TABLE ledger_postings ADD COLUMN IF NOT EXISTS created_at TIMESTAMP;
+UPDATE ledger_postings p SET created_at = e.created_at FROM ledger_entries e WHERE e.id = p.entry_id AND p.created_at IS NULL;
+ALTER TABLE ledger_postings ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE ledger_postings ALTER COLUMN created_at SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_ledger_postings_account_id;
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account_created_at ON ledger_postings (account_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry_account ON ledger_postings (entry_id, account_id);
@@ -0,0 +1,28 @@
+-- Transfers that are sent on a cron schedule on behalf of their owner, and the outcome of every
+-- run so that the owner can see failed ones.
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL CHECK (amount > 0),
+    message VARCHAR(500) NOT NULL DEFAULT '',
+    category VARCHAR(64) NOT NULL DEFAULT '',
+    schedule VARCHAR(100) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_owner_id ON scheduled_transfers (owner_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_next_run_at ON scheduled_transfers (next_run_at) WHERE active;
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    id BIGSERIAL PRIMARY KEY,
+    scheduled_transfer_id BIGINT NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMP NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (scheduled_transfer_id, scheduled_for)
+);
@@ -0,0 +1,6 @@
+-- A transfer is undone by a compensating refund entry that points at it, never by deleting it.
+-- The unique index makes sure a transfer is reversed at most once.
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS reverses_entry_id BIGINT REFERENCES ledger_entries(id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_entries_reverses_entry_id ON ledger_entries (reverses_entry_id)
+    WHERE reverses_entry_id IS NOT NULL;
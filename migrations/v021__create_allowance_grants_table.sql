@@ -0,0 +1,9 @@
+-- The allowance is granted once per user and period. The row is written in the same transaction
+-- as the grant, so a period that has been granted is never granted again, even when several
+-- instances run the allowance job.
+CREATE TABLE IF NOT EXISTS allowance_grants (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, period)
+);
@@ -0,0 +1,24 @@
+-- Balances are tracked as lots: every credit to a user opens a lot with its grant time, and
+-- every debit consumes the oldest lots first. Coins moved between users keep the grant time of
+-- the lot they came from. Lots expire a while after they were granted.
+CREATE TABLE IF NOT EXISTS coin_lots (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    entry_id BIGINT REFERENCES ledger_entries(id),
+    amount INT NOT NULL CHECK (amount > 0),
+    remaining INT NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+    granted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_coin_lots_open ON coin_lots (user_id, granted_at, id) WHERE remaining > 0;
+CREATE INDEX IF NOT EXISTS idx_coin_lots_open_granted_at ON coin_lots (granted_at) WHERE remaining > 0;
+
+-- Balances from before lots existed become one lot per user, granted now, so that no coins
+-- expire earlier than a full lifetime after the introduction of expiry.
+INSERT INTO coin_lots (user_id, amount, remaining, granted_at)
+SELECT u.id, u.coins, u.coins, CURRENT_TIMESTAMP FROM users u
+WHERE u.coins > 0 AND NOT EXISTS (SELECT 1 FROM coin_lots l WHERE l.user_id = u.id);
+
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_kind_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_kind_check
+    CHECK (kind IN ('transfer', 'purchase', 'grant', 'refund', 'adjustment', 'expiry'));
@@ -0,0 +1,5 @@
+-- A run is recorded as pending in the transaction that claims it, before its transfer is sent,
+-- and gets its outcome afterwards. A run left pending was interrupted while sending.
+ALTER TABLE scheduled_transfer_runs DROP CONSTRAINT IF EXISTS scheduled_transfer_runs_status_check;
+ALTER TABLE scheduled_transfer_runs ADD CONSTRAINT scheduled_transfer_runs_status_check
+    CHECK (status IN ('pending', 'succeeded', 'failed'));
@@ -0,0 +1,74 @@
+-- Every movement of coins is a journal entry whose postings sum to zero. users.coins is kept as
+-- a cache of the sum of the postings on the account of the user.
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    id SERIAL PRIMARY KEY,
+    user_id INT UNIQUE REFERENCES users(id),
+    code VARCHAR(32) UNIQUE,
+    CHECK ((user_id IS NULL) <> (code IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('transfer', 'purchase', 'grant', 'refund', 'adjustment')),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES ledger_entries(id),
+    account_id INT NOT NULL REFERENCES ledger_accounts(id),
+    amount INT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry_id ON ledger_postings (entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account_id ON ledger_postings (account_id);
+
+-- Coins are issued from the issuance account and spent on merch into the shop account.
+INSERT INTO ledger_accounts (code) VALUES ('issuance'), ('shop') ON CONFLICT DO NOTHING;
+INSERT INTO ledger_accounts (user_id) SELECT id FROM users ON CONFLICT DO NOTHING;
+
+-- Transfers are carried over from the sender side of coin_transactions, and an opening balance
+-- makes every account agree with the balance accumulated before the ledger existed.
+DO $$
+DECLARE
+    t RECORD;
+    entry BIGINT;
+BEGIN
+    FOR t IN SELECT ct.user_id, u.id AS recipient_id, ct.amount, ct.created_at
+             FROM coin_transactions ct JOIN users u ON u.username = ct.counterpart_username
+             WHERE ct.transaction_type = 'sent' AND ct.amount > 0 ORDER BY ct.id LOOP
+        INSERT INTO ledger_entries (kind, created_at) VALUES ('transfer', t.created_at) RETURNING id INTO entry;
+        INSERT INTO ledger_postings (entry_id, account_id, amount)
+            SELECT entry, id, -t.amount FROM ledger_accounts WHERE user_id = t.user_id;
+        INSERT INTO ledger_postings (entry_id, account_id, amount)
+            SELECT entry, id, t.amount FROM ledger_accounts WHERE user_id = t.recipient_id;
+    END LOOP;
+
+    FOR t IN SELECT a.id AS account_id, u.coins - COALESCE(SUM(p.amount), 0) AS opening
+             FROM users u JOIN ledger_accounts a ON a.user_id = u.id
+             LEFT JOIN ledger_postings p ON p.account_id = a.id
+             GROUP BY a.id, u.coins HAVING u.coins - COALESCE(SUM(p.amount), 0) <> 0 LOOP
+        INSERT INTO ledger_entries (kind, description) VALUES ('grant', 'opening balance') RETURNING id INTO entry;
+        INSERT INTO ledger_postings (entry_id, account_id, amount) VALUES (entry, t.account_id, t.opening);
+        INSERT INTO ledger_postings (entry_id, account_id, amount)
+            SELECT entry, id, -t.opening FROM ledger_accounts WHERE code = 'issuance';
+    END LOOP;
+END $$;
+
+-- The ledger replaces coin_transactions. v023 recreates it as a read-only view over the ledger
+-- for queries that still read it.
+DROP TABLE IF EXISTS coin_transactions;
+
+-- A journal entry must balance when the transaction that writes it commits.
+CREATE OR REPLACE FUNCTION check_ledger_entry_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger entry % does not balance', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END $$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_entry_balanced ON ledger_postings;
+CREATE CONSTRAINT TRIGGER ledger_entry_balanced AFTER INSERT OR UPDATE ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED FOR EACH ROW EXECUTE FUNCTION check_ledger_entry_balanced();
@@ -0,0 +1,13 @@
+-- Responses to requests sent with an Idempotency-Key header, replayed when the request is
+-- retried. status_code is NULL while the first request is still being processed.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    username VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INT,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (username, idempotency_key)
+);
@@ -0,0 +1,24 @@
+-- Purchases with the item, quantity and unit price at the time of purchase. The coins are moved
+-- by the ledger entry; the item is kept by name as well, since merch may be renamed or removed.
+CREATE TABLE IF NOT EXISTS purchases (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL UNIQUE REFERENCES ledger_entries(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    item_id INT REFERENCES merch(id) ON DELETE SET NULL,
+    item_name VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price INT NOT NULL CHECK (unit_price >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchases_user_id ON purchases (user_id, created_at);
+
+-- Purchases recorded by the ledger before this table existed were always of a single item.
+INSERT INTO purchases (entry_id, user_id, item_id, item_name, quantity, unit_price, created_at)
+SELECT e.id, a.user_id, m.id, e.description, 1, -p.amount, e.created_at
+FROM ledger_entries e
+JOIN ledger_postings p ON p.entry_id = e.id
+JOIN ledger_accounts a ON a.id = p.account_id AND a.user_id IS NOT NULL
+LEFT JOIN merch m ON m.item_name = e.description
+WHERE e.kind = 'purchase'
+ON CONFLICT (entry_id) DO NOTHING;
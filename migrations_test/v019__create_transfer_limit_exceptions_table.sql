@@ -0,0 +1,13 @@
+-- Per-user exceptions to the configured transfer limits, granted by finance administrators. A
+-- NULL limit keeps the configured one, 0 lifts it.
+CREATE TABLE IF NOT EXISTS transfer_limit_exceptions (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_per_transfer INT CHECK (max_per_transfer >= 0),
+    daily_limit INT CHECK (daily_limit >= 0),
+    monthly_limit INT CHECK (monthly_limit >= 0),
+    recipient_limit INT CHECK (recipient_limit >= 0),
+    reason VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
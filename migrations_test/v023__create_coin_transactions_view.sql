@@ -0,0 +1,15 @@
+-- coin_transactions was replaced by the ledger in v013. This read-only view keeps queries written
+-- against the old table working: every transfer appears once for the sender as 'sent' and once
+-- for the recipient as 'received', with a positive amount, as the table recorded it. Refunds,
+-- purchases and grants were never in coin_transactions and are not shown.
+CREATE OR REPLACE VIEW coin_transactions AS
+SELECT p.id, a.user_id, cu.username AS counterpart_username, ABS(p.amount) AS amount,
+       CAST(CASE WHEN p.amount < 0 THEN 'sent' ELSE 'received' END AS VARCHAR(10)) AS transaction_type,
+       e.created_at
+FROM ledger_entries e
+JOIN ledger_postings p ON p.entry_id = e.id
+JOIN ledger_accounts a ON a.id = p.account_id AND a.user_id IS NOT NULL
+JOIN ledger_postings cp ON cp.entry_id = e.id AND cp.id <> p.id
+JOIN ledger_accounts ca ON ca.id = cp.account_id
+JOIN users cu ON cu.id = ca.user_id
+WHERE e.kind = 'transfer';
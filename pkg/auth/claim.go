@@ -3,6 +3,30 @@ package auth
 import "github.com/golang-jwt/jwt/v4"
 
 type Claims struct {
-	EmployeeUsername string `json:"employee_username"`
+	EmployeeUsername string   `json:"employee_username"`
+	Roles            []string `json:"roles,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
+	// SessionID identifies the sign-in the token was issued for, so revoking the session
+	// also rejects its access tokens.
+	SessionID int64 `json:"sid,omitempty"`
+	// APIKeyID is set when the request was authenticated with an API key instead of a token.
+	APIKeyID int64 `json:"-"`
 	jwt.RegisteredClaims
 }
+
+func (c *Claims) HasRole(role string) bool {
+	return contains(c.Roles, role)
+}
+
+func (c *Claims) HasScope(scope string) bool {
+	return contains(c.Scopes, scope)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+)
+
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// SupportedAlgorithms are the signing algorithms of the key types a KeySet can hold.
+var SupportedAlgorithms = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+type Config struct {
+	AccessTokenTTL time.Duration
+	Keys           *KeySet
+	// Issuer is written to the iss claim of new tokens and, when set, required in parsed ones.
+	Issuer string
+	// Audience is written to the aud claim of new tokens and, when set, parsed tokens must be
+	// meant for at least one of its values.
+	Audience []string
+	// AllowedAlgorithms limits the signing algorithms accepted on parsing. Empty means
+	// SupportedAlgorithms.
+	AllowedAlgorithms []string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+	// MaxAge rejects tokens issued longer ago than that, whatever their exp. Zero disables it.
+	MaxAge time.Duration
+}
+
+// LoadConfig reads token settings and keys from a YAML file. Relative key paths inside the
+// file are resolved against jwt_key_dir, which the JWT_KEY_DIR environment variable overrides,
+// so private keys can be mounted as secrets instead of living next to the file. Without it they
+// are resolved relative to the file.
+func LoadConfig(path string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.BindEnv("jwt_key_dir", "JWT_KEY_DIR"); err != nil {
+		return nil, fmt.Errorf("error binding JWT_KEY_DIR: %v", err)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	signingKid := v.GetString("jwt_signing_key_id")
+	if signingKid == "" {
+		return nil, fmt.Errorf("jwt_signing_key_id is not set in config file")
+	}
+
+	var keyConfigs []keyConfig
+	if err := v.UnmarshalKey("jwt_keys", &keyConfigs); err != nil {
+		return nil, fmt.Errorf("error reading jwt_keys from config file: %v", err)
+	}
+
+	keyDir := resolvePath(filepath.Dir(path), v.GetString("jwt_key_dir"))
+	keys, err := loadKeySet(keyDir, signingKid, keyConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("error loading jwt keys: %v", err)
+	}
+
+	accessTokenTTL := v.GetDuration("access_token_ttl")
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = defaultAccessTokenTTL
+	}
+
+	allowedAlgorithms := v.GetStringSlice("jwt_allowed_algorithms")
+	for _, alg := range allowedAlgorithms {
+		if !contains(SupportedAlgorithms, alg) {
+			return nil, fmt.Errorf("unsupported algorithm %q in jwt_allowed_algorithms, must be one of %v", alg, SupportedAlgorithms)
+		}
+	}
+	if len(allowedAlgorithms) > 0 && !contains(allowedAlgorithms, keys.signing.method.Alg()) {
+		return nil, fmt.Errorf("algorithm %s of signing key %q is not in jwt_allowed_algorithms", keys.signing.method.Alg(), signingKid)
+	}
+
+	leeway := v.GetDuration("jwt_leeway")
+	maxAge := v.GetDuration("jwt_max_age")
+	if leeway < 0 || maxAge < 0 {
+		return nil, fmt.Errorf("jwt_leeway and jwt_max_age must not be negative")
+	}
+	if maxAge > 0 && maxAge < accessTokenTTL {
+		return nil, fmt.Errorf("jwt_max_age %s is shorter than access_token_ttl %s", maxAge, accessTokenTTL)
+	}
+
+	return &Config{
+		AccessTokenTTL:    accessTokenTTL,
+		Keys:              keys,
+		Issuer:            v.GetString("jwt_issuer"),
+		Audience:          v.GetStringSlice("jwt_audience"),
+		AllowedAlgorithms: allowedAlgorithms,
+		Leeway:            leeway,
+		MaxAge:            maxAge,
+	}, nil
+}
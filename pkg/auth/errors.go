@@ -0,0 +1,17 @@
+package auth
+
+import "errors"
+
+// Errors returned by ParseToken. Each one names the reason a token was rejected, so callers can
+// tell clients why without exposing anything else about the token.
+var (
+	ErrTokenMalformed   = errors.New("token is malformed")
+	ErrTokenUnknownKey  = errors.New("token is signed with an unknown key")
+	ErrTokenAlgorithm   = errors.New("token signing algorithm is not allowed")
+	ErrTokenSignature   = errors.New("token signature is invalid")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrTokenTooOld      = errors.New("token is older than the maximum age")
+	ErrTokenIssuer      = errors.New("token issuer is not accepted")
+	ErrTokenAudience    = errors.New("token audience is not accepted")
+)
@@ -1,57 +1,183 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"github.com/spf13/viper"
-	"log"
-	"os"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-var JwtSecretKey = []byte(getSecretKey())
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type TokenManager interface {
+	// IssueToken signs the claims as an access token. The token id, issue time and expiry
+	// are filled in by the manager.
+	IssueToken(claims Claims) (string, error)
+	ParseToken(tokenStr string) (*Claims, error)
+	AccessTokenTTL() time.Duration
+	JWKS() JWKS
+}
+
+type JWTManager struct {
+	keys              *KeySet
+	accessTokenTTL    time.Duration
+	issuer            string
+	audience          []string
+	allowedAlgorithms []string
+	leeway            time.Duration
+	maxAge            time.Duration
+	clock             Clock
+}
+
+// NewJWTManager creates a token manager. A nil clock means the system clock.
+func NewJWTManager(cfg *Config, clock Clock) *JWTManager {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	accessTokenTTL := cfg.AccessTokenTTL
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = defaultAccessTokenTTL
+	}
+	allowedAlgorithms := cfg.AllowedAlgorithms
+	if len(allowedAlgorithms) == 0 {
+		allowedAlgorithms = SupportedAlgorithms
+	}
+	return &JWTManager{
+		keys:              cfg.Keys,
+		accessTokenTTL:    accessTokenTTL,
+		issuer:            cfg.Issuer,
+		audience:          cfg.Audience,
+		allowedAlgorithms: allowedAlgorithms,
+		leeway:            cfg.Leeway,
+		maxAge:            cfg.MaxAge,
+		clock:             clock,
+	}
+}
 
-func GenerateToken(employeeUsername string) (string, error) {
-	claims := Claims{
-		EmployeeUsername: employeeUsername,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
-		},
+func (m *JWTManager) IssueToken(claims Claims) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JwtSecretKey)
+
+	now := m.clock.Now()
+	claims.ID = jti
+	claims.Issuer = m.issuer
+	claims.Audience = m.audience
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(m.accessTokenTTL))
+
+	token := jwt.NewWithClaims(m.keys.signing.method, claims)
+	token.Header["kid"] = m.keys.signing.kid
+	return token.SignedString(m.keys.signing.key)
 }
 
-func ParseToken(tokenStr string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return JwtSecretKey, nil
-	})
+// ParseToken verifies the signature and the registered claims of the token. Every failure
+// wraps one of the ErrToken errors describing why the token was rejected.
+func (m *JWTManager) ParseToken(tokenStr string) (*Claims, error) {
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(tokenStr, &Claims{}, m.keyFunc)
 	if err != nil {
-		return nil, err
+		return nil, parseError(err)
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, ErrTokenMalformed
+	}
+	if err := m.verifyClaims(claims); err != nil {
+		return nil, err
 	}
 	return claims, nil
 }
 
-func getSecretKey() string {
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		configPath = "pkg/auth/jwt_key/config.yaml"
+func (m *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	if !contains(m.allowedAlgorithms, token.Method.Alg()) {
+		return nil, fmt.Errorf("%w: %q", ErrTokenAlgorithm, token.Method.Alg())
 	}
+	return m.keys.keyFunc(token)
+}
+
+// verifyClaims checks the time claims, allowing for clock skew between services of up to the
+// leeway, and the issuer and audience when they are configured.
+func (m *JWTManager) verifyClaims(claims *Claims) error {
+	now := m.clock.Now()
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: exp claim is missing", ErrTokenMalformed)
+	}
+	if !now.Before(claims.ExpiresAt.Add(m.leeway)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now.Add(m.leeway).Before(claims.NotBefore.Time) {
+		return ErrTokenNotYetValid
+	}
+	if claims.IssuedAt != nil && now.Add(m.leeway).Before(claims.IssuedAt.Time) {
+		return fmt.Errorf("%w: issued in the future", ErrTokenNotYetValid)
+	}
+	if m.maxAge > 0 {
+		if claims.IssuedAt == nil {
+			return fmt.Errorf("%w: iat claim is missing", ErrTokenMalformed)
+		}
+		if now.After(claims.IssuedAt.Add(m.maxAge + m.leeway)) {
+			return ErrTokenTooOld
+		}
+	}
+	if m.issuer != "" && claims.Issuer != m.issuer {
+		return fmt.Errorf("%w: %q", ErrTokenIssuer, claims.Issuer)
+	}
+	if len(m.audience) > 0 && !containsAny(claims.Audience, m.audience) {
+		return ErrTokenAudience
+	}
+	return nil
+}
 
-	viper.SetConfigFile(configPath)
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatal("Error reading config file ", err)
+// parseError keeps the reason returned by the key function and classifies the rest.
+func parseError(err error) error {
+	var validationErr *jwt.ValidationError
+	if !errors.As(err, &validationErr) {
+		return fmt.Errorf("%w: %v", ErrTokenMalformed, err)
 	}
+	if errors.Is(validationErr.Inner, ErrTokenUnknownKey) || errors.Is(validationErr.Inner, ErrTokenAlgorithm) {
+		return validationErr.Inner
+	}
+	if validationErr.Errors&jwt.ValidationErrorSignatureInvalid != 0 {
+		return ErrTokenSignature
+	}
+	return fmt.Errorf("%w: %v", ErrTokenMalformed, err)
+}
+
+func (m *JWTManager) AccessTokenTTL() time.Duration {
+	return m.accessTokenTTL
+}
+
+func (m *JWTManager) JWKS() JWKS {
+	return m.keys.JWKS()
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, value := range wanted {
+		if contains(values, value) {
+			return true
+		}
+	}
+	return false
+}
 
-	secret := viper.GetString("jwt_secret_key")
-	if secret == "" {
-		log.Fatal("jwt_secret_key is not set in config file")
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token id: %v", err)
 	}
-	return secret
+	return hex.EncodeToString(b), nil
 }
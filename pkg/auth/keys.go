@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type keyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	PublicKeyPath  string `mapstructure:"public_key_path"`
+}
+
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    crypto.PrivateKey
+}
+
+// KeySet holds the key used to sign new tokens and every key tokens may still be verified with.
+type KeySet struct {
+	signing      *signingKey
+	verification map[string]*verificationKey
+}
+
+// NewKeySet creates a key set that signs with the given RSA or Ed25519 private key.
+func NewKeySet(signingKid string, privateKey crypto.PrivateKey) (*KeySet, error) {
+	method, publicKey, err := methodForPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{
+		signing:      &signingKey{kid: signingKid, method: method, key: privateKey},
+		verification: map[string]*verificationKey{signingKid: {kid: signingKid, method: method, key: publicKey}},
+	}, nil
+}
+
+// AddVerificationKey registers a key that is accepted for verification but never used for signing.
+func (k *KeySet) AddVerificationKey(kid string, publicKey crypto.PublicKey) error {
+	if _, ok := k.verification[kid]; ok {
+		return fmt.Errorf("duplicate jwt key id %q", kid)
+	}
+	method, err := methodForPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	k.verification[kid] = &verificationKey{kid: kid, method: method, key: publicKey}
+	return nil
+}
+
+func loadKeySet(configDir, signingKid string, configs []keyConfig) (*KeySet, error) {
+	keySet := &KeySet{verification: make(map[string]*verificationKey, len(configs))}
+
+	for _, cfg := range configs {
+		if cfg.Kid == "" {
+			return nil, fmt.Errorf("jwt key without kid")
+		}
+		if _, ok := keySet.verification[cfg.Kid]; ok {
+			return nil, fmt.Errorf("duplicate jwt key id %q", cfg.Kid)
+		}
+
+		switch {
+		case cfg.PrivateKeyPath != "":
+			privateKey, err := readPrivateKey(resolvePath(configDir, cfg.PrivateKeyPath))
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %q: %v", cfg.Kid, err)
+			}
+			method, publicKey, err := methodForPrivateKey(privateKey)
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %q: %v", cfg.Kid, err)
+			}
+			keySet.verification[cfg.Kid] = &verificationKey{kid: cfg.Kid, method: method, key: publicKey}
+			if cfg.Kid == signingKid {
+				keySet.signing = &signingKey{kid: cfg.Kid, method: method, key: privateKey}
+			}
+		case cfg.PublicKeyPath != "":
+			publicKey, err := readPublicKey(resolvePath(configDir, cfg.PublicKeyPath))
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %q: %v", cfg.Kid, err)
+			}
+			method, err := methodForPublicKey(publicKey)
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %q: %v", cfg.Kid, err)
+			}
+			keySet.verification[cfg.Kid] = &verificationKey{kid: cfg.Kid, method: method, key: publicKey}
+		default:
+			return nil, fmt.Errorf("jwt key %q has neither private_key_path nor public_key_path", cfg.Kid)
+		}
+	}
+
+	if keySet.signing == nil {
+		return nil, fmt.Errorf("signing key %q not found among jwt keys with a private key", signingKid)
+	}
+	return keySet, nil
+}
+
+func (k *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrTokenUnknownKey, kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("%w: %q for key %q", ErrTokenAlgorithm, token.Method.Alg(), kid)
+	}
+	return key.key, nil
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns every verification key, so other services can check tokens issued by the shop.
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(k.verification))}
+	for _, key := range k.verification {
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch publicKey := key.key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
+
+// PublicKey decodes an RSA or Ed25519 key.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus of key %q: %v", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent of key %q: %v", k.Kid, err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key %q", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q of key %q", k.Kty, k.Kid)
+	}
+}
+
+// KeyFunc verifies tokens signed by a third party with the keys of the set. The token must name
+// a key of the set in its kid header, unless the set has a single key.
+func (s JWKS) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, jwk := range s.Keys {
+		if jwk.Kid != kid && (kid != "" || len(s.Keys) != 1) {
+			continue
+		}
+		if jwk.Use != "" && jwk.Use != "sig" {
+			return nil, fmt.Errorf("key %q is not a signing key", kid)
+		}
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		method, err := methodForPublicKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != method.Alg() {
+			return nil, fmt.Errorf("%w: %q for key %q", ErrTokenAlgorithm, token.Method.Alg(), kid)
+		}
+		return publicKey, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrTokenUnknownKey, kid)
+}
+
+func methodForPrivateKey(key crypto.PrivateKey) (jwt.SigningMethod, crypto.PublicKey, error) {
+	switch privateKey := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, &privateKey.PublicKey, nil
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, privateKey.Public(), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported private key type %T, must be RSA or Ed25519", key)
+	}
+}
+
+func methodForPublicKey(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T, must be RSA or Ed25519", key)
+	}
+}
+
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key file: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %s", path)
+	}
+	return block, nil
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,52 @@
+package auth
+
+const (
+	RoleEmployee      = "employee"
+	RoleMerchAdmin    = "merch-admin"
+	RoleFinanceAdmin  = "finance-admin"
+	RoleSecurityAdmin = "security-admin"
+)
+
+const (
+	ScopeInfoRead   = "info:read"
+	ScopeCoinsSend  = "coins:send"
+	ScopeMerchBuy   = "merch:buy"
+	ScopeMerchWrite = "merch:write"
+	ScopeCoinsAdmin = "coins:admin"
+	ScopeUsersAdmin = "users:admin"
+)
+
+var roleScopes = map[string][]string{
+	RoleEmployee:      {ScopeInfoRead, ScopeCoinsSend, ScopeMerchBuy},
+	RoleMerchAdmin:    {ScopeMerchWrite},
+	RoleFinanceAdmin:  {ScopeCoinsAdmin},
+	RoleSecurityAdmin: {ScopeUsersAdmin},
+}
+
+// IsKnownRole reports whether the role is one the shop grants scopes for.
+func IsKnownRole(role string) bool {
+	_, ok := roleScopes[role]
+	return ok
+}
+
+// ResolveScopes returns the scopes granted by the roles together with the extra scopes,
+// without duplicates and in a stable order.
+func ResolveScopes(roles []string, extraScopes []string) []string {
+	seen := make(map[string]struct{})
+	var scopes []string
+	add := func(scope string) {
+		if _, ok := seen[scope]; !ok {
+			seen[scope] = struct{}{}
+			scopes = append(scopes, scope)
+		}
+	}
+	for _, role := range roles {
+		for _, scope := range roleScopes[role] {
+			add(scope)
+		}
+	}
+	for _, scope := range extraScopes {
+		add(scope)
+	}
+	return scopes
+}
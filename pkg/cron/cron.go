@@ -0,0 +1,146 @@
+// Package cron parses standard five-field cron expressions and computes when they fire.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// horizon bounds the search for the next activation; it spans a leap year, so every schedule
+// that can fire at all does within it.
+const horizon = 5
+
+var macros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// Schedule is a parsed cron expression: minute, hour, day of month, month and day of week. Each
+// field is a bit set of the values it matches. Schedules are evaluated in UTC.
+type Schedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// As in cron, when both day fields are restricted a day matches if either of them does.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+// Parse parses an expression of five space-separated fields, each a list of values, ranges
+// (1-5), wildcards (*) and steps (*/15, 1-30/2), or one of the macros @hourly, @daily,
+// @weekly, @monthly and @yearly. Sunday is 0 or 7 in the day of week field.
+func Parse(expr string) (*Schedule, error) {
+	if macro, ok := macros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidSchedule, len(fields))
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if s.dayOfMonth, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if s.dayOfWeek, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if s.dayOfWeek&(1<<7) != 0 {
+		s.dayOfWeek |= 1
+	}
+	s.anyDayOfMonth = strings.HasPrefix(fields[2], "*")
+	s.anyDayOfWeek = strings.HasPrefix(fields[4], "*")
+
+	if s.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("%w: %q never fires", ErrInvalidSchedule, expr)
+	}
+	return &s, nil
+}
+
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: bad step in %q", ErrInvalidSchedule, part)
+			}
+			step = n
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(first); err != nil {
+				return 0, fmt.Errorf("%w: bad value in %q", ErrInvalidSchedule, part)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(last); err != nil {
+					return 0, fmt.Errorf("%w: bad value in %q", ErrInvalidSchedule, part)
+				}
+			} else if hasStep {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%w: %q is out of range %d-%d", ErrInvalidSchedule, part, min, max)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first activation strictly after the given time, or the zero time if the
+// schedule does not fire within the next years.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(horizon, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dayOfMonth := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
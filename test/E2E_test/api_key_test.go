@@ -0,0 +1,66 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const usernameAPIKey = "apikeyuser"
+const passwordAPIKey = "apikey"
+
+func TestE2E_APIKeyLifecycle(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	authResp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "%s"}`, usernameAPIKey, passwordAPIKey))))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, authResp.StatusCode)
+	var authData map[string]interface{}
+	assert.NoError(t, json.NewDecoder(authResp.Body).Decode(&authData))
+	authResp.Body.Close()
+	token, _ := authData["token"].(string)
+
+	createReq, err := http.NewRequest("POST", baseURL+"/me/api-keys", bytes.NewReader([]byte(`{"name": "review-bot", "scopes": ["info:read"]}`)))
+	assert.NoError(t, err)
+	createReq.Header.Set("Authorization", "Bearer "+token)
+	createResp, err := client.Do(createReq)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, createResp.StatusCode)
+	var keyData map[string]interface{}
+	assert.NoError(t, json.NewDecoder(createResp.Body).Decode(&keyData))
+	createResp.Body.Close()
+	apiKey, _ := keyData["key"].(string)
+	assert.NotEmpty(t, apiKey)
+
+	withKey := func(method, path string) int {
+		req, err := http.NewRequest(method, baseURL+path, bytes.NewReader([]byte(`{"name": "nested", "scopes": ["info:read"]}`)))
+		assert.NoError(t, err)
+		req.Header.Set("X-Api-Key", apiKey)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, withKey("GET", "/info"))
+	assert.Equal(t, http.StatusForbidden, withKey("POST", "/sendCoin"), "key has no coins:send scope")
+	assert.Equal(t, http.StatusForbidden, withKey("POST", "/me/api-keys"), "keys must not create keys")
+
+	revokeReq, err := http.NewRequest("DELETE", fmt.Sprintf("%s/me/api-keys/%.0f", baseURL, keyData["id"]), nil)
+	assert.NoError(t, err)
+	revokeReq.Header.Set("Authorization", "Bearer "+token)
+	revokeResp, err := client.Do(revokeReq)
+	assert.NoError(t, err)
+	revokeResp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, revokeResp.StatusCode)
+
+	assert.Equal(t, http.StatusUnauthorized, withKey("GET", "/info"))
+}
@@ -0,0 +1,91 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_SendCoinBatch(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	suffix := time.Now().UnixNano()
+	sender := fmt.Sprintf("batchsender%d", suffix)
+	first := fmt.Sprintf("batchfirst%d", suffix)
+	second := fmt.Sprintf("batchsecond%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	senderToken := login(sender)
+	firstToken := login(first)
+	secondToken := login(second)
+
+	type result struct {
+		ToUser     string `json:"toUser"`
+		Status     string `json:"status"`
+		TransferID int64  `json:"transferId"`
+		Error      string `json:"error"`
+	}
+	sendBatch := func(body string) (int, []result) {
+		req, err := http.NewRequest("POST", baseURL+"/sendCoin/batch", bytes.NewReader([]byte(body)))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var response struct {
+			Results []result `json:"results"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response.Results
+	}
+	coins := func(token string) int {
+		req, err := http.NewRequest("GET", baseURL+"/info", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var info struct {
+			Coins int `json:"coins"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info.Coins
+	}
+
+	status, results := sendBatch(fmt.Sprintf(`{"transfers": [{"toUser": "%s", "amount": 100}, {"toUser": "%s", "amount": 200, "message": "thanks"}]}`, first, second))
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "sent", results[0].Status)
+		assert.NotZero(t, results[0].TransferID)
+		assert.Equal(t, "sent", results[1].Status)
+	}
+	assert.Equal(t, 700, coins(senderToken))
+	assert.Equal(t, 1100, coins(firstToken))
+	assert.Equal(t, 1200, coins(secondToken))
+
+	// The second transfer does not fit the balance, so the first one is not sent either.
+	status, results = sendBatch(fmt.Sprintf(`{"transfers": [{"toUser": "%s", "amount": 500}, {"toUser": "%s", "amount": 500}]}`, first, second))
+	assert.Equal(t, http.StatusBadRequest, status)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "skipped", results[0].Status)
+		assert.Equal(t, "failed", results[1].Status)
+		assert.Contains(t, results[1].Error, "not enough coins")
+	}
+	assert.Equal(t, 700, coins(senderToken))
+	assert.Equal(t, 1100, coins(firstToken))
+}
@@ -112,4 +112,13 @@ func TestE2E_BuyMerch(t *testing.T) {
 	}
 
 	assert.True(t, itemFound, "Merch must be in inventory")
+
+	coinHistory, _ := userInfo2["coinHistory"].(map[string]interface{})
+	purchases, _ := coinHistory["purchases"].([]interface{})
+	if assert.NotEmpty(t, purchases, "Purchase must be in coin history") {
+		latest, _ := purchases[0].(map[string]interface{})
+		assert.Equal(t, itemName, latest["item"])
+		assert.Equal(t, float64(1), latest["quantity"])
+		assert.Equal(t, float64(merchPrice), latest["unitPrice"])
+	}
 }
@@ -0,0 +1,121 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/config"
+	"github.com/avito-shop-service/internal/repository"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestE2E_CoinExpiry ages the coins of a user in the database of the service and runs the expiry
+// job against it, since a year cannot pass during the test.
+func TestE2E_CoinExpiry(t *testing.T) {
+	cfg, err := config.LoadConfig(os.Getenv("TEST_MODE") == "true")
+	require.NoError(t, err)
+	db, err := pgxpool.New(context.Background(), cfg.DatabaseURL)
+	require.NoError(t, err)
+	defer db.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	suffix := time.Now().UnixNano()
+	holder := fmt.Sprintf("expiryholder%d", suffix)
+	friend := fmt.Sprintf("expiryfriend%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	holderToken := login(holder)
+	friendToken := login(friend)
+
+	do := func(token, method, path, body string) int {
+		req, err := http.NewRequest(method, baseURL+path, bytes.NewReader([]byte(body)))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	type expiration struct {
+		Amount    int       `json:"amount"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	info := func(token string) (int, []expiration) {
+		req, err := http.NewRequest("GET", baseURL+"/info", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var body struct {
+			Coins         int          `json:"coins"`
+			ExpiringCoins []expiration `json:"expiringCoins"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.Coins, body.ExpiringCoins
+	}
+
+	// The welcome grant of the holder was made 13 months ago.
+	_, err = db.Exec(context.Background(), `UPDATE coin_lots SET granted_at = granted_at - INTERVAL '13 months'
+        WHERE user_id = (SELECT id FROM users WHERE username = $1)`, holder)
+	require.NoError(t, err)
+
+	// Coins received keep the grant time they had with the sender; spending takes the oldest first.
+	assert.Equal(t, http.StatusOK, do(friendToken, "POST", "/sendCoin", fmt.Sprintf(`{"toUser": "%s", "amount": 300}`, holder)))
+	assert.Equal(t, http.StatusOK, do(holderToken, "GET", "/buy/pen", ""))
+	assert.Equal(t, http.StatusOK, do(holderToken, "POST", "/sendCoin", fmt.Sprintf(`{"toUser": "%s", "amount": 100}`, friend)))
+
+	// Sending coins to yourself does not renew them.
+	assert.Equal(t, http.StatusBadRequest, do(holderToken, "POST", "/sendCoin", fmt.Sprintf(`{"toUser": "%s", "amount": 500}`, holder)))
+
+	coins, expirations := info(holderToken)
+	assert.Equal(t, 1190, coins)
+	if assert.Len(t, expirations, 2) {
+		assert.Equal(t, 890, expirations[0].Amount)
+		assert.True(t, expirations[0].ExpiresAt.Before(time.Now()))
+		assert.Equal(t, 300, expirations[1].Amount)
+		assert.True(t, expirations[1].ExpiresAt.After(time.Now().AddDate(0, 11, 0)))
+	}
+	_, expirations = info(friendToken)
+	if assert.Len(t, expirations, 2) {
+		assert.Equal(t, 100, expirations[0].Amount)
+		assert.True(t, expirations[0].ExpiresAt.Before(time.Now()))
+		assert.Equal(t, 700, expirations[1].Amount)
+	}
+
+	expiry := services.NewCoinExpiryService(repository.NewCoinLotRepository(db), 12, nil)
+	require.NoError(t, expiry.ExpireCoins(context.Background()))
+
+	coins, expirations = info(holderToken)
+	assert.Equal(t, 300, coins)
+	if assert.Len(t, expirations, 1) {
+		assert.Equal(t, 300, expirations[0].Amount)
+	}
+	coins, _ = info(friendToken)
+	assert.Equal(t, 700, coins)
+
+	// Expiring again finds nothing left to expire.
+	require.NoError(t, expiry.ExpireCoins(context.Background()))
+	coins, _ = info(holderToken)
+	assert.Equal(t, 300, coins)
+}
@@ -0,0 +1,101 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_ConcurrentSpendingNeverOverdraws(t *testing.T) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	suffix := time.Now().UnixNano()
+	sender := fmt.Sprintf("racesender%d", suffix)
+	recipient := fmt.Sprintf("racerecipient%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	balance := func(token string) int {
+		req, err := http.NewRequest("GET", baseURL+"/info", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var info map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		coins, _ := info["coins"].(float64)
+		return int(coins)
+	}
+	// spend fires all requests at once and returns how many of them succeeded.
+	spend := func(requests int, newRequest func() *http.Request) int {
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		start := make(chan struct{})
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := newRequest()
+				<-start
+				resp, err := client.Do(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				resp.Body.Close()
+				assert.Contains(t, []int{http.StatusOK, http.StatusBadRequest}, resp.StatusCode)
+				if resp.StatusCode == http.StatusOK {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+		return succeeded
+	}
+
+	senderToken := login(sender)
+	recipientToken := login(recipient)
+	initial := balance(senderToken)
+
+	const transfer = 100
+	sent := spend(initial/transfer+10, func() *http.Request {
+		req, _ := http.NewRequest("POST", baseURL+"/sendCoin", bytes.NewReader([]byte(fmt.Sprintf(`{"toUser": "%s", "amount": %d}`, recipient, transfer))))
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	})
+	assert.Equal(t, initial/transfer, sent)
+	assert.Equal(t, initial-sent*transfer, balance(senderToken))
+	assert.Equal(t, initial+sent*transfer, balance(recipientToken))
+
+	const cupPrice = 20
+	recipientBalance := balance(recipientToken)
+	bought := spend(recipientBalance/cupPrice+10, func() *http.Request {
+		req, _ := http.NewRequest("GET", baseURL+"/buy/cup", nil)
+		req.Header.Set("Authorization", "Bearer "+recipientToken)
+		return req
+	})
+	assert.Equal(t, recipientBalance/cupPrice, bought)
+	assert.Equal(t, recipientBalance-bought*cupPrice, balance(recipientToken))
+}
@@ -0,0 +1,66 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_RetriedTransferIsChargedOnce(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	suffix := time.Now().UnixNano()
+	sender := fmt.Sprintf("idemsender%d", suffix)
+	recipient := fmt.Sprintf("idemrecipient%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	senderToken := login(sender)
+	login(recipient)
+
+	send := func(key string, amount int) *http.Response {
+		req, err := http.NewRequest("POST", baseURL+"/sendCoin", bytes.NewReader([]byte(fmt.Sprintf(`{"toUser": "%s", "amount": %d}`, recipient, amount))))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	first := send("transfer-1", 100)
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Empty(t, first.Header.Get("Idempotent-Replayed"))
+
+	retry := send("transfer-1", 100)
+	assert.Equal(t, http.StatusOK, retry.StatusCode)
+	assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+
+	reused := send("transfer-1", 200)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.StatusCode)
+
+	req, err := http.NewRequest("GET", baseURL+"/info", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+senderToken)
+	infoResp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer infoResp.Body.Close()
+	var info map[string]interface{}
+	assert.NoError(t, json.NewDecoder(infoResp.Body).Decode(&info))
+	assert.Equal(t, float64(900), info["coins"], "a retried transfer must be charged once")
+}
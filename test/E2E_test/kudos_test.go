@@ -0,0 +1,67 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_TransferMessageShownToBothParties(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	suffix := time.Now().UnixNano()
+	sender := fmt.Sprintf("kudossender%d", suffix)
+	recipient := fmt.Sprintf("kudosrecipient%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	senderToken := login(sender)
+	recipientToken := login(recipient)
+
+	send := func(body string) int {
+		req, err := http.NewRequest("POST", baseURL+"/sendCoin", bytes.NewReader([]byte(body)))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusBadRequest, send(fmt.Sprintf(`{"toUser": "%s", "amount": 10, "category": "no such category"}`, recipient)))
+	assert.Equal(t, http.StatusOK, send(fmt.Sprintf(`{"toUser": "%s", "amount": 10, "message": "thanks for the review", "category": "great review"}`, recipient)))
+
+	history := func(token, direction string) []interface{} {
+		req, err := http.NewRequest("GET", baseURL+"/info", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var info struct {
+			CoinHistory map[string][]interface{} `json:"coinHistory"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info.CoinHistory[direction]
+	}
+	for _, items := range [][]interface{}{history(senderToken, "sent"), history(recipientToken, "received")} {
+		if assert.Len(t, items, 1) {
+			item := items[0].(map[string]interface{})
+			assert.Equal(t, "thanks for the review", item["message"])
+			assert.Equal(t, "great review", item["category"])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const usernameLockout = "lockoutuser"
+const passwordLockout = "lockout"
+
+func TestE2E_FailedLoginsAreThrottled(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	login := func(password string) *http.Response {
+		resp, err := client.Post(baseURL+"/login", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "%s"}`, usernameLockout, password))))
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	registerResp, err := client.Post(baseURL+"/register", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "%s"}`, usernameLockout, passwordLockout))))
+	assert.NoError(t, err)
+	registerResp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, login("wrong").StatusCode)
+
+	blockedResp := login(passwordLockout)
+	assert.Equal(t, http.StatusTooManyRequests, blockedResp.StatusCode, "correct password must wait for the backoff")
+	assert.NotEmpty(t, blockedResp.Header.Get("Retry-After"))
+
+	time.Sleep(1100 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, login(passwordLockout).StatusCode)
+}
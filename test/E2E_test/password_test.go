@@ -0,0 +1,59 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_ChangePasswordEndsSessions(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	// The password changes, so every run registers its own user.
+	usernamePassword := fmt.Sprintf("passworduser%d", time.Now().UnixNano())
+
+	registerResp, err := client.Post(baseURL+"/register", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "old"}`, usernamePassword))))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, registerResp.StatusCode)
+	var authData map[string]interface{}
+	assert.NoError(t, json.NewDecoder(registerResp.Body).Decode(&authData))
+	registerResp.Body.Close()
+	token, _ := authData["token"].(string)
+	refreshToken, _ := authData["refreshToken"].(string)
+
+	// Tokens are issued with second precision, so the old token must be from an earlier second.
+	time.Sleep(time.Second)
+
+	req, err := http.NewRequest("POST", baseURL+"/me/password", bytes.NewReader([]byte(`{"oldPassword": "old", "newPassword": "new"}`)))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	changeResp, err := client.Do(req)
+	assert.NoError(t, err)
+	changeResp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, changeResp.StatusCode)
+
+	req, err = http.NewRequest("GET", baseURL+"/info", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	infoResp, err := client.Do(req)
+	assert.NoError(t, err)
+	infoResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, infoResp.StatusCode, "access token issued before the change must be rejected")
+
+	refreshResp, err := client.Post(baseURL+"/auth/refresh", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"refreshToken": "%s"}`, refreshToken))))
+	assert.NoError(t, err)
+	refreshResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, refreshResp.StatusCode, "refresh token issued before the change must be rejected")
+
+	loginResp, err := client.Post(baseURL+"/login", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "new"}`, usernamePassword))))
+	assert.NoError(t, err)
+	loginResp.Body.Close()
+	assert.Equal(t, http.StatusOK, loginResp.StatusCode)
+}
@@ -0,0 +1,69 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_UpdateOwnProfile(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	username := fmt.Sprintf("profileuser%d", time.Now().UnixNano())
+
+	authResp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, username))))
+	assert.NoError(t, err)
+	var authData map[string]interface{}
+	assert.NoError(t, json.NewDecoder(authResp.Body).Decode(&authData))
+	authResp.Body.Close()
+	token, _ := authData["token"].(string)
+
+	patch := func(body string) *http.Response {
+		req, err := http.NewRequest("PATCH", baseURL+"/me", bytes.NewReader([]byte(body)))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := patch(`{"displayName": "Profile User", "hireDate": "2024-03-01"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = patch(`{"status": "terminated"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "employees must not change their own status")
+
+	resp = patch(`{"department": "Platform"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "employees must not change their own department")
+
+	resp = patch(`{"hireDate": "yesterday"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, err := http.NewRequest("GET", baseURL+"/me", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	profileResp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer profileResp.Body.Close()
+	assert.Equal(t, http.StatusOK, profileResp.StatusCode)
+
+	var profile map[string]interface{}
+	assert.NoError(t, json.NewDecoder(profileResp.Body).Decode(&profile))
+	assert.Equal(t, username, profile["username"])
+	assert.Equal(t, "Profile User", profile["displayName"])
+	assert.Equal(t, "", profile["department"])
+	assert.Equal(t, "2024-03-01", profile["hireDate"])
+	assert.Equal(t, "active", profile["status"])
+}
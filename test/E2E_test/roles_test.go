@@ -0,0 +1,42 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const usernameRoles = "rolesuser"
+const passwordRoles = "roles"
+
+func TestE2E_AdminEndpointRequiresRole(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	authResp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "%s"}`, usernameRoles, passwordRoles))))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, authResp.StatusCode)
+	defer authResp.Body.Close()
+
+	var authData map[string]interface{}
+	err = json.NewDecoder(authResp.Body).Decode(&authData)
+	assert.NoError(t, err)
+	token, _ := authData["token"].(string)
+	assert.NotEmpty(t, token)
+
+	req, err := http.NewRequest("POST", baseURL+"/admin/merch", bytes.NewReader([]byte(`{"name": "sticker", "price": 5}`)))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,74 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_ScheduledTransferLifecycle(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	suffix := time.Now().UnixNano()
+	lead := fmt.Sprintf("schedulelead%d", suffix)
+	report := fmt.Sprintf("schedulereport%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	leadToken := login(lead)
+	login(report)
+
+	do := func(method, path, body string, out interface{}) int {
+		req, err := http.NewRequest(method, baseURL+path, bytes.NewReader([]byte(body)))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+leadToken)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		if out != nil {
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do("POST", "/scheduled-transfers", fmt.Sprintf(`{"toUser": "%s", "amount": 10, "schedule": "every monday"}`, report), nil))
+
+	var created struct {
+		ID        int64     `json:"id"`
+		Active    bool      `json:"active"`
+		NextRunAt time.Time `json:"nextRunAt"`
+	}
+	status := do("POST", "/scheduled-transfers", fmt.Sprintf(`{"toUser": "%s", "amount": 10, "category": "teamwork", "schedule": "0 9 * * 1"}`, report), &created)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.True(t, created.Active)
+	assert.Equal(t, time.Monday, created.NextRunAt.Weekday())
+
+	var listed []map[string]interface{}
+	assert.Equal(t, http.StatusOK, do("GET", "/scheduled-transfers", "", &listed))
+	assert.Len(t, listed, 1)
+
+	var paused map[string]interface{}
+	assert.Equal(t, http.StatusOK, do("PATCH", fmt.Sprintf("/scheduled-transfers/%d", created.ID), `{"active": false}`, &paused))
+	assert.Equal(t, false, paused["active"])
+
+	var runs []interface{}
+	assert.Equal(t, http.StatusOK, do("GET", fmt.Sprintf("/scheduled-transfers/%d/runs", created.ID), "", &runs))
+	assert.Empty(t, runs)
+
+	assert.Equal(t, http.StatusNoContent, do("DELETE", fmt.Sprintf("/scheduled-transfers/%d", created.ID), "", nil))
+	assert.Equal(t, http.StatusNotFound, do("GET", fmt.Sprintf("/scheduled-transfers/%d", created.ID), "", nil))
+}
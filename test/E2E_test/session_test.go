@@ -0,0 +1,88 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_RevokeSessionFromAnotherDevice(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	username := fmt.Sprintf("sessionuser%d", time.Now().UnixNano())
+	credentials := fmt.Sprintf(`{"username": "%s", "password": "secret"}`, username)
+
+	login := func(userAgent string) (string, string) {
+		req, err := http.NewRequest("POST", baseURL+"/auth", bytes.NewReader([]byte(credentials)))
+		assert.NoError(t, err)
+		req.Header.Set("User-Agent", userAgent)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		refreshToken, _ := authData["refreshToken"].(string)
+		return token, refreshToken
+	}
+	laptopToken, _ := login("laptop")
+	phoneToken, phoneRefreshToken := login("phone")
+
+	req, err := http.NewRequest("GET", baseURL+"/me/sessions", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+laptopToken)
+	sessionsResp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, sessionsResp.StatusCode)
+	var sessions []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(sessionsResp.Body).Decode(&sessions))
+	sessionsResp.Body.Close()
+	assert.Len(t, sessions, 2)
+
+	var phoneSessionID float64
+	for _, session := range sessions {
+		if session["userAgent"] == "phone" {
+			phoneSessionID, _ = session["id"].(float64)
+			assert.NotContains(t, session, "current")
+		} else {
+			assert.Equal(t, true, session["current"])
+		}
+	}
+
+	req, err = http.NewRequest("DELETE", fmt.Sprintf("%s/me/sessions/%d", baseURL, int64(phoneSessionID)), nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+laptopToken)
+	revokeResp, err := client.Do(req)
+	assert.NoError(t, err)
+	revokeResp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, revokeResp.StatusCode)
+
+	req, err = http.NewRequest("GET", baseURL+"/info", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+phoneToken)
+	infoResp, err := client.Do(req)
+	assert.NoError(t, err)
+	infoResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, infoResp.StatusCode, "access token of a revoked session must be rejected")
+
+	refreshResp, err := client.Post(baseURL+"/auth/refresh", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"refreshToken": "%s"}`, phoneRefreshToken))))
+	assert.NoError(t, err)
+	refreshResp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, refreshResp.StatusCode, "refresh token of a revoked session must be rejected")
+
+	req, err = http.NewRequest("GET", baseURL+"/info", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+laptopToken)
+	infoResp, err = client.Do(req)
+	assert.NoError(t, err)
+	infoResp.Body.Close()
+	assert.Equal(t, http.StatusOK, infoResp.StatusCode, "other sessions must stay active")
+}
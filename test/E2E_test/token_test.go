@@ -0,0 +1,68 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const usernameToken = "tokenuser"
+const passwordToken = "token"
+
+func TestE2E_RefreshAndLogout(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	authResp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "%s"}`, usernameToken, passwordToken))))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, authResp.StatusCode)
+
+	var authData map[string]interface{}
+	err = json.NewDecoder(authResp.Body).Decode(&authData)
+	assert.NoError(t, err)
+	refreshToken, _ := authData["refreshToken"].(string)
+	assert.NotEmpty(t, refreshToken)
+
+	refreshResp, err := client.Post(baseURL+"/auth/refresh", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"refreshToken": "%s"}`, refreshToken))))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, refreshResp.StatusCode)
+
+	var refreshData map[string]interface{}
+	err = json.NewDecoder(refreshResp.Body).Decode(&refreshData)
+	assert.NoError(t, err)
+	token, _ := refreshData["token"].(string)
+	newRefreshToken, _ := refreshData["refreshToken"].(string)
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, refreshToken, newRefreshToken)
+
+	reusedResp, err := client.Post(baseURL+"/auth/refresh", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"refreshToken": "%s"}`, refreshToken))))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, reusedResp.StatusCode, "rotated refresh token must not be accepted twice")
+
+	authResp, err = client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "%s"}`, usernameToken, passwordToken))))
+	assert.NoError(t, err)
+	err = json.NewDecoder(authResp.Body).Decode(&authData)
+	assert.NoError(t, err)
+	token, _ = authData["token"].(string)
+
+	reqLogout, err := http.NewRequest("POST", baseURL+"/auth/logout", nil)
+	assert.NoError(t, err)
+	reqLogout.Header.Set("Authorization", "Bearer "+token)
+	logoutResp, err := client.Do(reqLogout)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, logoutResp.StatusCode)
+
+	reqInfo, err := http.NewRequest("GET", baseURL+"/info", nil)
+	assert.NoError(t, err)
+	reqInfo.Header.Set("Authorization", "Bearer "+token)
+	infoResp, err := client.Do(reqInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, infoResp.StatusCode, "token must be rejected after logout")
+}
@@ -0,0 +1,87 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_PaginatedTransactionHistory(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	suffix := time.Now().UnixNano()
+	sender := fmt.Sprintf("historysender%d", suffix)
+	recipient := fmt.Sprintf("historyrecipient%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	senderToken := login(sender)
+	login(recipient)
+
+	for _, amount := range []int{10, 20, 30} {
+		req, err := http.NewRequest("POST", baseURL+"/sendCoin", bytes.NewReader([]byte(fmt.Sprintf(`{"toUser": "%s", "amount": %d}`, recipient, amount))))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	type page struct {
+		Transactions []struct {
+			Type        string `json:"type"`
+			Direction   string `json:"direction"`
+			Counterpart string `json:"counterpart"`
+			Amount      int    `json:"amount"`
+		} `json:"transactions"`
+		NextCursor string `json:"nextCursor"`
+	}
+	fetch := func(query url.Values) page {
+		req, err := http.NewRequest("GET", baseURL+"/transactions?"+query.Encode(), nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+senderToken)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var p page
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+		return p
+	}
+
+	first := fetch(url.Values{"type": {"transfer"}, "counterpart": {recipient}, "limit": {"2"}})
+	if assert.Len(t, first.Transactions, 2) {
+		assert.Equal(t, 30, first.Transactions[0].Amount)
+		assert.Equal(t, 20, first.Transactions[1].Amount)
+		assert.Equal(t, "sent", first.Transactions[0].Direction)
+	}
+	assert.NotEmpty(t, first.NextCursor)
+
+	second := fetch(url.Values{"type": {"transfer"}, "counterpart": {recipient}, "limit": {"2"}, "cursor": {first.NextCursor}})
+	if assert.Len(t, second.Transactions, 1) {
+		assert.Equal(t, 10, second.Transactions[0].Amount)
+	}
+	assert.Empty(t, second.NextCursor)
+
+	grants := fetch(url.Values{"direction": {"received"}, "order": {"asc"}})
+	if assert.Len(t, grants.Transactions, 1) {
+		assert.Equal(t, "grant", grants.Transactions[0].Type)
+		assert.Equal(t, "issuance", grants.Transactions[0].Counterpart)
+	}
+}
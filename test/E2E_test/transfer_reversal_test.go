@@ -0,0 +1,90 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2E_CancelTransfer(t *testing.T) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	suffix := time.Now().UnixNano()
+	sender := fmt.Sprintf("cancelsender%d", suffix)
+	recipient := fmt.Sprintf("cancelrecipient%d", suffix)
+
+	login := func(name string) string {
+		resp, err := client.Post(baseURL+"/auth", "application/json", bytes.NewReader([]byte(fmt.Sprintf(`{"username": "%s", "password": "secret"}`, name))))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		var authData map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&authData))
+		token, _ := authData["token"].(string)
+		return token
+	}
+	senderToken := login(sender)
+	recipientToken := login(recipient)
+
+	do := func(token, method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, baseURL+path, bytes.NewReader([]byte(body)))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+	// send transfers 30 coins to the recipient and returns the id of the transfer.
+	send := func() int64 {
+		resp := do(senderToken, "POST", "/sendCoin", fmt.Sprintf(`{"toUser": "%s", "amount": 30}`, recipient))
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = do(senderToken, "GET", "/transactions?type=transfer&limit=1", "")
+		defer resp.Body.Close()
+		var page struct {
+			Transactions []struct {
+				EntryID int64 `json:"entryId"`
+			} `json:"transactions"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		if !assert.Len(t, page.Transactions, 1) {
+			return 0
+		}
+		return page.Transactions[0].EntryID
+	}
+	cancel := func(token string, id int64) int {
+		resp := do(token, "POST", fmt.Sprintf("/transfers/%d/cancel", id), "")
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	coins := func(token string) int {
+		resp := do(token, "GET", "/info", "")
+		defer resp.Body.Close()
+		var info struct {
+			Coins int `json:"coins"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		return info.Coins
+	}
+
+	id := send()
+	assert.Equal(t, http.StatusNotFound, cancel(recipientToken, id))
+	assert.Equal(t, http.StatusOK, cancel(senderToken, id))
+	assert.Equal(t, http.StatusConflict, cancel(senderToken, id))
+	assert.Equal(t, 1000, coins(senderToken))
+	assert.Equal(t, 1000, coins(recipientToken))
+
+	// Once the recipient has spent coins the transfer can no longer be cancelled.
+	id = send()
+	resp := do(recipientToken, "GET", "/buy/pen", "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusConflict, cancel(senderToken, id))
+}
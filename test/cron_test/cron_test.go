@@ -0,0 +1,59 @@
+//go:build unit
+// +build unit
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/pkg/cron"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Next(t *testing.T) {
+	// Thursday.
+	from := time.Date(2026, 10, 1, 12, 30, 45, 0, time.UTC)
+
+	for _, test := range []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 1, 12, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 1, 12, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1", time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * MON", time.Time{}},
+		{"0 18 * * 1-5", time.Date(2026, 10, 1, 18, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC)},
+		{"30 10 1,15 * *", time.Date(2026, 10, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		schedule, err := cron.Parse(test.expr)
+		if test.want.IsZero() {
+			assert.ErrorIs(t, err, cron.ErrInvalidSchedule, test.expr)
+			continue
+		}
+		if assert.NoError(t, err, test.expr) {
+			assert.Equal(t, test.want, schedule.Next(from), test.expr)
+		}
+	}
+}
+
+func TestParse_NextIsStrictlyAfter(t *testing.T) {
+	schedule, err := cron.Parse("0 9 * * *")
+	assert.NoError(t, err)
+
+	at := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, at.AddDate(0, 0, 1), schedule.Next(at))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *", "0 0 30 2 *", "@every"} {
+		_, err := cron.Parse(expr)
+		assert.ErrorIs(t, err, cron.ErrInvalidSchedule, expr)
+	}
+}
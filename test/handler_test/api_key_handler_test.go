@@ -0,0 +1,183 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyHandler_CreateAPIKey_Success(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	handler := handlers.NewAPIKeyHandler(mockAPIKeyService)
+
+	mockAPIKeyService.On("CreateAPIKey", mock.Anything, "testuser", models.APIKey{Name: "review-bot", Scopes: []string{"coins:send"}, RateLimit: 10}).
+		Return(&models.CreatedAPIKey{APIKey: models.APIKey{ID: 1, Name: "review-bot", Prefix: "ask_abcdefgh"}, Key: "ask_abcdefghsecret"}, nil)
+
+	req := httptest.NewRequest("POST", "/api/me/api-keys", strings.NewReader(`{"name": "review-bot", "scopes": ["coins:send"], "rateLimit": 10}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.CreateAPIKey(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "ask_abcdefghsecret", response["key"])
+	assert.Equal(t, "ask_abcdefgh", response["prefix"])
+}
+
+func TestAPIKeyHandler_CreateAPIKey_InvalidScopes(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	handler := handlers.NewAPIKeyHandler(mockAPIKeyService)
+
+	mockAPIKeyService.On("CreateAPIKey", mock.Anything, "testuser", mock.Anything).Return(nil, models.ErrInvalidScopes)
+
+	req := httptest.NewRequest("POST", "/api/me/api-keys", strings.NewReader(`{"name": "bot", "scopes": ["coins:admin"]}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.CreateAPIKey(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIKeyHandler_CreateAPIKey_RateLimitTooHigh(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	handler := handlers.NewAPIKeyHandler(mockAPIKeyService)
+
+	mockAPIKeyService.On("CreateAPIKey", mock.Anything, "testuser", mock.Anything).
+		Return(nil, fmt.Errorf("%w: must be at most 60 requests per minute", models.ErrInvalidRateLimit))
+
+	req := httptest.NewRequest("POST", "/api/me/api-keys", strings.NewReader(`{"name": "bot", "scopes": ["coins:send"], "rateLimit": 1000000}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.CreateAPIKey(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIKeyHandler_CreateAPIKey_InvalidInput(t *testing.T) {
+	handler := handlers.NewAPIKeyHandler(new(MockAPIKeyService))
+
+	req := httptest.NewRequest("POST", "/api/me/api-keys", strings.NewReader(`{"name": ""}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.CreateAPIKey(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPIKeyHandler_GetAPIKeys(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	handler := handlers.NewAPIKeyHandler(mockAPIKeyService)
+
+	lastUsed := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockAPIKeyService.On("GetAPIKeys", mock.Anything, "testuser").Return([]models.APIKey{{ID: 1, Name: "bot", KeyHash: "secret-hash", LastUsedAt: &lastUsed}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/me/api-keys", nil)
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.GetAPIKeys(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"lastUsedAt":"2026-10-01T12:00:00Z"`)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+}
+
+func TestAPIKeyHandler_RevokeAPIKey(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	handler := handlers.NewAPIKeyHandler(mockAPIKeyService)
+
+	mockAPIKeyService.On("RevokeAPIKey", mock.Anything, "testuser", int64(1)).Return(nil)
+	mockAPIKeyService.On("RevokeAPIKey", mock.Anything, "testuser", int64(2)).Return(models.ErrAPIKeyNotFound)
+
+	r := chi.NewRouter()
+	r.Delete("/api/me/api-keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.RevokeAPIKey(w, r.WithContext(setEmployeeUsername(r.Context(), "testuser")))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/me/api-keys/1", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/me/api-keys/2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/me/api-keys/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	mockAPIKeyService := new(MockAPIKeyService)
+	authMiddleware := middleware.NewAuthMiddleware(mockTokenService, mockAPIKeyService)
+
+	mockAPIKeyService.On("ValidateAPIKey", mock.Anything, "ask_valid").Return(&auth.Claims{EmployeeUsername: "bot-owner", APIKeyID: 3}, nil)
+
+	req := httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("X-Api-Key", "ask_valid")
+	w := httptest.NewRecorder()
+
+	authMiddleware.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, ok := middleware.GetEmployeeUsername(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "bot-owner", username)
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockTokenService.AssertNotCalled(t, "ValidateAccessToken", mock.Anything, mock.Anything)
+}
+
+func TestAuthMiddleware_APIKeyErrors(t *testing.T) {
+	mockAPIKeyService := new(MockAPIKeyService)
+	authMiddleware := middleware.NewAuthMiddleware(new(MockTokenService), mockAPIKeyService)
+
+	mockAPIKeyService.On("ValidateAPIKey", mock.Anything, "ask_revoked").Return(nil, models.ErrInvalidAPIKey)
+	mockAPIKeyService.On("ValidateAPIKey", mock.Anything, "ask_busy").Return(nil, &models.RateLimitedError{RetryAfter: 30 * time.Second})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler must not be called")
+	})
+
+	req := httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("X-Api-Key", "ask_revoked")
+	w := httptest.NewRecorder()
+	authMiddleware.Handle(next).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("X-Api-Key", "ask_busy")
+	w = httptest.NewRecorder()
+	authMiddleware.Handle(next).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+}
+
+func TestRequireToken_RejectsAPIKey(t *testing.T) {
+	w := serveWithClaims(func(next http.Handler) http.Handler { return middleware.RequireToken(next) }, &auth.Claims{APIKeyID: 3})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveWithClaims(func(next http.Handler) http.Handler { return middleware.RequireToken(next) }, &auth.Claims{EmployeeUsername: "testuser"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
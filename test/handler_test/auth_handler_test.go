@@ -6,43 +6,52 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/avito-shop-service/internal/handlers"
 	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestUserHandler_Auth_Success(t *testing.T) {
 	mockUserService := new(MockUserService)
-	mockUserService.On("Authenticate", mock.Anything, "testuser", "password123").Return("test-token", nil)
+	mockUserService.On("Authenticate", mock.Anything, "testuser", "password123", mock.Anything).Return(&models.AuthTokens{AccessToken: "test-token", RefreshToken: "refresh-token"}, nil)
 	mockUserService.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{Username: "testuser"}, nil)
 
-	handler := handlers.NewUserHandler(mockUserService)
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
 	reqBody := []byte(`{"username":"testuser","password":"password123"}`)
 	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader(reqBody))
 	rr := httptest.NewRecorder()
 
 	handler.Auth(rr, req)
 	assert.Equal(t, http.StatusOK, rr.Code)
-	var response map[string]string
+	var response map[string]interface{}
 	err := json.NewDecoder(rr.Body).Decode(&response)
 	assert.NoError(t, err)
 	assert.Equal(t, "test-token", response["token"])
+	assert.Equal(t, "refresh-token", response["refreshToken"])
 }
 
 func TestUserHandler_Auth_Error_InvalidInput(t *testing.T) {
 	mockUserService := new(MockUserService)
+	mockUserService.On("RecordFailedLogin", mock.Anything, "", mock.Anything, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, models.ErrInvalidLoginRequest)
+	})).Return()
 
-	handler := handlers.NewUserHandler(mockUserService)
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
 	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader([]byte(`{`)))
 	rr := httptest.NewRecorder()
 
 	handler.Auth(rr, req)
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockUserService.AssertExpectations(t)
 }
 
 func TestUserHandler_Auth_Error_CreatingUser(t *testing.T) {
@@ -50,7 +59,7 @@ func TestUserHandler_Auth_Error_CreatingUser(t *testing.T) {
 	mockUserService.On("GetUserByUsername", mock.Anything, "testuser").Return((*models.User)(nil), nil)
 	mockUserService.On("CreateUser", mock.Anything, "testuser", "password123").Return((*models.User)(nil), fmt.Errorf("error"))
 
-	handler := handlers.NewUserHandler(mockUserService)
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
 	reqBody := []byte(`{"username":"testuser","password":"password123"}`)
 	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader(reqBody))
 	rr := httptest.NewRecorder()
@@ -62,7 +71,7 @@ func TestUserHandler_Auth_Error_GetUserByUsername(t *testing.T) {
 	mockUserService := new(MockUserService)
 	mockUserService.On("GetUserByUsername", mock.Anything, "testuser").Return((*models.User)(nil), fmt.Errorf("database error"))
 
-	handler := handlers.NewUserHandler(mockUserService)
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
 	reqBody := []byte(`{"username":"testuser","password":"password123"}`)
 	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader(reqBody))
 	rr := httptest.NewRecorder()
@@ -76,9 +85,9 @@ func TestUserHandler_Auth_Error_GetUserByUsername(t *testing.T) {
 func TestUserHandler_Auth_Error_Authenticate(t *testing.T) {
 	mockUserService := new(MockUserService)
 	mockUserService.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{Username: "testuser"}, nil)
-	mockUserService.On("Authenticate", mock.Anything, "testuser", "password123").Return("", fmt.Errorf("invalid password"))
+	mockUserService.On("Authenticate", mock.Anything, "testuser", "password123", mock.Anything).Return((*models.AuthTokens)(nil), models.ErrInvalidCredentials)
 
-	handler := handlers.NewUserHandler(mockUserService)
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
 	reqBody := []byte(`{"username":"testuser","password":"password123"}`)
 	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader(reqBody))
 	rr := httptest.NewRecorder()
@@ -87,3 +96,174 @@ func TestUserHandler_Auth_Error_Authenticate(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, rr.Code)
 }
+
+func alwaysProvision(t *testing.T) *services.ProvisioningPolicy {
+	policy, err := services.NewProvisioningPolicy("always", nil, "")
+	assert.NoError(t, err)
+	return policy
+}
+
+func TestUserHandler_Auth_ProvisioningDisabled(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("GetUserByUsername", mock.Anything, "typo").Return((*models.User)(nil), nil)
+	mockUserService.On("Authenticate", mock.Anything, "typo", "password123", mock.Anything).Return((*models.AuthTokens)(nil), models.ErrInvalidCredentials)
+
+	policy, err := services.NewProvisioningPolicy("never", nil, "")
+	assert.NoError(t, err)
+	handler := handlers.NewUserHandler(mockUserService, policy)
+	reqBody := []byte(`{"username":"typo","password":"password123"}`)
+	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+
+	handler.Auth(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	mockUserService.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestUserHandler_Auth_ProvisioningDisabled_Throttled(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("GetUserByUsername", mock.Anything, "typo").Return((*models.User)(nil), nil)
+	mockUserService.On("Authenticate", mock.Anything, "typo", "password123", mock.Anything).
+		Return((*models.AuthTokens)(nil), &models.LoginBlockedError{RetryAfter: time.Minute})
+
+	policy, err := services.NewProvisioningPolicy("never", nil, "")
+	assert.NoError(t, err)
+	handler := handlers.NewUserHandler(mockUserService, policy)
+	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader([]byte(`{"username":"typo","password":"password123"}`)))
+	rr := httptest.NewRecorder()
+
+	handler.Auth(rr, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+}
+
+func TestUserHandler_Auth_EmptyCredentials(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("RecordFailedLogin", mock.Anything, "testuser", mock.Anything, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, models.ErrInvalidLoginRequest)
+	})).Return()
+
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
+	req := httptest.NewRequest("POST", "/api/auth", bytes.NewReader([]byte(`{"username":"testuser"}`)))
+	rr := httptest.NewRecorder()
+
+	handler.Auth(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestUserHandler_Register_ProvisioningDisabled(t *testing.T) {
+	mockUserService := new(MockUserService)
+
+	policy, err := services.NewProvisioningPolicy("never", nil, "")
+	assert.NoError(t, err)
+	handler := handlers.NewUserHandler(mockUserService, policy)
+	reqBody := []byte(`{"username":"newuser","password":"password123"}`)
+	req := httptest.NewRequest("POST", "/api/register", bytes.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+
+	handler.Register(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	mockUserService.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserHandler_Register_Success(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("CreateUser", mock.Anything, "newuser", "password123").Return(&models.User{Username: "newuser"}, nil)
+	mockUserService.On("Authenticate", mock.Anything, "newuser", "password123", mock.Anything).Return(&models.AuthTokens{AccessToken: "test-token", RefreshToken: "refresh-token"}, nil)
+
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
+	reqBody := []byte(`{"username":"newuser","password":"password123"}`)
+	req := httptest.NewRequest("POST", "/api/register", bytes.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+
+	handler.Register(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var response map[string]interface{}
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-token", response["token"])
+}
+
+func TestUserHandler_Register_Conflict(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("CreateUser", mock.Anything, "testuser", "password123").Return((*models.User)(nil), models.ErrUserAlreadyExists)
+
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
+	reqBody := []byte(`{"username":"testuser","password":"password123"}`)
+	req := httptest.NewRequest("POST", "/api/register", bytes.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+
+	handler.Register(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	mockUserService.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserHandler_Login_Success(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("Authenticate", mock.Anything, "testuser", "password123", mock.Anything).Return(&models.AuthTokens{AccessToken: "test-token", RefreshToken: "refresh-token"}, nil)
+
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
+	reqBody := []byte(`{"username":"testuser","password":"password123"}`)
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+
+	handler.Login(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockUserService.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserHandler_Login_UnknownUser(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("Authenticate", mock.Anything, "typo", "password123", mock.Anything).Return((*models.AuthTokens)(nil), models.ErrInvalidCredentials)
+
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
+	reqBody := []byte(`{"username":"typo","password":"password123"}`)
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewReader(reqBody))
+	rr := httptest.NewRecorder()
+
+	handler.Login(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	mockUserService.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserHandler_Login_Blocked(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("Authenticate", mock.Anything, "testuser", "password123", models.ClientInfo{IP: "192.0.2.1"}).Return((*models.AuthTokens)(nil), &models.LoginBlockedError{RetryAfter: 1500 * time.Millisecond})
+
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewReader([]byte(`{"username":"testuser","password":"password123"}`)))
+	rr := httptest.NewRecorder()
+
+	handler.Login(rr, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+}
+
+func TestUserHandler_Unlock(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockUserService.On("UnlockUser", mock.Anything, "testuser").Return(nil)
+	mockUserService.On("UnlockUser", mock.Anything, "ghost").Return(models.ErrUserNotFound)
+
+	handler := handlers.NewUserHandler(mockUserService, alwaysProvision(t))
+	r := chi.NewRouter()
+	r.Post("/api/admin/users/{username}/unlock", handler.Unlock)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/users/testuser/unlock", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("POST", "/api/admin/users/ghost/unlock", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
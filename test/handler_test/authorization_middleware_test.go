@@ -0,0 +1,59 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithClaims(h func(http.Handler) http.Handler, claims *auth.Claims) *httptest.ResponseRecorder {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if claims != nil {
+		req = req.WithContext(setClaims(req.Context(), claims))
+	}
+	w := httptest.NewRecorder()
+	h(next).ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireRole_Allowed(t *testing.T) {
+	w := serveWithClaims(middleware.RequireRole(auth.RoleMerchAdmin, auth.RoleFinanceAdmin), &auth.Claims{Roles: []string{auth.RoleFinanceAdmin}})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireRole_Forbidden(t *testing.T) {
+	w := serveWithClaims(middleware.RequireRole(auth.RoleMerchAdmin), &auth.Claims{Roles: []string{auth.RoleEmployee}})
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequireRole_NoClaims(t *testing.T) {
+	w := serveWithClaims(middleware.RequireRole(auth.RoleMerchAdmin), nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequireScope_Allowed(t *testing.T) {
+	w := serveWithClaims(middleware.RequireScope(auth.ScopeMerchWrite), &auth.Claims{Scopes: []string{auth.ScopeInfoRead, auth.ScopeMerchWrite}})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireScope_MissingScope(t *testing.T) {
+	w := serveWithClaims(middleware.RequireScope(auth.ScopeInfoRead, auth.ScopeMerchWrite), &auth.Claims{Scopes: []string{auth.ScopeInfoRead}})
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), auth.ScopeMerchWrite)
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -88,10 +89,13 @@ func TestBuyHandler_Buy_NotEnoughCoins(t *testing.T) {
 
 	mockMerchService.On("GetMerchByName", req.Context(), "item1").Return(&models.Merch{ID: 1, ItemName: "item1", Price: 100}, nil)
 	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser", Coins: 50}, nil)
+	mockInventoryService.On("BuyItemToInventory", req.Context(), int64(1), int64(1), 1, 100).
+		Return(fmt.Errorf("error adding item to inventory: %w", &models.InsufficientFundsError{Balance: 50, Required: 100}))
 
 	handler.Buy(w, req)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "not enough coins")
 }
 
 func TestBuyHandler_Buy_ErrorFetchingUser(t *testing.T) {
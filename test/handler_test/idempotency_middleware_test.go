@@ -0,0 +1,125 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newIdempotentRequest(body string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/sendCoin", strings.NewReader(body))
+	req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+	return req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+}
+
+func TestIdempotencyMiddleware_StoresFirstResponse(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	idempotency := middleware.NewIdempotencyMiddleware(mockService)
+
+	mockService.On("Begin", mock.Anything, "testuser", "key-1", mock.AnythingOfType("string")).Return(nil, nil)
+	mockService.On("Complete", mock.Anything, mock.MatchedBy(func(record *models.IdempotencyRecord) bool {
+		return record.Key == "key-1" && record.StatusCode == http.StatusOK && string(record.Body) == "sent"
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	idempotency.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"amount": 10}`, string(body), "the handler must still see the body")
+		_, _ = w.Write([]byte("sent"))
+	})).ServeHTTP(w, newIdempotentRequest(`{"amount": 10}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotencyMiddleware_ReplaysStoredResponse(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	idempotency := middleware.NewIdempotencyMiddleware(mockService)
+
+	mockService.On("Begin", mock.Anything, "testuser", "key-1", mock.AnythingOfType("string")).
+		Return(&models.IdempotencyRecord{StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte(`{"message":"send successful"}`)}, nil)
+
+	w := httptest.NewRecorder()
+	idempotency.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("a replayed request must not be processed again")
+	})).ServeHTTP(w, newIdempotentRequest(`{"amount": 10}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"message":"send successful"}`, w.Body.String())
+}
+
+func TestIdempotencyMiddleware_FingerprintCoversPayload(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	idempotency := middleware.NewIdempotencyMiddleware(mockService)
+
+	var fingerprints []string
+	mockService.On("Begin", mock.Anything, "testuser", "key-1", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		fingerprints = append(fingerprints, args.String(3))
+	}).Return(nil, models.ErrIdempotencyMismatch)
+
+	for _, body := range []string{`{"amount": 10}`, `{"amount": 10}`, `{"amount": 20}`} {
+		w := httptest.NewRecorder()
+		idempotency.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, newIdempotentRequest(body))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	}
+	assert.Equal(t, fingerprints[0], fingerprints[1])
+	assert.NotEqual(t, fingerprints[0], fingerprints[2])
+}
+
+func TestIdempotencyMiddleware_ServerErrorIsStored(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	idempotency := middleware.NewIdempotencyMiddleware(mockService)
+
+	mockService.On("Begin", mock.Anything, "testuser", "key-1", mock.AnythingOfType("string")).Return(nil, nil)
+	mockService.On("Complete", mock.Anything, mock.MatchedBy(func(record *models.IdempotencyRecord) bool {
+		return record.Key == "key-1" && record.StatusCode == http.StatusInternalServerError
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	idempotency.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database error", http.StatusInternalServerError)
+	})).ServeHTTP(w, newIdempotentRequest(`{}`))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestIdempotencyMiddleware_InProgress(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	idempotency := middleware.NewIdempotencyMiddleware(mockService)
+
+	mockService.On("Begin", mock.Anything, "testuser", "key-1", mock.AnythingOfType("string")).Return(nil, models.ErrIdempotencyConflict)
+
+	w := httptest.NewRecorder()
+	idempotency.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler must not be called")
+	})).ServeHTTP(w, newIdempotentRequest(`{}`))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestIdempotencyMiddleware_WithoutKey(t *testing.T) {
+	mockService := new(MockIdempotencyService)
+	idempotency := middleware.NewIdempotencyMiddleware(mockService)
+
+	req := httptest.NewRequest("POST", "/api/sendCoin", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	idempotency.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertNotCalled(t, "Begin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestInformationHandler_GetInfo_Success(t *testing.T) {
@@ -18,8 +19,9 @@ func TestInformationHandler_GetInfo_Success(t *testing.T) {
 	mockMerchService := new(MockMerchService)
 	mockInventoryService := new(MockInventoryService)
 	mockTransactionService := new(MockTransactionService)
+	mockCoinExpiryService := new(MockCoinExpiryService)
 
-	handler := handlers.NewInformationHandler(mockUserService, mockMerchService, mockInventoryService, mockTransactionService)
+	handler := handlers.NewInformationHandler(mockUserService, mockMerchService, mockInventoryService, mockTransactionService, mockCoinExpiryService)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
@@ -27,7 +29,7 @@ func TestInformationHandler_GetInfo_Success(t *testing.T) {
 
 	user := &models.User{ID: 1, Username: "testuser", Coins: 500}
 	transactions := []models.CoinTransaction{
-		{TransactionType: "received", CounterpartUser: "user1", Amount: 100},
+		{TransactionType: "received", CounterpartUser: "user1", Amount: 100, Message: "thanks!", Category: "great review"},
 		{TransactionType: "sent", CounterpartUser: "user2", Amount: 50},
 	}
 	inventory := []models.Inventory{
@@ -42,6 +44,11 @@ func TestInformationHandler_GetInfo_Success(t *testing.T) {
 	mockInventoryService.On("GetInventoryByUserID", req.Context(), user.ID).Return(inventory, nil)
 	mockMerchService.On("GetMerchByID", req.Context(), int64(1)).Return(merch1, nil)
 	mockMerchService.On("GetMerchByID", req.Context(), int64(2)).Return(merch2, nil)
+	mockInventoryService.On("GetPurchasesByUserID", req.Context(), user.ID).Return([]models.Purchase{
+		{ItemID: 2, ItemName: "Mug", Quantity: 1, UnitPrice: 20},
+		{ItemID: 1, ItemName: "T-shirt", Quantity: 3, UnitPrice: 80},
+	}, nil)
+	mockCoinExpiryService.On("GetUpcomingExpirations", req.Context(), user.ID).Return([]models.CoinExpiration{}, nil)
 
 	handler.GetInfo(w, req)
 
@@ -53,8 +60,12 @@ func TestInformationHandler_GetInfo_Success(t *testing.T) {
 			{"type": "Mug", "quantity": 1}
 		],
 		"coinHistory": {
-			"received": [{"fromUser": "user1", "amount": 100}],
-			"sent": [{"toUser": "user2", "amount": 50}]
+			"received": [{"fromUser": "user1", "amount": 100, "message": "thanks!", "category": "great review"}],
+			"sent": [{"toUser": "user2", "amount": 50}],
+			"purchases": [
+				{"item": "Mug", "quantity": 1, "unitPrice": 20, "total": 20},
+				{"item": "T-shirt", "quantity": 3, "unitPrice": 80, "total": 240}
+			]
 		}
 	}`, w.Body.String())
 
@@ -65,7 +76,7 @@ func TestInformationHandler_GetInfo_Success(t *testing.T) {
 }
 
 func TestInformationHandler_GetInfo_Unauthorized(t *testing.T) {
-	handler := handlers.NewInformationHandler(nil, nil, nil, nil)
+	handler := handlers.NewInformationHandler(nil, nil, nil, nil, nil)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	w := httptest.NewRecorder()
@@ -78,7 +89,7 @@ func TestInformationHandler_GetInfo_Unauthorized(t *testing.T) {
 
 func TestInformationHandler_GetInfo_UserFetchError(t *testing.T) {
 	mockUserService := new(MockUserService)
-	handler := handlers.NewInformationHandler(mockUserService, nil, nil, nil)
+	handler := handlers.NewInformationHandler(mockUserService, nil, nil, nil, nil)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
@@ -95,7 +106,7 @@ func TestInformationHandler_GetInfo_UserFetchError(t *testing.T) {
 func TestInformationHandler_GetInfo_TransactionFetchError(t *testing.T) {
 	mockUserService := new(MockUserService)
 	mockTransactionService := new(MockTransactionService)
-	handler := handlers.NewInformationHandler(mockUserService, nil, nil, mockTransactionService)
+	handler := handlers.NewInformationHandler(mockUserService, nil, nil, mockTransactionService, nil)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
@@ -116,7 +127,7 @@ func TestInformationHandler_GetInfo_InventoryFetchError(t *testing.T) {
 	mockUserService := new(MockUserService)
 	mockTransactionService := new(MockTransactionService)
 	mockInventoryService := new(MockInventoryService)
-	handler := handlers.NewInformationHandler(mockUserService, nil, mockInventoryService, mockTransactionService)
+	handler := handlers.NewInformationHandler(mockUserService, nil, mockInventoryService, mockTransactionService, nil)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
@@ -139,7 +150,7 @@ func TestInformationHandler_GetInfo_MerchFetchError(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockInventoryService := new(MockInventoryService)
 	mockMerchService := new(MockMerchService)
-	handler := handlers.NewInformationHandler(mockUserService, mockMerchService, mockInventoryService, mockTransactionService)
+	handler := handlers.NewInformationHandler(mockUserService, mockMerchService, mockInventoryService, mockTransactionService, nil)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
@@ -163,7 +174,7 @@ func TestInformationHandler_GetInfo_MerchFetchError(t *testing.T) {
 
 func TestInformationHandler_GetInfo_UserNotFound(t *testing.T) {
 	mockUserService := new(MockUserService)
-	handler := handlers.NewInformationHandler(mockUserService, nil, nil, nil)
+	handler := handlers.NewInformationHandler(mockUserService, nil, nil, nil, nil)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
@@ -182,7 +193,7 @@ func TestInformationHandler_GetInfo_MerchNotFound(t *testing.T) {
 	mockTransactionService := new(MockTransactionService)
 	mockInventoryService := new(MockInventoryService)
 	mockMerchService := new(MockMerchService)
-	handler := handlers.NewInformationHandler(mockUserService, mockMerchService, mockInventoryService, mockTransactionService)
+	handler := handlers.NewInformationHandler(mockUserService, mockMerchService, mockInventoryService, mockTransactionService, nil)
 
 	req := httptest.NewRequest("GET", "/info", nil)
 	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
@@ -203,3 +214,54 @@ func TestInformationHandler_GetInfo_MerchNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "Merch with that id not found")
 }
+
+func TestInformationHandler_GetInfo_PurchaseFetchError(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	mockInventoryService := new(MockInventoryService)
+	handler := handlers.NewInformationHandler(mockUserService, nil, mockInventoryService, mockTransactionService, nil)
+
+	req := httptest.NewRequest("GET", "/info", nil)
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	user := &models.User{ID: 1, Username: "testuser", Coins: 500}
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(user, nil)
+	mockTransactionService.On("GetTransactionsByUserId", req.Context(), user.ID).Return([]models.CoinTransaction{}, nil)
+	mockInventoryService.On("GetInventoryByUserID", req.Context(), user.ID).Return([]models.Inventory{}, nil)
+	mockInventoryService.On("GetPurchasesByUserID", req.Context(), user.ID).Return(nil, errors.New("DB error"))
+
+	handler.GetInfo(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Error fetching purchases")
+}
+
+func TestInformationHandler_GetInfo_ExpiringCoins(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	mockInventoryService := new(MockInventoryService)
+	mockCoinExpiryService := new(MockCoinExpiryService)
+	handler := handlers.NewInformationHandler(mockUserService, nil, mockInventoryService, mockTransactionService, mockCoinExpiryService)
+
+	req := httptest.NewRequest("GET", "/info", nil)
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	user := &models.User{ID: 1, Username: "testuser", Coins: 500}
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(user, nil)
+	mockTransactionService.On("GetTransactionsByUserId", req.Context(), user.ID).Return([]models.CoinTransaction{}, nil)
+	mockInventoryService.On("GetInventoryByUserID", req.Context(), user.ID).Return([]models.Inventory{}, nil)
+	mockInventoryService.On("GetPurchasesByUserID", req.Context(), user.ID).Return([]models.Purchase{}, nil)
+	mockCoinExpiryService.On("GetUpcomingExpirations", req.Context(), user.ID).Return([]models.CoinExpiration{
+		{Amount: 300, ExpiresAt: time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)},
+		{Amount: 200, ExpiresAt: time.Date(2027, 3, 15, 12, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	handler.GetInfo(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"expiringCoins":[{"amount":300,"expiresAt":"2026-11-01T09:00:00Z"},{"amount":200,"expiresAt":"2027-03-15T12:00:00Z"}]`)
+}
@@ -0,0 +1,70 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIssuanceHandler_GrantCoins(t *testing.T) {
+	mockService := new(MockIssuanceService)
+	handler := handlers.NewIssuanceHandler(mockService)
+
+	mockService.On("Issue", mock.Anything, mock.MatchedBy(func(issuance *models.CoinIssuance) bool {
+		return issuance.Operation == models.IssuanceGrant && issuance.Username == "bob" && issuance.Amount == 100 &&
+			issuance.Reason == "hackathon winner" && issuance.IssuedBy == "admin"
+	})).Run(func(args mock.Arguments) {
+		issuance := args.Get(1).(*models.CoinIssuance)
+		issuance.Entries = []models.IssuanceEntry{{EntryID: 7, Username: "bob", Balance: 1100}}
+	}).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/coins/grant", strings.NewReader(`{"username": "bob", "amount": 100, "reason": "hackathon winner"}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "admin"))
+	w := httptest.NewRecorder()
+
+	handler.GrantCoins(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"entries":[{"entryId":7,"username":"bob","balance":1100}]`)
+	mockService.AssertExpectations(t)
+}
+
+func TestIssuanceHandler_BurnCoins_Errors(t *testing.T) {
+	mockService := new(MockIssuanceService)
+	handler := handlers.NewIssuanceHandler(mockService)
+
+	mockService.On("Issue", mock.Anything, mock.MatchedBy(func(issuance *models.CoinIssuance) bool {
+		return issuance.Operation == models.IssuanceBurn && issuance.Username == "bob"
+	})).Return(fmt.Errorf("bob: %w", &models.InsufficientFundsError{Balance: 10, Required: 100}))
+	mockService.On("Issue", mock.Anything, mock.MatchedBy(func(issuance *models.CoinIssuance) bool {
+		return issuance.Department == "nowhere"
+	})).Return(fmt.Errorf("%w: no active users in department %q", models.ErrUserNotFound, "nowhere"))
+	mockService.On("Issue", mock.Anything, mock.MatchedBy(func(issuance *models.CoinIssuance) bool {
+		return issuance.Username == "alice"
+	})).Return(fmt.Errorf("%w: reason must be 1 to 200 characters long", models.ErrInvalidIssuance))
+
+	for body, code := range map[string]int{
+		`{"username": "bob", "amount": 100, "reason": "duplicate grant"}`:       http.StatusBadRequest,
+		`{"department": "nowhere", "amount": 100, "reason": "duplicate grant"}`: http.StatusNotFound,
+		`{"username": "alice", "amount": 100}`:                                  http.StatusBadRequest,
+		`{`:                                                                     http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest("POST", "/api/admin/coins/burn", strings.NewReader(body))
+		req = req.WithContext(setEmployeeUsername(req.Context(), "admin"))
+		w := httptest.NewRecorder()
+
+		handler.BurnCoins(w, req)
+
+		assert.Equal(t, code, w.Code, body)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJWKSHandler_GetJWKS(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("test-key", privateKey)
+	assert.NoError(t, err)
+	handler := handlers.NewJWKSHandler(auth.NewJWTManager(&auth.Config{Keys: keys}, nil))
+
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetJWKS(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var jwks auth.JWKS
+	err = json.NewDecoder(w.Body).Decode(&jwks)
+	assert.NoError(t, err)
+	assert.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "test-key", jwks.Keys[0].Kid)
+	assert.Equal(t, "sig", jwks.Keys[0].Use)
+}
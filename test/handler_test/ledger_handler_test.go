@@ -0,0 +1,48 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLedgerHandler_VerifyLedger(t *testing.T) {
+	mockLedgerService := new(MockLedgerService)
+	handler := handlers.NewLedgerHandler(mockLedgerService)
+
+	mockLedgerService.On("VerifyLedger", mock.Anything).Return(&models.LedgerReport{
+		UnbalancedEntries: []int64{7},
+		Mismatches:        []models.BalanceMismatch{},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	handler.VerifyLedger(w, httptest.NewRequest("GET", "/api/admin/ledger/verify", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, false, response["balanced"])
+	assert.Equal(t, []interface{}{float64(7)}, response["unbalancedEntries"])
+}
+
+func TestLedgerHandler_VerifyLedger_Error(t *testing.T) {
+	mockLedgerService := new(MockLedgerService)
+	handler := handlers.NewLedgerHandler(mockLedgerService)
+
+	mockLedgerService.On("VerifyLedger", mock.Anything).Return(nil, errors.New("database error"))
+
+	w := httptest.NewRecorder()
+	handler.VerifyLedger(w, httptest.NewRequest("GET", "/api/admin/ledger/verify", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,73 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMerchHandler_CreateMerch_Success(t *testing.T) {
+	mockMerchService := new(MockMerchService)
+	handler := handlers.NewMerchHandler(mockMerchService)
+
+	mockMerchService.On("GetMerchByName", mock.Anything, "hoody").Return(nil, models.ErrMerchNotFound)
+	mockMerchService.On("CreateMerch", mock.Anything, &models.Merch{ItemName: "hoody", Price: 300}).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/merch", strings.NewReader(`{"name": "hoody", "price": 300}`))
+	w := httptest.NewRecorder()
+
+	handler.CreateMerch(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockMerchService.AssertExpectations(t)
+}
+
+func TestMerchHandler_CreateMerch_AlreadyExists(t *testing.T) {
+	mockMerchService := new(MockMerchService)
+	handler := handlers.NewMerchHandler(mockMerchService)
+
+	mockMerchService.On("GetMerchByName", mock.Anything, "hoody").Return(&models.Merch{ID: 1, ItemName: "hoody", Price: 300}, nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/merch", strings.NewReader(`{"name": "hoody", "price": 300}`))
+	w := httptest.NewRecorder()
+
+	handler.CreateMerch(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockMerchService.AssertNotCalled(t, "CreateMerch", mock.Anything, mock.Anything)
+}
+
+func TestMerchHandler_CreateMerch_InvalidInput(t *testing.T) {
+	handler := handlers.NewMerchHandler(new(MockMerchService))
+
+	req := httptest.NewRequest("POST", "/api/admin/merch", strings.NewReader(`{"name": "", "price": 300}`))
+	w := httptest.NewRecorder()
+
+	handler.CreateMerch(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMerchHandler_CreateMerch_LookupError(t *testing.T) {
+	mockMerchService := new(MockMerchService)
+	handler := handlers.NewMerchHandler(mockMerchService)
+
+	mockMerchService.On("GetMerchByName", mock.Anything, "hoody").Return(nil, errors.New("db error"))
+
+	req := httptest.NewRequest("POST", "/api/admin/merch", strings.NewReader(`{"name": "hoody", "price": 300}`))
+	w := httptest.NewRecorder()
+
+	handler.CreateMerch(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/avito-shop-service/internal/middleware"
 	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/pkg/auth"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,6 +12,11 @@ func setEmployeeUsername(ctx context.Context, username string) context.Context {
 	return context.WithValue(ctx, middleware.EmployeeUsernameKey, username)
 }
 
+func setClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	ctx = context.WithValue(ctx, middleware.EmployeeUsernameKey, claims.EmployeeUsername)
+	return context.WithValue(ctx, middleware.ClaimsKey, claims)
+}
+
 type MockUserService struct {
 	mock.Mock
 }
@@ -31,9 +37,29 @@ func (m *MockUserService) CreateUser(ctx context.Context, username string, passw
 	return nil, args.Error(1)
 }
 
-func (m *MockUserService) Authenticate(ctx context.Context, username string, password string) (string, error) {
-	args := m.Called(ctx, username, password)
-	return args.String(0), args.Error(1)
+func (m *MockUserService) Authenticate(ctx context.Context, username string, password string, client models.ClientInfo) (*models.AuthTokens, error) {
+	args := m.Called(ctx, username, password, client)
+	if tokens, ok := args.Get(0).(*models.AuthTokens); ok {
+		return tokens, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserService) RecordFailedLogin(ctx context.Context, username string, client models.ClientInfo, err error) {
+	m.Called(ctx, username, client, err)
+}
+
+func (m *MockUserService) AuthenticateExternal(ctx context.Context, provider string, credentials models.Credentials, client models.ClientInfo) (*models.AuthTokens, error) {
+	args := m.Called(ctx, provider, credentials, client)
+	if tokens, ok := args.Get(0).(*models.AuthTokens); ok {
+		return tokens, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockUserService) UnlockUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
 }
 
 type MockMerchService struct {
@@ -78,6 +104,14 @@ func (m *MockInventoryService) BuyItemToInventory(ctx context.Context, userID in
 	return args.Error(0)
 }
 
+func (m *MockInventoryService) GetPurchasesByUserID(ctx context.Context, userID int64) ([]models.Purchase, error) {
+	args := m.Called(ctx, userID)
+	if purchases, ok := args.Get(0).([]models.Purchase); ok {
+		return purchases, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockInventoryService) GetInventoryByUserID(ctx context.Context, userID int64) ([]models.Inventory, error) {
 	args := m.Called(ctx, userID)
 	if inv, ok := args.Get(0).([]models.Inventory); ok {
@@ -113,6 +147,11 @@ func (m *MockTransactionService) CreateTransaction(ctx context.Context, transact
 	return args.Error(0)
 }
 
+func (m *MockTransactionService) CreateTransactions(ctx context.Context, transactions []models.CoinTransaction) error {
+	args := m.Called(ctx, transactions)
+	return args.Error(0)
+}
+
 func (m *MockTransactionService) GetTransactionsByUserId(ctx context.Context, userID int64) ([]models.CoinTransaction, error) {
 	args := m.Called(ctx, userID)
 	if transactions, ok := args.Get(0).([]models.CoinTransaction); ok {
@@ -120,3 +159,346 @@ func (m *MockTransactionService) GetTransactionsByUserId(ctx context.Context, us
 	}
 	return nil, args.Error(1)
 }
+
+func (m *MockTransactionService) GetTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error) {
+	args := m.Called(ctx, filter)
+	if transfers, ok := args.Get(0).([]models.Transfer); ok {
+		return transfers, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) GetHistory(ctx context.Context, filter models.TransactionFilter, cursor string) (*models.TransactionPage, error) {
+	args := m.Called(ctx, filter, cursor)
+	if page, ok := args.Get(0).(*models.TransactionPage); ok {
+		return page, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionService) Categories() []string {
+	args := m.Called()
+	if categories, ok := args.Get(0).([]string); ok {
+		return categories
+	}
+	return nil
+}
+
+type MockTokenService struct {
+	mock.Mock
+}
+
+func (m *MockTokenService) IssueTokens(ctx context.Context, user *models.User, client models.ClientInfo) (*models.AuthTokens, error) {
+	args := m.Called(ctx, user, client)
+	if tokens, ok := args.Get(0).(*models.AuthTokens); ok {
+		return tokens, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTokenService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.AuthTokens, error) {
+	args := m.Called(ctx, refreshToken, client)
+	if tokens, ok := args.Get(0).(*models.AuthTokens); ok {
+		return tokens, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTokenService) Logout(ctx context.Context, claims *auth.Claims, refreshToken string) error {
+	args := m.Called(ctx, claims, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) ValidateAccessToken(ctx context.Context, tokenStr string) (*auth.Claims, error) {
+	args := m.Called(ctx, tokenStr)
+	if claims, ok := args.Get(0).(*auth.Claims); ok {
+		return claims, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTokenService) RevokeSessions(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, username string, req models.APIKey) (*models.CreatedAPIKey, error) {
+	args := m.Called(ctx, username, req)
+	if key, ok := args.Get(0).(*models.CreatedAPIKey); ok {
+		return key, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyService) GetAPIKeys(ctx context.Context, username string) ([]models.APIKey, error) {
+	args := m.Called(ctx, username)
+	if keys, ok := args.Get(0).([]models.APIKey); ok {
+		return keys, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, username string, keyID int64) error {
+	args := m.Called(ctx, username, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) ValidateAPIKey(ctx context.Context, key string) (*auth.Claims, error) {
+	args := m.Called(ctx, key)
+	if claims, ok := args.Get(0).(*auth.Claims); ok {
+		return claims, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockAuthCodeAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthCodeAuthenticator) Name() string {
+	return "oidc"
+}
+
+func (m *MockAuthCodeAuthenticator) Authenticate(ctx context.Context, credentials models.Credentials) (*models.Identity, error) {
+	args := m.Called(ctx, credentials)
+	if identity, ok := args.Get(0).(*models.Identity); ok {
+		return identity, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAuthCodeAuthenticator) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	args := m.Called(ctx, state, nonce, codeChallenge)
+	return args.String(0), args.Error(1)
+}
+
+type MockPasswordService struct {
+	mock.Mock
+}
+
+func (m *MockPasswordService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string, client models.ClientInfo) error {
+	args := m.Called(ctx, username, oldPassword, newPassword, client)
+	return args.Error(0)
+}
+
+func (m *MockPasswordService) CreateResetToken(ctx context.Context, username, createdBy string) (*models.CreatedPasswordResetToken, error) {
+	args := m.Called(ctx, username, createdBy)
+	if token, ok := args.Get(0).(*models.CreatedPasswordResetToken); ok {
+		return token, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPasswordService) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	args := m.Called(ctx, resetToken, newPassword)
+	return args.Error(0)
+}
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) RecordLogin(ctx context.Context, event models.LoginEvent) {
+	m.Called(ctx, event)
+}
+
+func (m *MockSessionService) GetSessions(ctx context.Context, username string, currentSessionID int64) ([]models.Session, error) {
+	args := m.Called(ctx, username, currentSessionID)
+	if sessions, ok := args.Get(0).([]models.Session); ok {
+		return sessions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockSessionService) RevokeSession(ctx context.Context, username string, sessionID int64) error {
+	args := m.Called(ctx, username, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) GetLoginEvents(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error) {
+	args := m.Called(ctx, filter)
+	if events, ok := args.Get(0).([]models.LoginEvent); ok {
+		return events, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockProfileService struct {
+	mock.Mock
+}
+
+func (m *MockProfileService) GetProfile(ctx context.Context, username string) (*models.Profile, error) {
+	args := m.Called(ctx, username)
+	if profile, ok := args.Get(0).(*models.Profile); ok {
+		return profile, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockProfileService) UpdateProfile(ctx context.Context, username string, update models.ProfileUpdate) (*models.Profile, error) {
+	args := m.Called(ctx, username, update)
+	if profile, ok := args.Get(0).(*models.Profile); ok {
+		return profile, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockProfileService) SetStatus(ctx context.Context, username, status string) error {
+	args := m.Called(ctx, username, status)
+	return args.Error(0)
+}
+
+func (m *MockProfileService) SetDepartment(ctx context.Context, username, department string) error {
+	args := m.Called(ctx, username, department)
+	return args.Error(0)
+}
+
+type MockLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockLedgerService) VerifyLedger(ctx context.Context) (*models.LedgerReport, error) {
+	args := m.Called(ctx)
+	if report, ok := args.Get(0).(*models.LedgerReport); ok {
+		return report, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockIdempotencyService struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyService) Begin(ctx context.Context, username, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	args := m.Called(ctx, username, key, fingerprint)
+	if record, ok := args.Get(0).(*models.IdempotencyRecord); ok {
+		return record, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIdempotencyService) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+type MockScheduledTransferService struct {
+	mock.Mock
+}
+
+func (m *MockScheduledTransferService) CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransferService) GetScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error) {
+	args := m.Called(ctx, owner)
+	if transfers, ok := args.Get(0).([]models.ScheduledTransfer); ok {
+		return transfers, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockScheduledTransferService) GetScheduledTransfer(ctx context.Context, owner string, id int64) (*models.ScheduledTransfer, error) {
+	args := m.Called(ctx, owner, id)
+	if transfer, ok := args.Get(0).(*models.ScheduledTransfer); ok {
+		return transfer, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockScheduledTransferService) UpdateScheduledTransfer(ctx context.Context, owner string, id int64, update models.ScheduledTransferUpdate) (*models.ScheduledTransfer, error) {
+	args := m.Called(ctx, owner, id, update)
+	if transfer, ok := args.Get(0).(*models.ScheduledTransfer); ok {
+		return transfer, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockScheduledTransferService) DeleteScheduledTransfer(ctx context.Context, owner string, id int64) error {
+	args := m.Called(ctx, owner, id)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransferService) GetScheduledTransferRuns(ctx context.Context, owner string, id int64) ([]models.ScheduledTransferRun, error) {
+	args := m.Called(ctx, owner, id)
+	if runs, ok := args.Get(0).([]models.ScheduledTransferRun); ok {
+		return runs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockTransferLimitService struct {
+	mock.Mock
+}
+
+func (m *MockTransferLimitService) LimitCheck(ctx context.Context, userID int64) (*models.TransferLimitCheck, error) {
+	args := m.Called(ctx, userID)
+	if check, ok := args.Get(0).(*models.TransferLimitCheck); ok {
+		return check, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransferLimitService) GetLimits(ctx context.Context, username string) (*models.UserTransferLimits, error) {
+	args := m.Called(ctx, username)
+	if limits, ok := args.Get(0).(*models.UserTransferLimits); ok {
+		return limits, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransferLimitService) SetException(ctx context.Context, exception *models.TransferLimitException) error {
+	args := m.Called(ctx, exception)
+	return args.Error(0)
+}
+
+func (m *MockTransferLimitService) DeleteException(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+type MockTransferReversalService struct {
+	mock.Mock
+}
+
+func (m *MockTransferReversalService) CancelTransfer(ctx context.Context, username string, transferID int64) (*models.TransferReversal, error) {
+	args := m.Called(ctx, username, transferID)
+	if reversal, ok := args.Get(0).(*models.TransferReversal); ok {
+		return reversal, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransferReversalService) ReverseTransfer(ctx context.Context, admin string, transferID int64, reason string) (*models.TransferReversal, error) {
+	args := m.Called(ctx, admin, transferID, reason)
+	if reversal, ok := args.Get(0).(*models.TransferReversal); ok {
+		return reversal, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockIssuanceService struct {
+	mock.Mock
+}
+
+func (m *MockIssuanceService) Issue(ctx context.Context, issuance *models.CoinIssuance) error {
+	args := m.Called(ctx, issuance)
+	return args.Error(0)
+}
+
+type MockCoinExpiryService struct {
+	mock.Mock
+}
+
+func (m *MockCoinExpiryService) GetUpcomingExpirations(ctx context.Context, userID int64) ([]models.CoinExpiration, error) {
+	args := m.Called(ctx, userID)
+	if expirations, ok := args.Get(0).([]models.CoinExpiration); ok {
+		return expirations, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
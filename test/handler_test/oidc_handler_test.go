@@ -0,0 +1,108 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOIDCHandler_Login(t *testing.T) {
+	authenticator := new(MockAuthCodeAuthenticator)
+	handler := handlers.NewOIDCHandler(new(MockUserService), authenticator)
+
+	authenticator.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("https://idp.example.com/authorize?client_id=shop", nil)
+
+	req := httptest.NewRequest("GET", "/api/auth/oidc/login", nil)
+	w := httptest.NewRecorder()
+
+	handler.Login(w, req)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://idp.example.com/authorize?client_id=shop", w.Header().Get("Location"))
+
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "oidc_login", cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+
+	parts := strings.Split(cookies[0].Value, ".")
+	assert.Len(t, parts, 3)
+	call := authenticator.Calls[0]
+	assert.Equal(t, parts[0], call.Arguments.String(1), "state")
+	assert.Equal(t, parts[1], call.Arguments.String(2), "nonce")
+	challenge := sha256.Sum256([]byte(parts[2]))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), call.Arguments.String(3), "S256 challenge of the verifier")
+}
+
+func newOIDCCallbackRequest(query string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/auth/oidc/callback?"+query, nil)
+	req.AddCookie(&http.Cookie{Name: "oidc_login", Value: "state-1.nonce-1.verifier-1"})
+	return req
+}
+
+func TestOIDCHandler_Callback_Success(t *testing.T) {
+	mockUserService := new(MockUserService)
+	handler := handlers.NewOIDCHandler(mockUserService, new(MockAuthCodeAuthenticator))
+
+	mockUserService.On("AuthenticateExternal", mock.Anything, "oidc", models.Credentials{Code: "code-1", Nonce: "nonce-1", CodeVerifier: "verifier-1"}, mock.Anything).
+		Return(&models.AuthTokens{AccessToken: "token", RefreshToken: "refresh-token"}, nil)
+
+	w := httptest.NewRecorder()
+	handler.Callback(w, newOIDCCallbackRequest("state=state-1&code=code-1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "refresh-token")
+	mockUserService.AssertExpectations(t)
+}
+
+func TestOIDCHandler_Callback_StateMismatch(t *testing.T) {
+	mockUserService := new(MockUserService)
+	handler := handlers.NewOIDCHandler(mockUserService, new(MockAuthCodeAuthenticator))
+
+	w := httptest.NewRecorder()
+	handler.Callback(w, newOIDCCallbackRequest("state=forged&code=code-1"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUserService.AssertNotCalled(t, "AuthenticateExternal", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOIDCHandler_Callback_WithoutCookie(t *testing.T) {
+	handler := handlers.NewOIDCHandler(new(MockUserService), new(MockAuthCodeAuthenticator))
+
+	w := httptest.NewRecorder()
+	handler.Callback(w, httptest.NewRequest("GET", "/api/auth/oidc/callback?state=state-1&code=code-1", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOIDCHandler_Callback_ProviderError(t *testing.T) {
+	handler := handlers.NewOIDCHandler(new(MockUserService), new(MockAuthCodeAuthenticator))
+
+	w := httptest.NewRecorder()
+	handler.Callback(w, newOIDCCallbackRequest("state=state-1&error=access_denied"))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestOIDCHandler_Callback_InvalidCode(t *testing.T) {
+	mockUserService := new(MockUserService)
+	handler := handlers.NewOIDCHandler(mockUserService, new(MockAuthCodeAuthenticator))
+
+	mockUserService.On("AuthenticateExternal", mock.Anything, "oidc", mock.Anything, mock.Anything).Return((*models.AuthTokens)(nil), models.ErrInvalidCredentials)
+
+	w := httptest.NewRecorder()
+	handler.Callback(w, newOIDCCallbackRequest("state=state-1&code=stale"))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,97 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPasswordHandler_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "success", body: `{"oldPassword": "old", "newPassword": "new"}`, wantStatus: http.StatusNoContent},
+		{name: "wrong old password", body: `{"oldPassword": "old", "newPassword": "new"}`, err: models.ErrInvalidCredentials, wantStatus: http.StatusForbidden},
+		{name: "external account", body: `{"oldPassword": "old", "newPassword": "new"}`, err: models.ErrNoLocalPassword, wantStatus: http.StatusConflict},
+		{name: "blocked", body: `{"oldPassword": "old", "newPassword": "new"}`, err: &models.LoginBlockedError{RetryAfter: time.Minute}, wantStatus: http.StatusTooManyRequests},
+		{name: "missing new password", body: `{"oldPassword": "old"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPasswordService := new(MockPasswordService)
+			handler := handlers.NewPasswordHandler(mockPasswordService)
+			mockPasswordService.On("ChangePassword", mock.Anything, "testuser", "old", "new", mock.Anything).Return(tt.err)
+
+			req := httptest.NewRequest("POST", "/api/me/password", strings.NewReader(tt.body))
+			req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+			w := httptest.NewRecorder()
+
+			handler.ChangePassword(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestPasswordHandler_CreateResetToken(t *testing.T) {
+	mockPasswordService := new(MockPasswordService)
+	handler := handlers.NewPasswordHandler(mockPasswordService)
+
+	expiresAt := time.Date(2026, 1, 1, 13, 0, 0, 0, time.UTC)
+	mockPasswordService.On("CreateResetToken", mock.Anything, "testuser", "admin").
+		Return(&models.CreatedPasswordResetToken{Token: "reset-token", ExpiresAt: expiresAt}, nil)
+	mockPasswordService.On("CreateResetToken", mock.Anything, "ghost", "admin").
+		Return((*models.CreatedPasswordResetToken)(nil), models.ErrUserNotFound)
+
+	r := chi.NewRouter()
+	r.Post("/api/admin/users/{username}/password-reset", func(w http.ResponseWriter, r *http.Request) {
+		handler.CreateResetToken(w, r.WithContext(setEmployeeUsername(r.Context(), "admin")))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/users/testuser/password-reset", nil))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "reset-token", response["resetToken"])
+	assert.Equal(t, "2026-01-01T13:00:00Z", response["expiresAt"])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/users/ghost/password-reset", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPasswordHandler_ResetPassword(t *testing.T) {
+	mockPasswordService := new(MockPasswordService)
+	handler := handlers.NewPasswordHandler(mockPasswordService)
+
+	mockPasswordService.On("ResetPassword", mock.Anything, "reset-token", "new").Return(nil)
+	mockPasswordService.On("ResetPassword", mock.Anything, "used-token", "new").Return(models.ErrInvalidResetToken)
+
+	w := httptest.NewRecorder()
+	handler.ResetPassword(w, httptest.NewRequest("POST", "/api/auth/password/reset", strings.NewReader(`{"resetToken": "reset-token", "newPassword": "new"}`)))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ResetPassword(w, httptest.NewRequest("POST", "/api/auth/password/reset", strings.NewReader(`{"resetToken": "used-token", "newPassword": "new"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ResetPassword(w, httptest.NewRequest("POST", "/api/auth/password/reset", strings.NewReader(`{"newPassword": "new"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,162 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProfileHandler_GetProfile(t *testing.T) {
+	mockProfileService := new(MockProfileService)
+	handler := handlers.NewProfileHandler(mockProfileService)
+
+	mockProfileService.On("GetProfile", mock.Anything, "testuser").Return(&models.Profile{
+		DisplayName: "Test User",
+		Department:  "Platform",
+		Manager:     "boss",
+		HireDate:    "2024-03-01",
+		Status:      models.UserStatusActive,
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/me", nil)
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.GetProfile(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "testuser", response["username"])
+	assert.Equal(t, "Test User", response["displayName"])
+	assert.Equal(t, "boss", response["manager"])
+	assert.Equal(t, "2024-03-01", response["hireDate"])
+	assert.Equal(t, "active", response["status"])
+}
+
+func TestProfileHandler_UpdateProfile(t *testing.T) {
+	mockProfileService := new(MockProfileService)
+	handler := handlers.NewProfileHandler(mockProfileService)
+
+	displayName := "Alice"
+	mockProfileService.On("UpdateProfile", mock.Anything, "testuser", models.ProfileUpdate{DisplayName: &displayName}).
+		Return(&models.Profile{DisplayName: displayName, Department: "Payments", Status: models.UserStatusActive}, nil)
+
+	req := httptest.NewRequest("PATCH", "/api/me", strings.NewReader(`{"displayName":"Alice"}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.UpdateProfile(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"displayName":"Alice"`)
+}
+
+func TestProfileHandler_UpdateProfile_DepartmentForbidden(t *testing.T) {
+	mockProfileService := new(MockProfileService)
+	handler := handlers.NewProfileHandler(mockProfileService)
+
+	req := httptest.NewRequest("PATCH", "/api/me", strings.NewReader(`{"department":"Payments"}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.UpdateProfile(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockProfileService.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProfileHandler_UpdateProfile_Invalid(t *testing.T) {
+	mockProfileService := new(MockProfileService)
+	handler := handlers.NewProfileHandler(mockProfileService)
+
+	mockProfileService.On("UpdateProfile", mock.Anything, "testuser", mock.Anything).
+		Return(nil, fmt.Errorf("%w: email is not a valid address", models.ErrInvalidProfile))
+
+	req := httptest.NewRequest("PATCH", "/api/me", strings.NewReader(`{"email":"nope"}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.UpdateProfile(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "email")
+}
+
+func TestProfileHandler_UpdateProfile_StatusIsForbidden(t *testing.T) {
+	mockProfileService := new(MockProfileService)
+	handler := handlers.NewProfileHandler(mockProfileService)
+
+	req := httptest.NewRequest("PATCH", "/api/me", strings.NewReader(`{"status":"active"}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.UpdateProfile(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockProfileService.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProfileHandler_SetUserStatus(t *testing.T) {
+	mockProfileService := new(MockProfileService)
+	handler := handlers.NewProfileHandler(mockProfileService)
+
+	mockProfileService.On("SetStatus", mock.Anything, "testuser", "suspended").Return(nil)
+	mockProfileService.On("SetStatus", mock.Anything, "testuser", "retired").Return(models.ErrInvalidStatus)
+	mockProfileService.On("SetStatus", mock.Anything, "ghost", "suspended").Return(models.ErrUserNotFound)
+
+	r := chi.NewRouter()
+	r.Put("/api/admin/users/{username}/status", handler.SetUserStatus)
+
+	for _, tc := range []struct {
+		username string
+		body     string
+		code     int
+	}{
+		{"testuser", `{"status":"suspended"}`, http.StatusNoContent},
+		{"testuser", `{"status":"retired"}`, http.StatusBadRequest},
+		{"ghost", `{"status":"suspended"}`, http.StatusNotFound},
+		{"testuser", `{`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("PUT", "/api/admin/users/"+tc.username+"/status", strings.NewReader(tc.body)))
+		assert.Equal(t, tc.code, w.Code, tc.body)
+	}
+}
+
+func TestProfileHandler_SetUserDepartment(t *testing.T) {
+	mockProfileService := new(MockProfileService)
+	handler := handlers.NewProfileHandler(mockProfileService)
+
+	mockProfileService.On("SetDepartment", mock.Anything, "testuser", "Payments").Return(nil)
+	mockProfileService.On("SetDepartment", mock.Anything, "ghost", "Payments").Return(models.ErrUserNotFound)
+
+	r := chi.NewRouter()
+	r.Put("/api/admin/users/{username}/department", handler.SetUserDepartment)
+
+	for _, tc := range []struct {
+		username string
+		body     string
+		code     int
+	}{
+		{"testuser", `{"department":"Payments"}`, http.StatusNoContent},
+		{"ghost", `{"department":"Payments"}`, http.StatusNotFound},
+		{"testuser", `{`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("PUT", "/api/admin/users/"+tc.username+"/department", strings.NewReader(tc.body)))
+		assert.Equal(t, tc.code, w.Code, tc.body)
+	}
+}
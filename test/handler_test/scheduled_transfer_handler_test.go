@@ -0,0 +1,154 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newScheduledTransferRouter(handler *handlers.ScheduledTransferHandler) http.Handler {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(setEmployeeUsername(r.Context(), "lead")))
+		})
+	})
+	r.Post("/api/scheduled-transfers", handler.CreateScheduledTransfer)
+	r.Get("/api/scheduled-transfers", handler.GetScheduledTransfers)
+	r.Patch("/api/scheduled-transfers/{id}", handler.UpdateScheduledTransfer)
+	r.Delete("/api/scheduled-transfers/{id}", handler.DeleteScheduledTransfer)
+	r.Get("/api/scheduled-transfers/{id}/runs", handler.GetScheduledTransferRuns)
+	return r
+}
+
+func TestScheduledTransferHandler_Create(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	r := newScheduledTransferRouter(handlers.NewScheduledTransferHandler(mockService))
+
+	mockService.On("CreateScheduledTransfer", mock.Anything, mock.MatchedBy(func(transfer *models.ScheduledTransfer) bool {
+		return transfer.Owner == "lead" && transfer.ToUser == "report" && transfer.Amount == 10 && transfer.Schedule == "0 9 * * 1" &&
+			transfer.Message == "weekly thanks"
+	})).Run(func(args mock.Arguments) {
+		transfer := args.Get(1).(*models.ScheduledTransfer)
+		transfer.ID = 1
+		transfer.Active = true
+		transfer.NextRunAt = time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+	}).Return(nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/scheduled-transfers",
+		strings.NewReader(`{"toUser": "report", "amount": 10, "message": "weekly thanks ", "schedule": "0 9 * * 1"}`)))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"nextRunAt":"2026-10-05T09:00:00Z"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestScheduledTransferHandler_Create_Invalid(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	r := newScheduledTransferRouter(handlers.NewScheduledTransferHandler(mockService))
+
+	mockService.On("CreateScheduledTransfer", mock.Anything, mock.MatchedBy(func(transfer *models.ScheduledTransfer) bool {
+		return transfer.ToUser == "report"
+	})).Return(fmt.Errorf("%w: bad schedule", models.ErrInvalidScheduledTransfer))
+	mockService.On("CreateScheduledTransfer", mock.Anything, mock.MatchedBy(func(transfer *models.ScheduledTransfer) bool {
+		return transfer.ToUser == "ghost"
+	})).Return(fmt.Errorf("%w: ghost", models.ErrUserNotFound))
+
+	for _, body := range []string{`{"toUser": "report", "amount": 10, "schedule": "sometimes"}`, `{"toUser": "ghost", "amount": 10, "schedule": "@weekly"}`, `{`} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/api/scheduled-transfers", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestScheduledTransferHandler_List(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	r := newScheduledTransferRouter(handlers.NewScheduledTransferHandler(mockService))
+
+	mockService.On("GetScheduledTransfers", mock.Anything, "lead").Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/scheduled-transfers", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+}
+
+func TestScheduledTransferHandler_Update(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	r := newScheduledTransferRouter(handlers.NewScheduledTransferHandler(mockService))
+
+	mockService.On("UpdateScheduledTransfer", mock.Anything, "lead", int64(1), mock.MatchedBy(func(update models.ScheduledTransferUpdate) bool {
+		return update.Active != nil && !*update.Active && update.Amount == nil && update.Schedule == nil
+	})).Return(&models.ScheduledTransfer{ID: 1, Owner: "lead", ToUser: "report", Amount: 10, Schedule: "@weekly"}, nil)
+	mockService.On("UpdateScheduledTransfer", mock.Anything, "lead", int64(2), mock.Anything).Return(nil, models.ErrScheduledTransferNotFound)
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{"/api/scheduled-transfers/1", http.StatusOK},
+		{"/api/scheduled-transfers/2", http.StatusNotFound},
+		{"/api/scheduled-transfers/abc", http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("PATCH", tc.path, strings.NewReader(`{"active": false}`)))
+		assert.Equal(t, tc.code, w.Code, tc.path)
+	}
+}
+
+func TestScheduledTransferHandler_Delete(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	r := newScheduledTransferRouter(handlers.NewScheduledTransferHandler(mockService))
+
+	mockService.On("DeleteScheduledTransfer", mock.Anything, "lead", int64(1)).Return(nil)
+	mockService.On("DeleteScheduledTransfer", mock.Anything, "lead", int64(2)).Return(models.ErrScheduledTransferNotFound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/scheduled-transfers/1", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/scheduled-transfers/2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestScheduledTransferHandler_GetRuns(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	r := newScheduledTransferRouter(handlers.NewScheduledTransferHandler(mockService))
+
+	mockService.On("GetScheduledTransferRuns", mock.Anything, "lead", int64(1)).Return([]models.ScheduledTransferRun{{
+		ID: 4, ScheduledTransferID: 1, ScheduledFor: time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC),
+		Status: models.ScheduledRunFailed, Error: "not enough coins: balance is 5, 10 required",
+	}}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/scheduled-transfers/1/runs", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"failed"`)
+	assert.Contains(t, w.Body.String(), "not enough coins")
+}
+
+func TestScheduledTransferHandler_Unauthorized(t *testing.T) {
+	handler := handlers.NewScheduledTransferHandler(nil)
+
+	w := httptest.NewRecorder()
+	handler.GetScheduledTransfers(w, httptest.NewRequest("GET", "/api/scheduled-transfers", nil).WithContext(context.Background()))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
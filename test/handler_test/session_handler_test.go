@@ -0,0 +1,125 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSessionHandler_GetSessions(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	handler := handlers.NewSessionHandler(mockSessionService)
+
+	mockSessionService.On("GetSessions", mock.Anything, "testuser", int64(3)).
+		Return([]models.Session{{ID: 3, UserID: 1, IP: "10.0.0.1", UserAgent: "curl/8.0", Current: true}, {ID: 4, UserID: 1}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/me/sessions", nil)
+	req = req.WithContext(setClaims(req.Context(), &auth.Claims{EmployeeUsername: "testuser", SessionID: 3}))
+	w := httptest.NewRecorder()
+
+	handler.GetSessions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Len(t, response, 2)
+	assert.Equal(t, true, response[0]["current"])
+	assert.Equal(t, "curl/8.0", response[0]["userAgent"])
+	assert.NotContains(t, response[1], "current")
+	assert.NotContains(t, response[0], "userId")
+}
+
+func TestSessionHandler_RevokeSession(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	handler := handlers.NewSessionHandler(mockSessionService)
+
+	mockSessionService.On("RevokeSession", mock.Anything, "testuser", int64(1)).Return(nil)
+	mockSessionService.On("RevokeSession", mock.Anything, "testuser", int64(2)).Return(models.ErrSessionNotFound)
+
+	r := chi.NewRouter()
+	r.Delete("/api/me/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.RevokeSession(w, r.WithContext(setEmployeeUsername(r.Context(), "testuser")))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/me/sessions/1", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/me/sessions/2", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/me/sessions/abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSessionHandler_GetUserSessions_UnknownUser(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	handler := handlers.NewSessionHandler(mockSessionService)
+
+	mockSessionService.On("GetSessions", mock.Anything, "ghost", int64(0)).Return(nil, models.ErrUserNotFound)
+
+	r := chi.NewRouter()
+	r.Get("/api/admin/users/{username}/sessions", handler.GetUserSessions)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/users/ghost/sessions", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSessionHandler_GetLoginEvents(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	handler := handlers.NewSessionHandler(mockSessionService)
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mockSessionService.On("GetLoginEvents", mock.Anything, mock.MatchedBy(func(filter models.LoginEventFilter) bool {
+		return filter.Username == "alice" && filter.IP == "10.0.0.1" && filter.Since != nil && filter.Since.Equal(since) && filter.Limit == 20
+	})).Return([]models.LoginEvent{{ID: 1, Username: "alice", FailureReason: models.LoginFailureInvalidCredentials, IP: "10.0.0.1"}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/admin/logins?username=alice&ip=10.0.0.1&since=2026-10-01T00:00:00Z&limit=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetLoginEvents(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"failureReason":"invalid_credentials"`)
+}
+
+func TestSessionHandler_GetLoginEvents_ConvertsOffsetToUTC(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	handler := handlers.NewSessionHandler(mockSessionService)
+
+	mockSessionService.On("GetLoginEvents", mock.Anything, mock.MatchedBy(func(filter models.LoginEventFilter) bool {
+		return filter.Since != nil && *filter.Since == time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	})).Return([]models.LoginEvent{}, nil)
+
+	w := httptest.NewRecorder()
+	handler.GetLoginEvents(w, httptest.NewRequest("GET", "/api/admin/logins?since=2026-10-01T03:00:00%2B03:00", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSessionService.AssertExpectations(t)
+}
+
+func TestSessionHandler_GetLoginEvents_InvalidQuery(t *testing.T) {
+	handler := handlers.NewSessionHandler(new(MockSessionService))
+
+	for _, query := range []string{"since=yesterday", "limit=0", "limit=ten"} {
+		w := httptest.NewRecorder()
+		handler.GetLoginEvents(w, httptest.NewRequest("GET", "/api/admin/logins?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,220 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/middleware"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTokenHandler_Refresh_Success(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	handler := handlers.NewTokenHandler(mockTokenService)
+
+	mockTokenService.On("Refresh", mock.Anything, "refresh-token", mock.Anything).Return(&models.AuthTokens{AccessToken: "new-token", RefreshToken: "new-refresh-token"}, nil)
+
+	req := httptest.NewRequest("POST", "/api/auth/refresh", strings.NewReader(`{"refreshToken": "refresh-token"}`))
+	w := httptest.NewRecorder()
+
+	handler.Refresh(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "new-refresh-token")
+}
+
+func TestTokenHandler_Refresh_MissingToken(t *testing.T) {
+	handler := handlers.NewTokenHandler(new(MockTokenService))
+
+	req := httptest.NewRequest("POST", "/api/auth/refresh", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+
+	handler.Refresh(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTokenHandler_Refresh_InvalidToken(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	handler := handlers.NewTokenHandler(mockTokenService)
+
+	mockTokenService.On("Refresh", mock.Anything, "stale", mock.Anything).Return((*models.AuthTokens)(nil), models.ErrInvalidRefreshToken)
+
+	req := httptest.NewRequest("POST", "/api/auth/refresh", strings.NewReader(`{"refreshToken": "stale"}`))
+	w := httptest.NewRecorder()
+
+	handler.Refresh(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestTokenHandler_Logout_Success(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	handler := handlers.NewTokenHandler(mockTokenService)
+
+	claims := &auth.Claims{EmployeeUsername: "testuser", RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1"}}
+	req := httptest.NewRequest("POST", "/api/auth/logout", strings.NewReader(`{"refreshToken": "refresh-token"}`))
+	req = req.WithContext(setClaims(req.Context(), claims))
+	w := httptest.NewRecorder()
+
+	mockTokenService.On("Logout", mock.Anything, claims, "refresh-token").Return(nil)
+
+	handler.Logout(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockTokenService.AssertExpectations(t)
+}
+
+func TestTokenHandler_Logout_WithoutBody(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	handler := handlers.NewTokenHandler(mockTokenService)
+
+	claims := &auth.Claims{EmployeeUsername: "testuser", RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1"}}
+	req := httptest.NewRequest("POST", "/api/auth/logout", nil)
+	req = req.WithContext(setClaims(req.Context(), claims))
+	w := httptest.NewRecorder()
+
+	mockTokenService.On("Logout", mock.Anything, claims, "").Return(nil)
+
+	handler.Logout(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestTokenHandler_Logout_Unauthorized(t *testing.T) {
+	handler := handlers.NewTokenHandler(new(MockTokenService))
+
+	req := httptest.NewRequest("POST", "/api/auth/logout", nil)
+	w := httptest.NewRecorder()
+
+	handler.Logout(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	authMiddleware := middleware.NewAuthMiddleware(mockTokenService, new(MockAPIKeyService))
+
+	mockTokenService.On("ValidateAccessToken", mock.Anything, "revoked-token").Return((*auth.Claims)(nil), models.ErrTokenRevoked)
+
+	req := httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("Authorization", "Bearer revoked-token")
+	w := httptest.NewRecorder()
+
+	authMiddleware.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler must not be called")
+	})).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "revoked")
+}
+
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	authMiddleware := middleware.NewAuthMiddleware(mockTokenService, new(MockAPIKeyService))
+
+	claims := &auth.Claims{EmployeeUsername: "testuser"}
+	mockTokenService.On("ValidateAccessToken", mock.Anything, "valid-token").Return(claims, nil)
+
+	req := httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	w := httptest.NewRecorder()
+
+	authMiddleware.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, ok := middleware.GetEmployeeUsername(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "testuser", username)
+		w.WriteHeader(http.StatusOK)
+	})).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_ValidationError(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	authMiddleware := middleware.NewAuthMiddleware(mockTokenService, new(MockAPIKeyService))
+
+	mockTokenService.On("ValidateAccessToken", mock.Anything, "token").Return((*auth.Claims)(nil), errors.New("database error"))
+
+	req := httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	authMiddleware.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler must not be called")
+	})).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthMiddleware_RequiresBearerScheme(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	authMiddleware := middleware.NewAuthMiddleware(mockTokenService, new(MockAPIKeyService))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler must not be called")
+	})
+
+	for _, header := range []string{"valid-token", "Basic dXNlcjpwYXNz", "Bearer", "Bearer "} {
+		req := httptest.NewRequest("GET", "/api/info", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+
+		authMiddleware.Handle(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_request"`, header)
+	}
+	mockTokenService.AssertNotCalled(t, "ValidateAccessToken", mock.Anything, mock.Anything)
+}
+
+func TestAuthMiddleware_ExplainsRejection(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	authMiddleware := middleware.NewAuthMiddleware(mockTokenService, new(MockAPIKeyService))
+
+	mockTokenService.On("ValidateAccessToken", mock.Anything, "expired-token").
+		Return((*auth.Claims)(nil), fmt.Errorf("%w: %w", models.ErrInvalidToken, auth.ErrTokenExpired))
+
+	req := httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("Authorization", "bearer expired-token")
+	w := httptest.NewRecorder()
+
+	authMiddleware.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler must not be called")
+	})).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "invalid token: token is expired\n", w.Body.String())
+	assert.Equal(t, `Bearer error="invalid_token", error_description="invalid token: token is expired"`, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestAuthMiddleware_InactiveAccount(t *testing.T) {
+	mockTokenService := new(MockTokenService)
+	authMiddleware := middleware.NewAuthMiddleware(mockTokenService, new(MockAPIKeyService))
+
+	mockTokenService.On("ValidateAccessToken", mock.Anything, "token").
+		Return((*auth.Claims)(nil), &models.AccountInactiveError{Status: models.UserStatusSuspended})
+
+	req := httptest.NewRequest("GET", "/api/info", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	w := httptest.NewRecorder()
+
+	authMiddleware.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler must not be called")
+	})).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "suspended")
+}
@@ -5,6 +5,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"github.com/avito-shop-service/internal/handlers"
 	"github.com/avito-shop-service/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -13,6 +14,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestTransactionHandler_SendCoin_Success(t *testing.T) {
@@ -94,7 +96,8 @@ func TestTransactionHandler_SendCoin_UserNotFound(t *testing.T) {
 
 func TestTransactionHandler_SendCoin_NotEnoughCoins(t *testing.T) {
 	mockUserService := new(MockUserService)
-	handler := handlers.NewTransactionHandler(mockUserService, nil)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
 
 	reqBody := `{"toUser": "recipient", "amount": 1000}`
 	req := httptest.NewRequest("POST", "/send-coin", strings.NewReader(reqBody))
@@ -104,6 +107,8 @@ func TestTransactionHandler_SendCoin_NotEnoughCoins(t *testing.T) {
 	user := &models.User{ID: 1, Username: "testuser", Coins: 500}
 
 	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(user, nil)
+	mockTransactionService.On("CreateTransaction", req.Context(), mock.AnythingOfType("*models.CoinTransaction")).
+		Return(&models.InsufficientFundsError{Balance: 500, Required: 1000})
 
 	handler.SendCoin(w, req)
 
@@ -131,3 +136,324 @@ func TestTransactionHandler_SendCoin_CreateTransactionError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "failed to send coins")
 }
+
+func TestTransactionHandler_SendCoin_InactiveRecipient(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("POST", "/send-coin", strings.NewReader(`{"toUser": "leaver", "amount": 100}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser", Coins: 500}, nil)
+	mockTransactionService.On("CreateTransaction", req.Context(), mock.AnythingOfType("*models.CoinTransaction")).
+		Return(&models.AccountInactiveError{Username: "leaver", Status: models.UserStatusTerminated})
+
+	handler.SendCoin(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "account leaver is terminated")
+}
+
+func TestTransactionHandler_SendCoin_ToYourself(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("POST", "/send-coin", strings.NewReader(`{"toUser": "testuser", "amount": 100}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser", Coins: 500}, nil)
+	mockTransactionService.On("CreateTransaction", req.Context(), mock.MatchedBy(func(transaction *models.CoinTransaction) bool {
+		return transaction.FromUser == "testuser"
+	})).Return(models.ErrSelfTransfer)
+
+	handler.SendCoin(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cannot send coins to yourself")
+}
+
+func TestTransactionHandler_SendCoin_WithMessageAndCategory(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	reqBody := `{"toUser": "recipient", "amount": 100, "message": " thanks for covering my shift ", "category": "helped with on-call"}`
+	req := httptest.NewRequest("POST", "/send-coin", strings.NewReader(reqBody))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser", Coins: 500}, nil)
+	mockTransactionService.On("CreateTransaction", req.Context(), mock.MatchedBy(func(transaction *models.CoinTransaction) bool {
+		return transaction.Message == "thanks for covering my shift" && transaction.Category == "helped with on-call"
+	})).Return(nil)
+
+	handler.SendCoin(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockTransactionService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_SendCoin_UnknownCategory(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("POST", "/send-coin", strings.NewReader(`{"toUser": "recipient", "amount": 100, "category": "birthday"}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser", Coins: 500}, nil)
+	mockTransactionService.On("CreateTransaction", req.Context(), mock.AnythingOfType("*models.CoinTransaction")).
+		Return(fmt.Errorf("%w: %q", models.ErrUnknownCategory, "birthday"))
+
+	handler.SendCoin(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown kudos category")
+}
+
+func TestTransactionHandler_SendCoin_TransferLimitExceeded(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("POST", "/send-coin", strings.NewReader(`{"toUser": "recipient", "amount": 100}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser", Coins: 500}, nil)
+	mockTransactionService.On("CreateTransaction", req.Context(), mock.AnythingOfType("*models.CoinTransaction")).
+		Return(&models.TransferLimitError{Rule: models.RuleDailyLimit, Limit: 300, Used: 250})
+
+	handler.SendCoin(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "daily_limit")
+}
+
+func TestTransactionHandler_GetCategories(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(nil, mockTransactionService)
+
+	mockTransactionService.On("Categories").Return([]string{"great review", "mentoring"})
+
+	w := httptest.NewRecorder()
+	handler.GetCategories(w, httptest.NewRequest("GET", "/api/kudos/categories", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["great review", "mentoring"]`, w.Body.String())
+}
+
+func TestTransactionHandler_GetTransfers(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(nil, mockTransactionService)
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mockTransactionService.On("GetTransfers", mock.Anything, mock.MatchedBy(func(filter models.TransferFilter) bool {
+		return filter.Username == "alice" && filter.Category == "great review" && filter.Since != nil && filter.Since.Equal(since) &&
+			filter.Until == nil && filter.Limit == 20
+	})).Return([]models.Transfer{{ID: 1, FromUser: "bob", ToUser: "alice", Amount: 10, Message: "lgtm", Category: "great review"}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/admin/transfers?username=alice&category=great+review&since=2026-10-01T00:00:00Z&limit=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetTransfers(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"category":"great review"`)
+}
+
+func TestTransactionHandler_GetTransfers_ConvertsOffsetToUTC(t *testing.T) {
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(nil, mockTransactionService)
+
+	mockTransactionService.On("GetTransfers", mock.Anything, mock.MatchedBy(func(filter models.TransferFilter) bool {
+		return filter.Since != nil && *filter.Since == time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) &&
+			filter.Until != nil && *filter.Until == time.Date(2026, 10, 2, 1, 0, 0, 0, time.UTC)
+	})).Return([]models.Transfer{}, nil)
+
+	req := httptest.NewRequest("GET", "/api/admin/transfers?since=2026-10-01T03:00:00%2B03:00&until=2026-10-01T20:00:00-05:00", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetTransfers(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockTransactionService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_GetTransfers_InvalidQuery(t *testing.T) {
+	handler := handlers.NewTransactionHandler(nil, new(MockTransactionService))
+
+	for _, query := range []string{"since=yesterday", "until=tomorrow", "limit=0", "limit=ten"} {
+		w := httptest.NewRecorder()
+		handler.GetTransfers(w, httptest.NewRequest("GET", "/api/admin/transfers?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestTransactionHandler_GetTransactions(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("GET", "/api/transactions?direction=sent&counterpart=bob&type=transfer&since=2026-10-01T00:00:00Z&order=asc&limit=10&cursor=abc", nil)
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockTransactionService.On("GetHistory", req.Context(), mock.MatchedBy(func(filter models.TransactionFilter) bool {
+		return filter.UserID == 1 && filter.Direction == "sent" && filter.Counterpart == "bob" && filter.Type == "transfer" &&
+			filter.Since != nil && filter.Since.Equal(since) && filter.Until == nil && filter.Ascending && filter.Limit == 10
+	}), "abc").Return(&models.TransactionPage{
+		Transactions: []models.HistoryEntry{{ID: 7, EntryID: 3, Type: "transfer", Direction: "sent", Counterpart: "bob", Amount: 10, CreatedAt: since}},
+		NextCursor:   "def",
+	}, nil)
+
+	handler.GetTransactions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"transactions": [{"id": 7, "entryId": 3, "type": "transfer", "direction": "sent", "counterpart": "bob", "amount": 10, "createdAt": "2026-10-01T00:00:00Z"}],
+		"nextCursor": "def"
+	}`, w.Body.String())
+}
+
+func TestTransactionHandler_GetTransactions_ConvertsOffsetToUTC(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("GET", "/api/transactions?since=2026-10-01T03:00:00%2B03:00&until=2026-10-01T20:00:00-05:00", nil)
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockTransactionService.On("GetHistory", req.Context(), mock.MatchedBy(func(filter models.TransactionFilter) bool {
+		return filter.Since != nil && *filter.Since == time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) &&
+			filter.Until != nil && *filter.Until == time.Date(2026, 10, 2, 1, 0, 0, 0, time.UTC)
+	}), "").Return(&models.TransactionPage{Transactions: []models.HistoryEntry{}}, nil)
+
+	handler.GetTransactions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockTransactionService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_GetTransactions_InvalidQuery(t *testing.T) {
+	handler := handlers.NewTransactionHandler(new(MockUserService), new(MockTransactionService))
+
+	for _, query := range []string{"since=yesterday", "until=tomorrow", "order=random", "limit=0", "limit=ten"} {
+		req := httptest.NewRequest("GET", "/api/transactions?"+query, nil)
+		req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+		w := httptest.NewRecorder()
+		handler.GetTransactions(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestTransactionHandler_GetTransactions_InvalidCursor(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("GET", "/api/transactions?cursor=garbage", nil)
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockTransactionService.On("GetHistory", req.Context(), mock.Anything, "garbage").
+		Return(nil, fmt.Errorf("%w: malformed cursor", models.ErrInvalidHistoryQuery))
+
+	handler.GetTransactions(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "malformed cursor")
+}
+
+func TestTransactionHandler_SendCoinBatch_Success(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	reqBody := `{"transfers": [{"toUser": "bob", "amount": 100}, {"toUser": "carol", "amount": 50, "message": " thanks "}]}`
+	req := httptest.NewRequest("POST", "/send-coin/batch", strings.NewReader(reqBody))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockTransactionService.On("CreateTransactions", req.Context(), mock.MatchedBy(func(transactions []models.CoinTransaction) bool {
+		return len(transactions) == 2 && transactions[0].UserID == 1 && transactions[1].CounterpartUser == "carol" && transactions[1].Message == "thanks"
+	})).Run(func(args mock.Arguments) {
+		transactions := args.Get(1).([]models.CoinTransaction)
+		transactions[0].ID, transactions[1].ID = 11, 12
+	}).Return(nil)
+
+	handler.SendCoinBatch(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"results": [
+		{"toUser": "bob", "amount": 100, "status": "sent", "transferId": 11},
+		{"toUser": "carol", "amount": 50, "status": "sent", "transferId": 12}
+	]}`, w.Body.String())
+}
+
+func TestTransactionHandler_SendCoinBatch_LegFailed(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	reqBody := `{"transfers": [{"toUser": "bob", "amount": 100}, {"toUser": "carol", "amount": 500}, {"toUser": "dave", "amount": 10}]}`
+	req := httptest.NewRequest("POST", "/send-coin/batch", strings.NewReader(reqBody))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockTransactionService.On("CreateTransactions", req.Context(), mock.Anything).Return(&models.TransferBatchError{
+		Index: 1, ToUser: "carol", Err: &models.InsufficientFundsError{Balance: 400, Required: 500},
+	})
+
+	handler.SendCoinBatch(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "transfer to carol: not enough coins: balance is 400, 500 required", "results": [
+		{"toUser": "bob", "amount": 100, "status": "skipped"},
+		{"toUser": "carol", "amount": 500, "status": "failed", "error": "not enough coins: balance is 400, 500 required"},
+		{"toUser": "dave", "amount": 10, "status": "skipped"}
+	]}`, w.Body.String())
+}
+
+func TestTransactionHandler_SendCoinBatch_Empty(t *testing.T) {
+	handler := handlers.NewTransactionHandler(nil, nil)
+
+	req := httptest.NewRequest("POST", "/send-coin/batch", strings.NewReader(`{"transfers": []}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	handler.SendCoinBatch(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "transfers must have 1 to 100 entries")
+}
+
+func TestTransactionHandler_SendCoinBatch_Error(t *testing.T) {
+	mockUserService := new(MockUserService)
+	mockTransactionService := new(MockTransactionService)
+	handler := handlers.NewTransactionHandler(mockUserService, mockTransactionService)
+
+	req := httptest.NewRequest("POST", "/send-coin/batch", strings.NewReader(`{"transfers": [{"toUser": "bob", "amount": 100}]}`))
+	req = req.WithContext(setEmployeeUsername(req.Context(), "testuser"))
+	w := httptest.NewRecorder()
+
+	mockUserService.On("GetUserByUsername", req.Context(), "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockTransactionService.On("CreateTransactions", req.Context(), mock.Anything).Return(errors.New("database error"))
+
+	handler.SendCoinBatch(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "failed to send coins: database error")
+}
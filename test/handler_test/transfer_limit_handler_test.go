@@ -0,0 +1,114 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTransferLimitRouter(handler *handlers.TransferLimitHandler) http.Handler {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(setEmployeeUsername(r.Context(), "admin")))
+		})
+	})
+	r.Get("/api/admin/users/{username}/transfer-limits", handler.GetTransferLimits)
+	r.Put("/api/admin/users/{username}/transfer-limits", handler.SetTransferLimitException)
+	r.Delete("/api/admin/users/{username}/transfer-limits", handler.DeleteTransferLimitException)
+	return r
+}
+
+func TestTransferLimitHandler_Get(t *testing.T) {
+	mockService := new(MockTransferLimitService)
+	r := newTransferLimitRouter(handlers.NewTransferLimitHandler(mockService))
+
+	daily := 2000
+	mockService.On("GetLimits", mock.Anything, "alice").Return(&models.UserTransferLimits{
+		Username:  "alice",
+		Limits:    models.TransferLimits{MaxPerTransfer: 200, DailyLimit: 2000},
+		Defaults:  models.TransferLimits{MaxPerTransfer: 200, DailyLimit: 300},
+		Exception: &models.TransferLimitException{Username: "alice", DailyLimit: &daily, Reason: "quarterly awards"},
+	}, nil)
+	mockService.On("GetLimits", mock.Anything, "ghost").Return(nil, models.ErrUserNotFound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/users/alice/transfer-limits", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"limits":{"maxPerTransfer":200,"dailyLimit":2000,"monthlyLimit":0,"recipientLimit":0}`)
+	assert.Contains(t, w.Body.String(), `"reason":"quarterly awards"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/users/ghost/transfer-limits", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTransferLimitHandler_Set(t *testing.T) {
+	mockService := new(MockTransferLimitService)
+	r := newTransferLimitRouter(handlers.NewTransferLimitHandler(mockService))
+
+	mockService.On("SetException", mock.Anything, mock.MatchedBy(func(exception *models.TransferLimitException) bool {
+		return exception.Username == "alice" && exception.CreatedBy == "admin" && exception.Reason == "quarterly awards" &&
+			*exception.DailyLimit == 2000 && exception.MaxPerTransfer == nil && exception.ExpiresAt != nil
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("PUT", "/api/admin/users/alice/transfer-limits",
+		strings.NewReader(`{"dailyLimit": 2000, "reason": "quarterly awards", "expiresAt": "2026-12-31T00:00:00Z"}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"createdBy":"admin"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransferLimitHandler_Set_Invalid(t *testing.T) {
+	mockService := new(MockTransferLimitService)
+	r := newTransferLimitRouter(handlers.NewTransferLimitHandler(mockService))
+
+	mockService.On("SetException", mock.Anything, mock.MatchedBy(func(exception *models.TransferLimitException) bool {
+		return exception.Username == "alice"
+	})).Return(fmt.Errorf("%w: reason must be 1 to 255 characters long", models.ErrInvalidLimitException))
+	mockService.On("SetException", mock.Anything, mock.MatchedBy(func(exception *models.TransferLimitException) bool {
+		return exception.Username == "ghost"
+	})).Return(models.ErrUserNotFound)
+
+	for _, tc := range []struct {
+		username, body string
+		code           int
+	}{
+		{"alice", `{"dailyLimit": 2000}`, http.StatusBadRequest},
+		{"alice", `{`, http.StatusBadRequest},
+		{"ghost", `{"dailyLimit": 2000, "reason": "quarterly awards"}`, http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("PUT", "/api/admin/users/"+tc.username+"/transfer-limits", strings.NewReader(tc.body)))
+		assert.Equal(t, tc.code, w.Code, tc.body)
+	}
+}
+
+func TestTransferLimitHandler_Delete(t *testing.T) {
+	mockService := new(MockTransferLimitService)
+	r := newTransferLimitRouter(handlers.NewTransferLimitHandler(mockService))
+
+	mockService.On("DeleteException", mock.Anything, "alice").Return(nil)
+	mockService.On("DeleteException", mock.Anything, "bob").Return(models.ErrLimitExceptionNotFound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/admin/users/alice/transfer-limits", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/admin/users/bob/transfer-limits", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,84 @@
+//go:build unit
+// +build unit
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/handlers"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTransferReversalRouter(handler *handlers.TransferReversalHandler) http.Handler {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(setEmployeeUsername(r.Context(), "alice")))
+		})
+	})
+	r.Post("/api/transfers/{id}/cancel", handler.CancelTransfer)
+	r.Post("/api/admin/transfers/{id}/reverse", handler.ReverseTransfer)
+	return r
+}
+
+func TestTransferReversalHandler_Cancel(t *testing.T) {
+	mockService := new(MockTransferReversalService)
+	r := newTransferReversalRouter(handlers.NewTransferReversalHandler(mockService))
+
+	mockService.On("CancelTransfer", mock.Anything, "alice", int64(42)).
+		Return(&models.TransferReversal{ID: 43, TransferID: 42, FromUser: "alice", ToUser: "bob", Amount: 50, ReversedBy: "alice"}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/transfers/42/cancel", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"transferId":42`)
+}
+
+func TestTransferReversalHandler_Cancel_Errors(t *testing.T) {
+	mockService := new(MockTransferReversalService)
+	r := newTransferReversalRouter(handlers.NewTransferReversalHandler(mockService))
+
+	mockService.On("CancelTransfer", mock.Anything, "alice", int64(1)).Return(nil, models.ErrTransferNotFound)
+	mockService.On("CancelTransfer", mock.Anything, "alice", int64(2)).Return(nil, models.ErrTransferAlreadyReversed)
+	mockService.On("CancelTransfer", mock.Anything, "alice", int64(3)).
+		Return(nil, fmt.Errorf("%w: bob has spent coins since the transfer", models.ErrTransferNotReversible))
+
+	for path, code := range map[string]int{
+		"/api/transfers/1/cancel":   http.StatusNotFound,
+		"/api/transfers/2/cancel":   http.StatusConflict,
+		"/api/transfers/3/cancel":   http.StatusConflict,
+		"/api/transfers/abc/cancel": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		assert.Equal(t, code, w.Code, path)
+	}
+}
+
+func TestTransferReversalHandler_Reverse(t *testing.T) {
+	mockService := new(MockTransferReversalService)
+	r := newTransferReversalRouter(handlers.NewTransferReversalHandler(mockService))
+
+	mockService.On("ReverseTransfer", mock.Anything, "alice", int64(42), "sent to the wrong bob").
+		Return(&models.TransferReversal{ID: 43, TransferID: 42, ReversedBy: "alice", Reason: "sent to the wrong bob"}, nil)
+	mockService.On("ReverseTransfer", mock.Anything, "alice", int64(42), "").
+		Return(nil, fmt.Errorf("%w: reason must be 1 to 200 characters long", models.ErrInvalidReversal))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/transfers/42/reverse", strings.NewReader(`{"reason": "sent to the wrong bob"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason":"sent to the wrong bob"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/transfers/42/reverse", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -4,45 +4,482 @@
 package auth
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"github.com/avito-shop-service/pkg/auth"
 	"github.com/golang-jwt/jwt/v4"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTokenManager(t *testing.T, clock auth.Clock) *auth.JWTManager {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("test-key", privateKey)
+	assert.NoError(t, err)
+	return auth.NewJWTManager(&auth.Config{Keys: keys, AccessTokenTTL: 15 * time.Minute}, clock)
+}
+
 func TestGenerateToken(t *testing.T) {
-	token, err := auth.GenerateToken("testuser")
+	manager := newTokenManager(t, nil)
+	token, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 }
 
 func TestParseToken_ValidToken(t *testing.T) {
-	token, err := auth.GenerateToken("testuser")
+	manager := newTokenManager(t, nil)
+	token, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
 	assert.NoError(t, err)
-	claims, err := auth.ParseToken(token)
+	claims, err := manager.ParseToken(token)
 	assert.NoError(t, err)
 	assert.Equal(t, "testuser", claims.EmployeeUsername)
+	assert.NotEmpty(t, claims.ID)
 }
 
 func TestParseToken_InvalidToken(t *testing.T) {
-	claims, err := auth.ParseToken("invalid.token.value")
+	manager := newTokenManager(t, nil)
+	claims, err := manager.ParseToken("invalid.token.value")
 	assert.Error(t, err)
 	assert.Nil(t, claims)
 }
 
 func TestParseToken_ExpiredToken(t *testing.T) {
-	claims := auth.Claims{
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	manager := newTokenManager(t, clock)
+
+	tokenStr, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	clock.now = clock.now.Add(14 * time.Minute)
+	_, err = manager.ParseToken(tokenStr)
+	assert.NoError(t, err)
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	parsedClaims, err := manager.ParseToken(tokenStr)
+	assert.Error(t, err)
+	assert.Nil(t, parsedClaims)
+}
+
+func TestGenerateToken_HasKeyID(t *testing.T) {
+	manager := newTokenManager(t, nil)
+	tokenStr, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenStr, &auth.Claims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "test-key", token.Header["kid"])
+	assert.Equal(t, "EdDSA", token.Header["alg"])
+}
+
+func TestParseToken_UnknownKeyID(t *testing.T) {
+	manager := newTokenManager(t, nil)
+	other := newTokenManager(t, nil)
+
+	tokenStr, err := other.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseToken(tokenStr)
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestParseToken_RejectsSymmetricToken(t *testing.T) {
+	manager := newTokenManager(t, nil)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		EmployeeUsername: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	token.Header["kid"] = "test-key"
+	tokenStr, err := token.SignedString([]byte("XgrTyjawr67cnh83"))
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseToken(tokenStr)
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+}
+
+func TestParseToken_RotatedKey(t *testing.T) {
+	oldPrivateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	oldKeys, err := auth.NewKeySet("old-key", oldPrivateKey)
+	assert.NoError(t, err)
+	oldManager := auth.NewJWTManager(&auth.Config{Keys: oldKeys}, nil)
+
+	tokenStr, err := oldManager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	_, newPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	newKeys, err := auth.NewKeySet("new-key", newPrivateKey)
+	assert.NoError(t, err)
+	assert.NoError(t, newKeys.AddVerificationKey("old-key", &oldPrivateKey.PublicKey))
+	newManager := auth.NewJWTManager(&auth.Config{Keys: newKeys}, nil)
+
+	claims, err := newManager.ParseToken(tokenStr)
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", claims.EmployeeUsername)
+}
+
+func TestJWKS_ContainsVerificationKeys(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("b-signing", privateKey)
+	assert.NoError(t, err)
+	assert.NoError(t, keys.AddVerificationKey("a-previous", &rsaKey.PublicKey))
+
+	jwks := keys.JWKS()
+
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "a-previous", jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.Equal(t, "b-signing", jwks.Keys[1].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Alg)
+	assert.NotEmpty(t, jwks.Keys[1].X)
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "signing.pem"), "PRIVATE KEY", privateKeyBytes)
+
+	oldPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(oldPublicKey)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "previous.pub.pem"), "PUBLIC KEY", publicKeyBytes)
+
+	configPath := filepath.Join(dir, "config.yaml")
+	err = os.WriteFile(configPath, []byte(`access_token_ttl: "5m"
+jwt_signing_key_id: "current"
+jwt_keys:
+  - kid: "current"
+    private_key_path: "signing.pem"
+  - kid: "previous"
+    public_key_path: "previous.pub.pem"
+`), 0o600)
+	assert.NoError(t, err)
+
+	cfg, err := auth.LoadConfig(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.AccessTokenTTL)
+	assert.Len(t, cfg.Keys.JWKS().Keys, 2)
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	_, err := auth.LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_RepositoryConfig(t *testing.T) {
+	dir := t.TempDir()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "jwt_signing_2026_10_17.pem"), "PRIVATE KEY", privateKeyBytes)
+	t.Setenv("JWT_KEY_DIR", dir)
+
+	cfg, err := auth.LoadConfig("../../pkg/auth/jwt_key/config.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, cfg.AccessTokenTTL)
+	assert.Len(t, cfg.Keys.JWKS().Keys, 1)
+}
+
+func TestLoadConfig_KeyDirMissingKey(t *testing.T) {
+	t.Setenv("JWT_KEY_DIR", t.TempDir())
+
+	_, err := auth.LoadConfig("../../pkg/auth/jwt_key/config.yaml")
+	assert.Error(t, err)
+}
+
+func writePEM(t *testing.T, path, blockType string, data []byte) {
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600)
+	assert.NoError(t, err)
+}
+
+func TestParseToken_RolesAndScopes(t *testing.T) {
+	manager := newTokenManager(t, nil)
+	token, err := manager.IssueToken(auth.Claims{
 		EmployeeUsername: "testuser",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
-		},
+		Roles:            []string{auth.RoleMerchAdmin},
+		Scopes:           []string{auth.ScopeMerchWrite},
+	})
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseToken(token)
+	assert.NoError(t, err)
+	assert.True(t, claims.HasRole(auth.RoleMerchAdmin))
+	assert.False(t, claims.HasRole(auth.RoleFinanceAdmin))
+	assert.True(t, claims.HasScope(auth.ScopeMerchWrite))
+	assert.False(t, claims.HasScope(auth.ScopeCoinsAdmin))
+}
+
+func TestResolveScopes(t *testing.T) {
+	scopes := auth.ResolveScopes([]string{auth.RoleEmployee, auth.RoleMerchAdmin, "unknown"}, []string{auth.ScopeMerchBuy, "reports:read"})
+
+	assert.Equal(t, []string{auth.ScopeInfoRead, auth.ScopeCoinsSend, auth.ScopeMerchBuy, auth.ScopeMerchWrite, "reports:read"}, scopes)
+	assert.True(t, auth.IsKnownRole(auth.RoleFinanceAdmin))
+	assert.False(t, auth.IsKnownRole("unknown"))
+}
+
+func TestJWKS_KeyFunc(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("ed", edKey)
+	assert.NoError(t, err)
+	assert.NoError(t, keys.AddVerificationKey("rsa", &rsaKey.PublicKey))
+	jwks := keys.JWKS()
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "alice"})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return signed
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString(auth.JwtSecretKey)
+
+	_, err = jwt.Parse(sign(jwt.SigningMethodRS256, "rsa", rsaKey), jwks.KeyFunc)
+	assert.NoError(t, err)
+	_, err = jwt.Parse(sign(jwt.SigningMethodEdDSA, "ed", edKey), jwks.KeyFunc)
 	assert.NoError(t, err)
-	parsedClaims, err := auth.ParseToken(tokenStr)
+
+	_, err = jwt.Parse(sign(jwt.SigningMethodEdDSA, "unknown", edKey), jwks.KeyFunc)
 	assert.Error(t, err)
-	assert.Nil(t, parsedClaims)
+	_, err = jwt.Parse(sign(jwt.SigningMethodHS256, "rsa", []byte("public key as hmac secret")), jwks.KeyFunc)
+	assert.Error(t, err, "the algorithm must match the key type")
+}
+
+func newStrictTokenManager(t *testing.T, clock auth.Clock, configure func(cfg *auth.Config)) *auth.JWTManager {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("test-key", privateKey)
+	assert.NoError(t, err)
+	cfg := &auth.Config{Keys: keys, AccessTokenTTL: 15 * time.Minute, Issuer: "shop", Audience: []string{"shop"}}
+	if configure != nil {
+		configure(cfg)
+	}
+	return auth.NewJWTManager(cfg, clock)
+}
+
+func TestParseToken_IssuerAndAudience(t *testing.T) {
+	issuer := newStrictTokenManager(t, nil, nil)
+	tokenStr, err := issuer.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	claims, err := issuer.ParseToken(tokenStr)
+	assert.NoError(t, err)
+	assert.Equal(t, "shop", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"shop"}, claims.Audience)
+
+	// The same keys, but a different expected issuer or audience.
+	otherIssuer := auth.NewJWTManager(&auth.Config{Keys: issuerKeys(t, issuer), Issuer: "billing", Audience: []string{"shop"}}, nil)
+	_, err = otherIssuer.ParseToken(tokenStr)
+	assert.ErrorIs(t, err, auth.ErrTokenIssuer)
+
+	otherAudience := auth.NewJWTManager(&auth.Config{Keys: issuerKeys(t, issuer), Issuer: "shop", Audience: []string{"billing", "reports"}}, nil)
+	_, err = otherAudience.ParseToken(tokenStr)
+	assert.ErrorIs(t, err, auth.ErrTokenAudience)
+}
+
+// issuerKeys returns a key set that verifies tokens of the manager, taken from its JWKS.
+func issuerKeys(t *testing.T, manager *auth.JWTManager) *auth.KeySet {
+	_, unusedKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("unused", unusedKey)
+	assert.NoError(t, err)
+	for _, jwk := range manager.JWKS().Keys {
+		publicKey, err := jwk.PublicKey()
+		assert.NoError(t, err)
+		assert.NoError(t, keys.AddVerificationKey(jwk.Kid, publicKey))
+	}
+	return keys
+}
+
+func TestParseToken_AlgorithmAllowList(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("rsa-key", rsaKey)
+	assert.NoError(t, err)
+
+	rsaManager := auth.NewJWTManager(&auth.Config{Keys: keys}, nil)
+	tokenStr, err := rsaManager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+	_, err = rsaManager.ParseToken(tokenStr)
+	assert.NoError(t, err)
+
+	eddsaOnly := auth.NewJWTManager(&auth.Config{Keys: keys, AllowedAlgorithms: []string{"EdDSA"}}, nil)
+	_, err = eddsaOnly.ParseToken(tokenStr)
+	assert.ErrorIs(t, err, auth.ErrTokenAlgorithm)
+}
+
+func TestParseToken_TypedErrors(t *testing.T) {
+	manager := newTokenManager(t, nil)
+	other := newTokenManager(t, nil)
+
+	tokenStr, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+	foreignStr, err := other.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+	parts := strings.Split(tokenStr, ".")
+	foreignParts := strings.Split(foreignStr, ".")
+
+	_, unknownKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	unknownKeys, err := auth.NewKeySet("unknown-key", unknownKey)
+	assert.NoError(t, err)
+	unknownStr, err := auth.NewJWTManager(&auth.Config{Keys: unknownKeys}, nil).IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	symmetric := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	symmetric.Header["kid"] = "test-key"
+	symmetricStr, err := symmetric.SignedString([]byte("secret"))
+	assert.NoError(t, err)
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	unsigned.Header["kid"] = "test-key"
+	unsignedStr, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"malformed", "invalid.token.value", auth.ErrTokenMalformed},
+		{"unknown key", unknownStr, auth.ErrTokenUnknownKey},
+		{"forged signature", parts[0] + "." + parts[1] + "." + foreignParts[2], auth.ErrTokenSignature},
+		{"symmetric algorithm", symmetricStr, auth.ErrTokenAlgorithm},
+		{"no signature", unsignedStr, auth.ErrTokenAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := manager.ParseToken(tt.token)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
+func TestParseToken_Leeway(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	manager := newStrictTokenManager(t, clock, func(cfg *auth.Config) { cfg.Leeway = 30 * time.Second })
+
+	tokenStr, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	clock.now = clock.now.Add(15*time.Minute + 20*time.Second)
+	_, err = manager.ParseToken(tokenStr)
+	assert.NoError(t, err, "expiry within the leeway is tolerated")
+
+	clock.now = clock.now.Add(20 * time.Second)
+	_, err = manager.ParseToken(tokenStr)
+	assert.ErrorIs(t, err, auth.ErrTokenExpired)
+}
+
+func TestParseToken_NotYetValid(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	manager := newStrictTokenManager(t, clock, func(cfg *auth.Config) { cfg.Leeway = 30 * time.Second })
+
+	tokenStr, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	clock.now = clock.now.Add(-20 * time.Second)
+	_, err = manager.ParseToken(tokenStr)
+	assert.NoError(t, err, "a clock behind the issuer within the leeway is tolerated")
+
+	clock.now = clock.now.Add(-time.Minute)
+	_, err = manager.ParseToken(tokenStr)
+	assert.ErrorIs(t, err, auth.ErrTokenNotYetValid)
+}
+
+func TestParseToken_MaxAge(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	manager := newStrictTokenManager(t, clock, func(cfg *auth.Config) {
+		cfg.AccessTokenTTL = 24 * time.Hour
+		cfg.MaxAge = time.Hour
+	})
+
+	tokenStr, err := manager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	clock.now = clock.now.Add(59 * time.Minute)
+	_, err = manager.ParseToken(tokenStr)
+	assert.NoError(t, err)
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	_, err = manager.ParseToken(tokenStr)
+	assert.ErrorIs(t, err, auth.ErrTokenTooOld)
+}
+
+func TestLoadConfig_Validation(t *testing.T) {
+	dir := t.TempDir()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "signing.pem"), "PRIVATE KEY", privateKeyBytes)
+
+	load := func(extra string) (*auth.Config, error) {
+		configPath := filepath.Join(dir, "config.yaml")
+		err := os.WriteFile(configPath, []byte(`jwt_signing_key_id: "current"
+jwt_keys:
+  - kid: "current"
+    private_key_path: "signing.pem"
+`+extra), 0o600)
+		assert.NoError(t, err)
+		return auth.LoadConfig(configPath)
+	}
+
+	cfg, err := load(`jwt_issuer: "shop"
+jwt_audience: ["shop", "reports"]
+jwt_allowed_algorithms: ["EdDSA"]
+jwt_leeway: "30s"
+jwt_max_age: "1h"
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "shop", cfg.Issuer)
+	assert.Equal(t, []string{"shop", "reports"}, cfg.Audience)
+	assert.Equal(t, []string{"EdDSA"}, cfg.AllowedAlgorithms)
+	assert.Equal(t, 30*time.Second, cfg.Leeway)
+	assert.Equal(t, time.Hour, cfg.MaxAge)
+
+	_, err = load(`jwt_allowed_algorithms: ["HS256"]`)
+	assert.Error(t, err, "symmetric algorithms are not supported")
+	_, err = load(`jwt_allowed_algorithms: ["RS256"]`)
+	assert.Error(t, err, "the signing key must use an allowed algorithm")
+	_, err = load(`jwt_max_age: "5m"`)
+	assert.Error(t, err, "max age must not be shorter than the token lifetime")
 }
@@ -0,0 +1,189 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func newAPIKeyService(repo *MockAPIKeyRepository, userRepo *MockUserRepository, clock *fakeClock) *services.APIKeyService {
+	return services.NewAPIKeyService(repo, userRepo, services.NewRateLimiter(clock), 0, clock)
+}
+
+func TestCreateAPIKey_Success(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := newAPIKeyService(mockRepo, mockUserRepo, &fakeClock{now: time.Now()})
+
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser", Roles: []string{"employee"}}, nil)
+	var stored *models.APIKey
+	mockRepo.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*models.APIKey)
+	}).Return(nil)
+
+	key, err := service.CreateAPIKey(context.Background(), "testuser", models.APIKey{Name: "review-bot", Scopes: []string{"coins:send"}})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key.Key, "ask_"))
+	assert.Equal(t, key.Key[:12], key.Prefix)
+	assert.Equal(t, hashKey(key.Key), stored.KeyHash)
+	assert.Equal(t, int64(1), stored.UserID)
+	assert.Equal(t, services.DefaultAPIKeyRateLimit, stored.RateLimit)
+}
+
+func TestCreateAPIKey_ScopesMustBeGranted(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := newAPIKeyService(mockRepo, mockUserRepo, &fakeClock{now: time.Now()})
+
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser", Roles: []string{"employee"}}, nil)
+
+	_, err := service.CreateAPIKey(context.Background(), "testuser", models.APIKey{Name: "bot", Scopes: []string{"coins:send", "coins:admin"}})
+	assert.ErrorIs(t, err, models.ErrInvalidScopes)
+
+	_, err = service.CreateAPIKey(context.Background(), "testuser", models.APIKey{Name: "bot"})
+	assert.ErrorIs(t, err, models.ErrInvalidScopes)
+
+	mockRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestCreateAPIKey_RateLimitAboveMaximum(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := newAPIKeyService(mockRepo, mockUserRepo, &fakeClock{now: time.Now()})
+
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser", Roles: []string{"employee"}}, nil)
+	mockRepo.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("*models.APIKey")).Return(nil)
+
+	_, err := service.CreateAPIKey(context.Background(), "testuser", models.APIKey{Name: "bot", Scopes: []string{"coins:send"}, RateLimit: services.DefaultAPIKeyRateLimit + 1})
+	assert.ErrorIs(t, err, models.ErrInvalidRateLimit)
+	mockRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+
+	key, err := service.CreateAPIKey(context.Background(), "testuser", models.APIKey{Name: "bot", Scopes: []string{"coins:send"}, RateLimit: services.DefaultAPIKeyRateLimit})
+	assert.NoError(t, err)
+	assert.Equal(t, services.DefaultAPIKeyRateLimit, key.RateLimit)
+}
+
+func TestValidateAPIKey_Success(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	service := newAPIKeyService(mockRepo, mockUserRepo, clock)
+
+	apiKey := &models.APIKey{ID: 3, UserID: 1, Scopes: []string{"coins:send", "merch:write"}, RateLimit: 10}
+	mockRepo.On("GetAPIKeyByHash", mock.Anything, hashKey("ask_secret")).Return(apiKey, nil)
+	mockRepo.On("UpdateAPIKeyLastUsed", mock.Anything, int64(3), clock.now).Return(nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Username: "testuser", Roles: []string{"employee"}}, nil)
+
+	claims, err := service.ValidateAPIKey(context.Background(), "ask_secret")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", claims.EmployeeUsername)
+	assert.Equal(t, int64(3), claims.APIKeyID)
+	assert.Equal(t, []string{"coins:send"}, claims.Scopes, "scopes the owner lost must be dropped")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestValidateAPIKey_LastUsedIsNotUpdatedTooOften(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	service := newAPIKeyService(mockRepo, mockUserRepo, clock)
+
+	lastUsed := clock.now.Add(-10 * time.Second)
+	mockRepo.On("GetAPIKeyByHash", mock.Anything, hashKey("ask_secret")).Return(&models.APIKey{ID: 3, UserID: 1, RateLimit: 10, LastUsedAt: &lastUsed}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Username: "testuser"}, nil)
+
+	_, err := service.ValidateAPIKey(context.Background(), "ask_secret")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "UpdateAPIKeyLastUsed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestValidateAPIKey_Invalid(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	service := newAPIKeyService(mockRepo, mockUserRepo, clock)
+
+	revokedAt := clock.now.Add(-time.Hour)
+	expiresAt := clock.now.Add(-time.Minute)
+	mockRepo.On("GetAPIKeyByHash", mock.Anything, hashKey("ask_unknown")).Return((*models.APIKey)(nil), nil)
+	mockRepo.On("GetAPIKeyByHash", mock.Anything, hashKey("ask_revoked")).Return(&models.APIKey{ID: 1, RevokedAt: &revokedAt}, nil)
+	mockRepo.On("GetAPIKeyByHash", mock.Anything, hashKey("ask_expired")).Return(&models.APIKey{ID: 2, ExpiresAt: &expiresAt}, nil)
+
+	for _, key := range []string{"not-a-key", "ask_unknown", "ask_revoked", "ask_expired"} {
+		_, err := service.ValidateAPIKey(context.Background(), key)
+		assert.ErrorIs(t, err, models.ErrInvalidAPIKey, key)
+	}
+	mockRepo.AssertNotCalled(t, "GetAPIKeyByHash", mock.Anything, hashKey("not-a-key"))
+}
+
+func TestValidateAPIKey_RateLimit(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 15, 0, time.UTC)}
+	service := newAPIKeyService(mockRepo, mockUserRepo, clock)
+
+	mockRepo.On("GetAPIKeyByHash", mock.Anything, hashKey("ask_secret")).Return(&models.APIKey{ID: 3, UserID: 1, RateLimit: 2}, nil)
+	mockRepo.On("UpdateAPIKeyLastUsed", mock.Anything, int64(3), mock.Anything).Return(nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Username: "testuser"}, nil)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.ValidateAPIKey(context.Background(), "ask_secret")
+		assert.NoError(t, err)
+	}
+
+	_, err := service.ValidateAPIKey(context.Background(), "ask_secret")
+	var rateLimitedErr *models.RateLimitedError
+	assert.ErrorAs(t, err, &rateLimitedErr)
+	assert.Equal(t, 45*time.Second, rateLimitedErr.RetryAfter)
+
+	clock.now = clock.now.Add(45 * time.Second)
+	_, err = service.ValidateAPIKey(context.Background(), "ask_secret")
+	assert.NoError(t, err, "the limit must reset in the next window")
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := newAPIKeyService(mockRepo, mockUserRepo, &fakeClock{now: time.Now()})
+
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockRepo.On("RevokeAPIKey", mock.Anything, int64(1), int64(3)).Return(nil)
+	mockRepo.On("RevokeAPIKey", mock.Anything, int64(1), int64(4)).Return(models.ErrAPIKeyNotFound)
+
+	assert.NoError(t, service.RevokeAPIKey(context.Background(), "testuser", 3))
+	assert.ErrorIs(t, service.RevokeAPIKey(context.Background(), "testuser", 4), models.ErrAPIKeyNotFound)
+}
+
+func TestValidateAPIKey_InactiveOwner(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := newAPIKeyService(mockRepo, mockUserRepo, &fakeClock{now: time.Now()})
+
+	mockRepo.On("GetAPIKeyByHash", mock.Anything, hashKey("ask_secret")).Return(&models.APIKey{ID: 3, UserID: 1, RateLimit: 10}, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).
+		Return(&models.User{ID: 1, Username: "testuser", Profile: models.Profile{Status: models.UserStatusSuspended}}, nil)
+
+	_, err := service.ValidateAPIKey(context.Background(), "ask_secret")
+
+	assert.ErrorIs(t, err, models.ErrAccountInactive)
+	mockRepo.AssertNotCalled(t, "UpdateAPIKeyLastUsed", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,60 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCoinExpiryService_GetUpcomingExpirations(t *testing.T) {
+	mockRepo := new(MockCoinLotRepository)
+	service := services.NewCoinExpiryService(mockRepo, 0, &fakeClock{now: time.Now()})
+
+	mockRepo.On("GetOpenLots", mock.Anything, int64(1), 10).Return([]models.CoinLot{
+		{ID: 1, Amount: 1000, Remaining: 300, GrantedAt: time.Date(2025, 11, 1, 9, 0, 0, 0, time.UTC)},
+		{ID: 5, Amount: 200, Remaining: 200, GrantedAt: time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	expirations, err := service.GetUpcomingExpirations(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.CoinExpiration{
+		{Amount: 300, ExpiresAt: time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)},
+		{Amount: 200, ExpiresAt: time.Date(2027, 3, 15, 12, 0, 0, 0, time.UTC)},
+	}, expirations)
+}
+
+func TestCoinExpiryService_ExpireCoins(t *testing.T) {
+	mockRepo := new(MockCoinLotRepository)
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	service := services.NewCoinExpiryService(mockRepo, 6, &fakeClock{now: now})
+
+	cutoff := time.Date(2026, 4, 17, 15, 30, 0, 0, time.UTC)
+	description := "coins granted before 2026-04-17 expired"
+	mockRepo.On("GetUsersWithLotsGrantedBefore", mock.Anything, cutoff, 100).Return([]int64{1, 2}, nil)
+	mockRepo.On("ExpireLots", mock.Anything, int64(1), cutoff, description).Return(300, nil)
+	mockRepo.On("ExpireLots", mock.Anything, int64(2), cutoff, description).Return(0, nil)
+
+	assert.NoError(t, service.ExpireCoins(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCoinExpiryService_ExpireCoins_Error(t *testing.T) {
+	mockRepo := new(MockCoinLotRepository)
+	service := services.NewCoinExpiryService(mockRepo, 12, &fakeClock{now: time.Now()})
+
+	mockRepo.On("GetUsersWithLotsGrantedBefore", mock.Anything, mock.Anything, 100).Return([]int64{1, 2}, nil)
+	mockRepo.On("ExpireLots", mock.Anything, int64(1), mock.Anything, mock.Anything).Return(0, errors.New("db error"))
+	mockRepo.On("ExpireLots", mock.Anything, int64(2), mock.Anything, mock.Anything).Return(100, nil)
+
+	assert.EqualError(t, service.ExpireCoins(context.Background()), "user 1: db error")
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// fakeLDAPServer is an in-process LDAP server that understands simple binds and equality
+// searches, which is all LDAPAuthenticator uses.
+type fakeLDAPServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	entries  map[string]fakeLDAPEntry
+	binds    []string
+}
+
+type fakeLDAPEntry struct {
+	password   string
+	attributes map[string]string
+}
+
+func newFakeLDAPServer(t *testing.T) *fakeLDAPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting fake ldap server: %v", err)
+	}
+	server := &fakeLDAPServer{listener: listener, entries: make(map[string]fakeLDAPEntry)}
+	go server.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return server
+}
+
+func (s *fakeLDAPServer) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *fakeLDAPServer) AddEntry(dn, password string, attributes map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[dn] = fakeLDAPEntry{password: password, attributes: attributes}
+}
+
+// Binds returns the DNs of successful binds.
+func (s *fakeLDAPServer) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *fakeLDAPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeLDAPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	boundDN := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := request.Children[1].Value.(string)
+			password := request.Children[2].Data.String()
+			code := s.bind(dn, password)
+			if code == ldap.LDAPResultSuccess {
+				boundDN = dn
+			}
+			s.write(conn, messageID, ldap.ApplicationBindResponse, code)
+		case ldap.ApplicationSearchRequest:
+			if boundDN == "" {
+				s.write(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights)
+				continue
+			}
+			filter, err := ldap.DecompileFilter(request.Children[6])
+			if err != nil {
+				s.write(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError)
+				continue
+			}
+			for dn, entry := range s.search(filter) {
+				s.writeEntry(conn, messageID, dn, entry)
+			}
+			s.write(conn, messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			s.write(conn, messageID, request.Tag+1, ldap.LDAPResultUnwillingToPerform)
+		}
+	}
+}
+
+func (s *fakeLDAPServer) bind(dn, password string) uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[dn]
+	if !ok || password == "" || entry.password != password {
+		return ldap.LDAPResultInvalidCredentials
+	}
+	s.binds = append(s.binds, dn)
+	return ldap.LDAPResultSuccess
+}
+
+// search supports filters of the form (attribute=value).
+func (s *fakeLDAPServer) search(filter string) map[string]fakeLDAPEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found := make(map[string]fakeLDAPEntry)
+	attribute, value, ok := strings.Cut(strings.Trim(filter, "()"), "=")
+	if !ok {
+		return found
+	}
+	for dn, entry := range s.entries {
+		if entry.attributes[attribute] == value {
+			found[dn] = entry
+		}
+	}
+	return found
+}
+
+func (s *fakeLDAPServer) write(conn net.Conn, messageID int64, tag ber.Tag, code uint16) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, fmt.Sprintf("code %d", code), "Diagnostic Message"))
+	s.send(conn, messageID, response)
+}
+
+func (s *fakeLDAPServer) writeEntry(conn net.Conn, messageID int64, dn string, entry fakeLDAPEntry) {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "DN"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, value := range entry.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		values.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		attribute.AppendChild(values)
+		attributes.AppendChild(attribute)
+	}
+	response.AppendChild(attributes)
+	s.send(conn, messageID, response)
+}
+
+func (s *fakeLDAPServer) send(conn net.Conn, messageID int64, response *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	packet.AppendChild(response)
+	_, _ = conn.Write(packet.Bytes())
+}
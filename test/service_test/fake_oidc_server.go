@@ -0,0 +1,138 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	oidcClientID     = "shop"
+	oidcClientSecret = "shop-secret"
+	oidcRedirectURL  = "https://shop.example.com/api/auth/oidc/callback"
+	oidcKid          = "idp-key"
+)
+
+// fakeOIDCServer is an in-process OpenID Connect provider that serves discovery, token and
+// JWKS endpoints for codes issued with IssueCode.
+type fakeOIDCServer struct {
+	server     *httptest.Server
+	privateKey ed25519.PrivateKey
+	keys       *auth.KeySet
+
+	mu    sync.Mutex
+	codes map[string]fakeOIDCGrant
+	// Claims overrides claims of the issued ID tokens.
+	Claims jwt.MapClaims
+}
+
+type fakeOIDCGrant struct {
+	subject       string
+	username      string
+	nonce         string
+	codeChallenge string
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet(oidcKid, privateKey)
+	assert.NoError(t, err)
+
+	s := &fakeOIDCServer{privateKey: privateKey, keys: keys, codes: make(map[string]fakeOIDCGrant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(s.keys.JWKS())
+	})
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *fakeOIDCServer) Issuer() string {
+	return s.server.URL
+}
+
+// IssueCode returns an authorization code as if the user had signed in at the provider.
+func (s *fakeOIDCServer) IssueCode(subject, username, nonce, codeVerifier string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := fmt.Sprintf("code-%d", len(s.codes)+1)
+	s.codes[code] = fakeOIDCGrant{subject: subject, username: username, nonce: nonce, codeChallenge: codeChallenge(codeVerifier)}
+	return code
+}
+
+func (s *fakeOIDCServer) discovery(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 s.Issuer(),
+		"authorization_endpoint": s.Issuer() + "/authorize",
+		"token_endpoint":         s.Issuer() + "/token",
+		"jwks_uri":               s.Issuer() + "/jwks",
+	})
+}
+
+func (s *fakeOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != oidcClientID || clientSecret != oidcClientSecret {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	s.mu.Lock()
+	grant, ok := s.codes[r.PostFormValue("code")]
+	delete(s.codes, r.PostFormValue("code"))
+	s.mu.Unlock()
+
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != oidcRedirectURL ||
+		codeChallenge(r.PostFormValue("code_verifier")) != grant.codeChallenge {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                s.Issuer(),
+		"aud":                oidcClientID,
+		"sub":                grant.subject,
+		"preferred_username": grant.username,
+		"nonce":              grant.nonce,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+	}
+	for name, value := range s.Claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = oidcKid
+	idToken, err := token.SignedString(s.privateKey)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "opaque", "token_type": "Bearer", "id_token": idToken})
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyBegin_NewKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	service := services.NewIdempotencyService(mockRepo, time.Hour, clock)
+
+	mockRepo.On("CreateIdempotencyRecord", mock.Anything, mock.MatchedBy(func(record *models.IdempotencyRecord) bool {
+		return record.Username == "testuser" && record.Key == "key-1" && record.Fingerprint == "fp" &&
+			record.ExpiresAt.Equal(clock.now.Add(time.Hour))
+	})).Return(true, nil)
+
+	stored, err := service.Begin(context.Background(), "testuser", "key-1", "fp")
+
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	mockRepo.AssertNotCalled(t, "GetIdempotencyRecord", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyBegin_ReplaysCompletedRequest(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	clock := &fakeClock{now: time.Now()}
+	service := services.NewIdempotencyService(mockRepo, 0, clock)
+
+	completed := &models.IdempotencyRecord{Username: "testuser", Key: "key-1", Fingerprint: "fp", StatusCode: 200, Body: []byte("ok")}
+	mockRepo.On("CreateIdempotencyRecord", mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("GetIdempotencyRecord", mock.Anything, "testuser", "key-1", clock.now).Return(completed, nil)
+
+	stored, err := service.Begin(context.Background(), "testuser", "key-1", "fp")
+
+	assert.NoError(t, err)
+	assert.Equal(t, completed, stored)
+}
+
+func TestIdempotencyBegin_Rejections(t *testing.T) {
+	for name, tc := range map[string]struct {
+		existing    *models.IdempotencyRecord
+		fingerprint string
+		err         error
+	}{
+		"different payload": {&models.IdempotencyRecord{Fingerprint: "fp", StatusCode: 200}, "other", models.ErrIdempotencyMismatch},
+		"in progress":       {&models.IdempotencyRecord{Fingerprint: "fp"}, "fp", models.ErrIdempotencyConflict},
+		"released":          {nil, "fp", models.ErrIdempotencyConflict},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockIdempotencyRepository)
+			service := services.NewIdempotencyService(mockRepo, time.Hour, &fakeClock{now: time.Now()})
+
+			mockRepo.On("CreateIdempotencyRecord", mock.Anything, mock.Anything).Return(false, nil)
+			mockRepo.On("GetIdempotencyRecord", mock.Anything, "testuser", "key-1", mock.Anything).Return(tc.existing, nil)
+
+			_, err := service.Begin(context.Background(), "testuser", "key-1", tc.fingerprint)
+
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestIdempotencyBegin_InvalidKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := services.NewIdempotencyService(mockRepo, time.Hour, nil)
+
+	_, err := service.Begin(context.Background(), "testuser", strings.Repeat("k", 256), "fp")
+
+	assert.ErrorIs(t, err, models.ErrInvalidIdempotency)
+	mockRepo.AssertNotCalled(t, "CreateIdempotencyRecord", mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyPurgeExpired(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	service := services.NewIdempotencyService(mockRepo, time.Hour, clock)
+
+	mockRepo.On("DeleteExpiredIdempotencyRecords", mock.Anything, clock.now).Return(int64(3), nil)
+
+	assert.NoError(t, service.PurgeExpired(context.Background()))
+	mockRepo.AssertExpectations(t)
+}
@@ -262,3 +262,32 @@ func TestRemoveItemFromInventory_DBError(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestBuyItemToInventory_InsufficientFunds(t *testing.T) {
+	mockRepo := new(MockInventoryRepository)
+	service := services.NewInventoryService(mockRepo)
+
+	mockRepo.On("BuyItemToInventory", mock.Anything, int64(1), int64(2), 1, 500).
+		Return(&models.InsufficientFundsError{Balance: 100, Required: 500})
+
+	err := service.BuyItemToInventory(context.Background(), 1, 2, 1, 500)
+
+	var fundsErr *models.InsufficientFundsError
+	assert.ErrorAs(t, err, &fundsErr)
+	assert.Equal(t, 100, fundsErr.Balance)
+	assert.ErrorIs(t, err, models.ErrInsufficientFunds)
+}
+
+func TestGetPurchasesByUserID(t *testing.T) {
+	mockRepo := new(MockInventoryRepository)
+	service := services.NewInventoryService(mockRepo)
+
+	purchases := []models.Purchase{{ID: 1, UserID: 1, ItemName: "cup", Quantity: 2, UnitPrice: 20}}
+	mockRepo.On("GetPurchasesByUserID", mock.Anything, int64(1)).Return(purchases, nil)
+
+	result, err := service.GetPurchasesByUserID(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, purchases, result)
+	assert.Equal(t, 40, result[0].Total())
+}
@@ -0,0 +1,116 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIssuanceService_Issue(t *testing.T) {
+	mockRepo := new(MockIssuanceRepository)
+	service, err := services.NewIssuanceService(mockRepo, services.AllowancePolicy{}, nil)
+	assert.NoError(t, err)
+
+	mockRepo.On("IssueCoins", mock.Anything, mock.MatchedBy(func(issuance *models.CoinIssuance) bool {
+		return issuance.Department == "platform" && issuance.Reason == "release bonus" && issuance.Amount == 100
+	})).Return(nil)
+
+	err = service.Issue(context.Background(), &models.CoinIssuance{
+		Operation: models.IssuanceGrant, Department: " platform ", Amount: 100, Reason: " release bonus ", IssuedBy: "admin",
+	})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIssuanceService_Issue_Invalid(t *testing.T) {
+	mockRepo := new(MockIssuanceRepository)
+	service, err := services.NewIssuanceService(mockRepo, services.AllowancePolicy{}, nil)
+	assert.NoError(t, err)
+
+	for name, issuance := range map[string]models.CoinIssuance{
+		"unknown operation": {Operation: "print", Username: "bob", Amount: 10, Reason: "because"},
+		"zero amount":       {Operation: models.IssuanceGrant, Username: "bob", Reason: "because"},
+		"negative amount":   {Operation: models.IssuanceBurn, Username: "bob", Amount: -10, Reason: "because"},
+		"no target":         {Operation: models.IssuanceGrant, Amount: 10, Reason: "because"},
+		"both targets":      {Operation: models.IssuanceGrant, Username: "bob", Department: "platform", Amount: 10, Reason: "because"},
+		"no reason":         {Operation: models.IssuanceBurn, Username: "bob", Amount: 10, Reason: "  "},
+	} {
+		err := service.Issue(context.Background(), &issuance)
+		assert.ErrorIs(t, err, models.ErrInvalidIssuance, name)
+	}
+	mockRepo.AssertNotCalled(t, "IssueCoins", mock.Anything, mock.Anything)
+}
+
+func TestNewIssuanceService_InvalidPeriod(t *testing.T) {
+	_, err := services.NewIssuanceService(new(MockIssuanceRepository), services.AllowancePolicy{Amount: 10, Period: "hourly"}, nil)
+	assert.Error(t, err)
+}
+
+func TestIssuanceService_GrantAllowance_Periods(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	for period, name := range map[string]string{
+		"":                      "2026-10",
+		models.AllowanceMonthly: "2026-10",
+		models.AllowanceWeekly:  "2026-W42",
+		models.AllowanceDaily:   "2026-10-17",
+	} {
+		mockRepo := new(MockIssuanceRepository)
+		service, err := services.NewIssuanceService(mockRepo, services.AllowancePolicy{Amount: 50, Period: period}, &fakeClock{now: now})
+		assert.NoError(t, err)
+
+		mockRepo.On("GetUsersWithoutAllowance", mock.Anything, name, 100).Return([]int64{1, 2}, nil)
+		mockRepo.On("GrantAllowance", mock.Anything, int64(1), name, 50).Return(true, nil)
+		mockRepo.On("GrantAllowance", mock.Anything, int64(2), name, 50).Return(false, nil)
+
+		assert.NoError(t, service.GrantAllowance(context.Background()), period)
+		mockRepo.AssertExpectations(t)
+	}
+}
+
+func TestIssuanceService_GrantAllowance_Batches(t *testing.T) {
+	mockRepo := new(MockIssuanceRepository)
+	service, err := services.NewIssuanceService(mockRepo, services.AllowancePolicy{Amount: 50}, &fakeClock{now: time.Now()})
+	assert.NoError(t, err)
+
+	batch := make([]int64, 100)
+	for i := range batch {
+		batch[i] = int64(i + 1)
+	}
+	mockRepo.On("GetUsersWithoutAllowance", mock.Anything, mock.Anything, 100).Return(batch, nil).Once()
+	mockRepo.On("GetUsersWithoutAllowance", mock.Anything, mock.Anything, 100).Return([]int64{101}, nil).Once()
+	mockRepo.On("GrantAllowance", mock.Anything, mock.Anything, mock.Anything, 50).Return(true, nil)
+
+	assert.NoError(t, service.GrantAllowance(context.Background()))
+	mockRepo.AssertNumberOfCalls(t, "GrantAllowance", 101)
+}
+
+func TestIssuanceService_GrantAllowance_Error(t *testing.T) {
+	mockRepo := new(MockIssuanceRepository)
+	service, err := services.NewIssuanceService(mockRepo, services.AllowancePolicy{Amount: 50}, &fakeClock{now: time.Now()})
+	assert.NoError(t, err)
+
+	mockRepo.On("GetUsersWithoutAllowance", mock.Anything, mock.Anything, 100).Return([]int64{1, 2}, nil)
+	mockRepo.On("GrantAllowance", mock.Anything, int64(1), mock.Anything, 50).Return(false, errors.New("db error"))
+	mockRepo.On("GrantAllowance", mock.Anything, int64(2), mock.Anything, 50).Return(true, nil)
+
+	assert.EqualError(t, service.GrantAllowance(context.Background()), "user 1: db error")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIssuanceService_GrantAllowance_Disabled(t *testing.T) {
+	mockRepo := new(MockIssuanceRepository)
+	service, err := services.NewIssuanceService(mockRepo, services.AllowancePolicy{}, &fakeClock{now: time.Now()})
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.GrantAllowance(context.Background()))
+	mockRepo.AssertNotCalled(t, "GetUsersWithoutAllowance", mock.Anything, mock.Anything, mock.Anything)
+}
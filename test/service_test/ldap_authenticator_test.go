@@ -0,0 +1,103 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	ldapServiceDN = "cn=shop,ou=services,dc=example,dc=com"
+	ldapAliceDN   = "uid=alice,ou=people,dc=example,dc=com"
+)
+
+func newLDAPAuthenticator(t *testing.T) (*services.LDAPAuthenticator, *fakeLDAPServer) {
+	server := newFakeLDAPServer(t)
+	server.AddEntry(ldapServiceDN, "service-secret", map[string]string{"cn": "shop"})
+	server.AddEntry(ldapAliceDN, "alice-secret", map[string]string{"uid": "alice", "mail": "alice@example.com"})
+
+	authenticator, err := services.NewLDAPAuthenticator(services.LDAPConfig{
+		URL:          server.URL(),
+		BindDN:       ldapServiceDN,
+		BindPassword: "service-secret",
+		BaseDN:       "dc=example,dc=com",
+	})
+	assert.NoError(t, err)
+	return authenticator, server
+}
+
+func TestLDAPAuthenticator_Success(t *testing.T) {
+	authenticator, server := newLDAPAuthenticator(t)
+
+	identity, err := authenticator.Authenticate(context.Background(), models.Credentials{Username: "alice", Password: "alice-secret"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Identity{Provider: "ldap", Subject: ldapAliceDN, Username: "alice"}, identity)
+	assert.Equal(t, []string{ldapServiceDN, ldapAliceDN}, server.Binds())
+}
+
+func TestLDAPAuthenticator_WrongPassword(t *testing.T) {
+	authenticator, _ := newLDAPAuthenticator(t)
+
+	_, err := authenticator.Authenticate(context.Background(), models.Credentials{Username: "alice", Password: "wrong"})
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestLDAPAuthenticator_UnknownUser(t *testing.T) {
+	authenticator, _ := newLDAPAuthenticator(t)
+
+	_, err := authenticator.Authenticate(context.Background(), models.Credentials{Username: "mallory", Password: "secret"})
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestLDAPAuthenticator_FilterInjection(t *testing.T) {
+	authenticator, server := newLDAPAuthenticator(t)
+
+	_, err := authenticator.Authenticate(context.Background(), models.Credentials{Username: "*", Password: "alice-secret"})
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.Equal(t, []string{ldapServiceDN}, server.Binds())
+}
+
+func TestLDAPAuthenticator_EmptyPasswordIsRejected(t *testing.T) {
+	authenticator, server := newLDAPAuthenticator(t)
+
+	_, err := authenticator.Authenticate(context.Background(), models.Credentials{Username: "alice"})
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	assert.Empty(t, server.Binds(), "the directory must not be contacted")
+}
+
+func TestLDAPAuthenticator_WrongServiceAccount(t *testing.T) {
+	server := newFakeLDAPServer(t)
+	authenticator, err := services.NewLDAPAuthenticator(services.LDAPConfig{
+		URL:          server.URL(),
+		BindDN:       ldapServiceDN,
+		BindPassword: "stale",
+		BaseDN:       "dc=example,dc=com",
+	})
+	assert.NoError(t, err)
+
+	_, err = authenticator.Authenticate(context.Background(), models.Credentials{Username: "alice", Password: "alice-secret"})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, models.ErrInvalidCredentials, "a misconfigured directory is not a wrong password")
+}
+
+func TestLDAPAuthenticator_Unavailable(t *testing.T) {
+	authenticator, err := services.NewLDAPAuthenticator(services.LDAPConfig{URL: "ldap://127.0.0.1:1", BaseDN: "dc=example,dc=com"})
+	assert.NoError(t, err)
+
+	_, err = authenticator.Authenticate(context.Background(), models.Credentials{Username: "alice", Password: "alice-secret"})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, models.ErrInvalidCredentials)
+}
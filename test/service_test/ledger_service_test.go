@@ -0,0 +1,49 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestVerifyLedger_Balanced(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	service := services.NewLedgerService(mockRepo)
+
+	mockRepo.On("VerifyLedger", mock.Anything).Return(&models.LedgerReport{UnbalancedEntries: []int64{}, Mismatches: []models.BalanceMismatch{}}, nil)
+
+	report, err := service.VerifyLedger(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, report.Balanced)
+}
+
+func TestVerifyLedger_Mismatch(t *testing.T) {
+	mockRepo := new(MockLedgerRepository)
+	service := services.NewLedgerService(mockRepo)
+
+	mockRepo.On("VerifyLedger", mock.Anything).Return(&models.LedgerReport{
+		UnbalancedEntries: []int64{},
+		Mismatches:        []models.BalanceMismatch{{Username: "testuser", Balance: 1000, LedgerBalance: 900}},
+	}, nil)
+
+	report, err := service.VerifyLedger(context.Background())
+
+	assert.NoError(t, err)
+	assert.False(t, report.Balanced)
+}
+
+func TestLedgerEntry_Balanced(t *testing.T) {
+	entry := models.LedgerEntry{Postings: []models.Posting{{UserID: 1, Amount: -100}, {UserID: 2, Amount: 100}}}
+	assert.True(t, entry.Balanced())
+
+	entry.Postings = append(entry.Postings, models.Posting{Account: models.AccountShop, Amount: 1})
+	assert.False(t, entry.Balanced())
+}
@@ -0,0 +1,141 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var guardPolicy = services.LoginGuardPolicy{
+	MaxFailures:     5,
+	LockoutDuration: 15 * time.Minute,
+	IPMaxFailures:   20,
+	BackoffBase:     time.Second,
+	BackoffMax:      10 * time.Second,
+	FailureWindow:   time.Hour,
+}
+
+func TestLoginGuard_Check_NotBlocked(t *testing.T) {
+	mockRepo := new(MockLoginFailureRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	guard := services.NewLoginGuard(mockRepo, guardPolicy, clock)
+
+	expired := clock.now.Add(-time.Second)
+	mockRepo.On("GetLoginFailure", mock.Anything, models.LoginKindUsername, "alice").Return(&models.LoginFailure{Failures: 2, BlockedUntil: &expired}, nil)
+	mockRepo.On("GetLoginFailure", mock.Anything, models.LoginKindIP, "10.0.0.1").Return((*models.LoginFailure)(nil), nil)
+
+	err := guard.Check(context.Background(), "alice", models.ClientInfo{IP: "10.0.0.1"})
+
+	assert.NoError(t, err)
+}
+
+func TestLoginGuard_Check_Blocked(t *testing.T) {
+	mockRepo := new(MockLoginFailureRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	guard := services.NewLoginGuard(mockRepo, guardPolicy, clock)
+
+	userBlock := clock.now.Add(4 * time.Second)
+	ipBlock := clock.now.Add(10 * time.Minute)
+	mockRepo.On("GetLoginFailure", mock.Anything, models.LoginKindUsername, "alice").Return(&models.LoginFailure{Failures: 3, BlockedUntil: &userBlock}, nil)
+	mockRepo.On("GetLoginFailure", mock.Anything, models.LoginKindIP, "10.0.0.1").Return(&models.LoginFailure{Failures: 20, BlockedUntil: &ipBlock}, nil)
+
+	err := guard.Check(context.Background(), "alice", models.ClientInfo{IP: "10.0.0.1"})
+
+	var blockedErr *models.LoginBlockedError
+	assert.ErrorAs(t, err, &blockedErr)
+	assert.Equal(t, 10*time.Minute, blockedErr.RetryAfter)
+}
+
+func TestLoginGuard_Check_RepositoryError(t *testing.T) {
+	mockRepo := new(MockLoginFailureRepository)
+	guard := services.NewLoginGuard(mockRepo, guardPolicy, nil)
+
+	mockRepo.On("GetLoginFailure", mock.Anything, models.LoginKindUsername, "alice").Return((*models.LoginFailure)(nil), errors.New("db error"))
+
+	err := guard.Check(context.Background(), "alice", models.ClientInfo{})
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, models.ErrLoginBlocked)
+}
+
+func TestLoginGuard_RecordFailure_ExponentialBackoff(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+
+	cases := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 15 * time.Minute},
+	}
+	for _, c := range cases {
+		mockRepo := new(MockLoginFailureRepository)
+		guard := services.NewLoginGuard(mockRepo, guardPolicy, clock)
+
+		mockRepo.On("RecordLoginFailure", mock.Anything, models.LoginKindUsername, "alice", clock.now, time.Hour).Return(c.failures, nil)
+		mockRepo.On("BlockLogin", mock.Anything, models.LoginKindUsername, "alice", clock.now.Add(c.delay)).Return(nil)
+
+		guard.RecordFailure(context.Background(), "alice", models.ClientInfo{})
+
+		mockRepo.AssertExpectations(t)
+	}
+}
+
+func TestLoginGuard_RecordFailure_BackoffIsCapped(t *testing.T) {
+	mockRepo := new(MockLoginFailureRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	policy := guardPolicy
+	policy.MaxFailures = 100
+	guard := services.NewLoginGuard(mockRepo, policy, clock)
+
+	mockRepo.On("RecordLoginFailure", mock.Anything, models.LoginKindUsername, "alice", clock.now, time.Hour).Return(40, nil)
+	mockRepo.On("BlockLogin", mock.Anything, models.LoginKindUsername, "alice", clock.now.Add(10*time.Second)).Return(nil)
+
+	guard.RecordFailure(context.Background(), "alice", models.ClientInfo{})
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLoginGuard_RecordFailure_IPIsLockedOnlyAfterThreshold(t *testing.T) {
+	mockRepo := new(MockLoginFailureRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	guard := services.NewLoginGuard(mockRepo, guardPolicy, clock)
+
+	mockRepo.On("RecordLoginFailure", mock.Anything, models.LoginKindUsername, "alice", clock.now, time.Hour).Return(1, nil)
+	mockRepo.On("BlockLogin", mock.Anything, models.LoginKindUsername, "alice", clock.now.Add(time.Second)).Return(nil)
+	mockRepo.On("RecordLoginFailure", mock.Anything, models.LoginKindIP, "10.0.0.1", clock.now, time.Hour).Return(19, nil).Once()
+
+	guard.RecordFailure(context.Background(), "alice", models.ClientInfo{IP: "10.0.0.1"})
+	mockRepo.AssertNotCalled(t, "BlockLogin", mock.Anything, models.LoginKindIP, "10.0.0.1", mock.Anything)
+
+	mockRepo.On("RecordLoginFailure", mock.Anything, models.LoginKindIP, "10.0.0.1", clock.now, time.Hour).Return(20, nil).Once()
+	mockRepo.On("BlockLogin", mock.Anything, models.LoginKindIP, "10.0.0.1", clock.now.Add(15*time.Minute)).Return(nil)
+
+	guard.RecordFailure(context.Background(), "alice", models.ClientInfo{IP: "10.0.0.1"})
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLoginGuard_RecordSuccessAndUnlock(t *testing.T) {
+	mockRepo := new(MockLoginFailureRepository)
+	guard := services.NewLoginGuard(mockRepo, guardPolicy, nil)
+
+	mockRepo.On("ResetLoginFailures", mock.Anything, models.LoginKindUsername, "alice").Return(nil)
+
+	guard.RecordSuccess(context.Background(), "alice")
+	err := guard.Unlock(context.Background(), "alice")
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "ResetLoginFailures", 2)
+}
@@ -2,14 +2,40 @@ package services
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
 )
 
+func newTokenManager(t *testing.T) *auth.JWTManager {
+	return newTokenManagerWithClock(t, nil)
+}
+
+func newTokenManagerWithClock(t *testing.T, clock auth.Clock) *auth.JWTManager {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	keys, err := auth.NewKeySet("test-key", privateKey)
+	assert.NoError(t, err)
+	return auth.NewJWTManager(&auth.Config{Keys: keys, AccessTokenTTL: 15 * time.Minute}, clock)
+}
+
 type MockInventoryRepository struct {
 	mock.Mock
 }
 
+func (m *MockInventoryRepository) GetPurchasesByUserID(ctx context.Context, userID int64) ([]models.Purchase, error) {
+	args := m.Called(ctx, userID)
+	if purchases, ok := args.Get(0).([]models.Purchase); ok {
+		return purchases, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockInventoryRepository) GetInventoryByUserID(ctx context.Context, userID int64) ([]models.Inventory, error) {
 	args := m.Called(ctx, userID)
 	if inv, ok := args.Get(0).([]models.Inventory); ok {
@@ -86,8 +112,42 @@ func (m *MockTransactionRepository) GetTransactionsByUserID(ctx context.Context,
 	return nil, args.Error(1)
 }
 
-func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, transaction *models.CoinTransaction) error {
-	args := m.Called(ctx, transaction)
+func (m *MockTransactionRepository) CreateTransaction(ctx context.Context, transaction *models.CoinTransaction, limits *models.TransferLimitCheck) error {
+	args := m.Called(ctx, transaction, limits)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) CreateTransactions(ctx context.Context, transactions []models.CoinTransaction, limits *models.TransferLimitCheck) error {
+	args := m.Called(ctx, transactions, limits)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) GetHistory(ctx context.Context, filter models.TransactionFilter) ([]models.HistoryEntry, error) {
+	args := m.Called(ctx, filter)
+	if history, ok := args.Get(0).([]models.HistoryEntry); ok {
+		return history, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetTransfers(ctx context.Context, filter models.TransferFilter) ([]models.Transfer, error) {
+	args := m.Called(ctx, filter)
+	if transfers, ok := args.Get(0).([]models.Transfer); ok {
+		return transfers, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionRepository) GetTransfer(ctx context.Context, id int64) (*models.Transfer, error) {
+	args := m.Called(ctx, id)
+	if transfer, ok := args.Get(0).(*models.Transfer); ok {
+		return transfer, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransactionRepository) ReverseTransfer(ctx context.Context, reversal *models.TransferReversal, requireUnspent bool) error {
+	args := m.Called(ctx, reversal, requireUnspent)
 	return args.Error(0)
 }
 
@@ -103,6 +163,14 @@ func (m *MockUserRepository) GetUserByUsername(ctx context.Context, username str
 	return nil, args.Error(1)
 }
 
+func (m *MockUserRepository) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if user, ok := args.Get(0).(*models.User); ok {
+		return user, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockUserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -112,3 +180,403 @@ func (m *MockUserRepository) UpdateUserCoins(ctx context.Context, userID int64,
 	args := m.Called(ctx, userID, coins)
 	return args.Error(0)
 }
+
+func (m *MockUserRepository) UpdateUserPassword(ctx context.Context, userID int64, password string) error {
+	args := m.Called(ctx, userID, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ChangeUserPassword(ctx context.Context, userID int64, password string, changedAt time.Time) error {
+	args := m.Called(ctx, userID, password, changedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserProfile(ctx context.Context, userID int64, profile models.Profile) error {
+	args := m.Called(ctx, userID, profile)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserStatus(ctx context.Context, userID int64, status string) error {
+	args := m.Called(ctx, userID, status)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserDepartment(ctx context.Context, userID int64, department string) error {
+	args := m.Called(ctx, userID, department)
+	return args.Error(0)
+}
+
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) CreateSession(ctx context.Context, session *models.Session, token *models.RefreshToken) error {
+	args := m.Called(ctx, session, token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if token, ok := args.Get(0).(*models.RefreshToken); ok {
+		return token, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTokenRepository) RotateRefreshToken(ctx context.Context, oldTokenID int64, newToken *models.RefreshToken, session *models.Session) error {
+	args := m.Called(ctx, oldTokenID, newToken, session)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) GetActiveSessions(ctx context.Context, userID int64, now time.Time) ([]models.Session, error) {
+	args := m.Called(ctx, userID, now)
+	if sessions, ok := args.Get(0).([]models.Session); ok {
+		return sessions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeSession(ctx context.Context, userID int64, sessionID int64) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeUserSessions(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	args := m.Called(ctx, jti, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string, sessionID int64) (bool, error) {
+	args := m.Called(ctx, jti, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockLoginFailureRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginFailureRepository) GetLoginFailure(ctx context.Context, kind, subject string) (*models.LoginFailure, error) {
+	args := m.Called(ctx, kind, subject)
+	if failure, ok := args.Get(0).(*models.LoginFailure); ok {
+		return failure, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockLoginFailureRepository) RecordLoginFailure(ctx context.Context, kind, subject string, now time.Time, window time.Duration) (int, error) {
+	args := m.Called(ctx, kind, subject, now, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLoginFailureRepository) BlockLogin(ctx context.Context, kind, subject string, until time.Time) error {
+	args := m.Called(ctx, kind, subject, until)
+	return args.Error(0)
+}
+
+func (m *MockLoginFailureRepository) ResetLoginFailures(ctx context.Context, kind, subject string) error {
+	args := m.Called(ctx, kind, subject)
+	return args.Error(0)
+}
+
+type MockLoginGuard struct {
+	mock.Mock
+}
+
+func (m *MockLoginGuard) Check(ctx context.Context, username string, client models.ClientInfo) error {
+	args := m.Called(ctx, username, client)
+	return args.Error(0)
+}
+
+func (m *MockLoginGuard) RecordFailure(ctx context.Context, username string, client models.ClientInfo) {
+	m.Called(ctx, username, client)
+}
+
+func (m *MockLoginGuard) RecordSuccess(ctx context.Context, username string) {
+	m.Called(ctx, username)
+}
+
+func (m *MockLoginGuard) Unlock(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if key, ok := args.Get(0).(*models.APIKey); ok {
+		return key, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeysByUserID(ctx context.Context, userID int64) ([]models.APIKey, error) {
+	args := m.Called(ctx, userID)
+	if keys, ok := args.Get(0).([]models.APIKey); ok {
+		return keys, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, userID int64, keyID int64) error {
+	args := m.Called(ctx, userID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, keyID int64, usedAt time.Time) error {
+	args := m.Called(ctx, keyID, usedAt)
+	return args.Error(0)
+}
+
+type MockAuthenticator struct {
+	mock.Mock
+	name string
+}
+
+func (m *MockAuthenticator) Name() string {
+	return m.name
+}
+
+func (m *MockAuthenticator) Authenticate(ctx context.Context, credentials models.Credentials) (*models.Identity, error) {
+	args := m.Called(ctx, credentials)
+	if identity, ok := args.Get(0).(*models.Identity); ok {
+		return identity, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string, now time.Time) (*models.PasswordResetToken, error) {
+	args := m.Called(ctx, tokenHash, now)
+	if token, ok := args.Get(0).(*models.PasswordResetToken); ok {
+		return token, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockLoginEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginEventRepository) CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockLoginEventRepository) GetLoginEvents(ctx context.Context, filter models.LoginEventFilter) ([]models.LoginEvent, error) {
+	args := m.Called(ctx, filter)
+	if events, ok := args.Get(0).([]models.LoginEvent); ok {
+		return events, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockLoginRecorder struct {
+	mock.Mock
+}
+
+func (m *MockLoginRecorder) RecordLogin(ctx context.Context, event models.LoginEvent) {
+	m.Called(ctx, event)
+}
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) VerifyLedger(ctx context.Context) (*models.LedgerReport, error) {
+	args := m.Called(ctx)
+	if report, ok := args.Get(0).(*models.LedgerReport); ok {
+		return report, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) CreateIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	args := m.Called(ctx, record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) GetIdempotencyRecord(ctx context.Context, username, key string, now time.Time) (*models.IdempotencyRecord, error) {
+	args := m.Called(ctx, username, key, now)
+	if record, ok := args.Get(0).(*models.IdempotencyRecord); ok {
+		return record, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) CompleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockScheduledTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockScheduledTransferRepository) CreateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) GetScheduledTransfers(ctx context.Context, owner string) ([]models.ScheduledTransfer, error) {
+	args := m.Called(ctx, owner)
+	if transfers, ok := args.Get(0).([]models.ScheduledTransfer); ok {
+		return transfers, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) GetScheduledTransfer(ctx context.Context, owner string, id int64) (*models.ScheduledTransfer, error) {
+	args := m.Called(ctx, owner, id)
+	if transfer, ok := args.Get(0).(*models.ScheduledTransfer); ok {
+		return transfer, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) UpdateScheduledTransfer(ctx context.Context, transfer *models.ScheduledTransfer, reschedule bool) error {
+	args := m.Called(ctx, transfer, reschedule)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) DeleteScheduledTransfer(ctx context.Context, owner string, id int64) (bool, error) {
+	args := m.Called(ctx, owner, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]models.ScheduledTransfer, error) {
+	args := m.Called(ctx, now, limit)
+	if transfers, ok := args.Get(0).([]models.ScheduledTransfer); ok {
+		return transfers, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) ClaimScheduledTransferRun(ctx context.Context, run *models.ScheduledTransferRun, next time.Time) (bool, error) {
+	args := m.Called(ctx, run, next)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) FinishScheduledTransferRun(ctx context.Context, run *models.ScheduledTransferRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockScheduledTransferRepository) GetScheduledTransferRuns(ctx context.Context, transferID int64, limit int) ([]models.ScheduledTransferRun, error) {
+	args := m.Called(ctx, transferID, limit)
+	if runs, ok := args.Get(0).([]models.ScheduledTransferRun); ok {
+		return runs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type MockTransferLimitRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferLimitRepository) GetTransferLimitException(ctx context.Context, userID int64) (*models.TransferLimitException, error) {
+	args := m.Called(ctx, userID)
+	if exception, ok := args.Get(0).(*models.TransferLimitException); ok {
+		return exception, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTransferLimitRepository) SaveTransferLimitException(ctx context.Context, exception *models.TransferLimitException) error {
+	args := m.Called(ctx, exception)
+	return args.Error(0)
+}
+
+func (m *MockTransferLimitRepository) DeleteTransferLimitException(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockIssuanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockIssuanceRepository) IssueCoins(ctx context.Context, issuance *models.CoinIssuance) error {
+	args := m.Called(ctx, issuance)
+	return args.Error(0)
+}
+
+func (m *MockIssuanceRepository) GetUsersWithoutAllowance(ctx context.Context, period string, limit int) ([]int64, error) {
+	args := m.Called(ctx, period, limit)
+	if userIDs, ok := args.Get(0).([]int64); ok {
+		return userIDs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIssuanceRepository) GrantAllowance(ctx context.Context, userID int64, period string, amount int) (bool, error) {
+	args := m.Called(ctx, userID, period, amount)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockCoinLotRepository struct {
+	mock.Mock
+}
+
+func (m *MockCoinLotRepository) GetOpenLots(ctx context.Context, userID int64, limit int) ([]models.CoinLot, error) {
+	args := m.Called(ctx, userID, limit)
+	if lots, ok := args.Get(0).([]models.CoinLot); ok {
+		return lots, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCoinLotRepository) GetUsersWithLotsGrantedBefore(ctx context.Context, cutoff time.Time, limit int) ([]int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	if userIDs, ok := args.Get(0).([]int64); ok {
+		return userIDs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockCoinLotRepository) ExpireLots(ctx context.Context, userID int64, cutoff time.Time, description string) (int, error) {
+	args := m.Called(ctx, userID, cutoff, description)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,135 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOIDCAuthenticator(t *testing.T) (*services.OIDCAuthenticator, *fakeOIDCServer) {
+	server := newFakeOIDCServer(t)
+	authenticator, err := services.NewOIDCAuthenticator(services.OIDCConfig{
+		Issuer:       server.Issuer(),
+		ClientID:     oidcClientID,
+		ClientSecret: oidcClientSecret,
+		RedirectURL:  oidcRedirectURL,
+	}, nil, nil)
+	assert.NoError(t, err)
+	return authenticator, server
+}
+
+func TestOIDCAuthenticator_AuthCodeURL(t *testing.T) {
+	authenticator, server := newOIDCAuthenticator(t)
+
+	authURL, err := authenticator.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+
+	assert.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, server.Issuer()+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	query := parsed.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, oidcClientID, query.Get("client_id"))
+	assert.Equal(t, oidcRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, "challenge-1", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Contains(t, query.Get("scope"), "openid")
+}
+
+func TestOIDCAuthenticator_Success(t *testing.T) {
+	authenticator, server := newOIDCAuthenticator(t)
+	code := server.IssueCode("00u1", "alice", "nonce-1", "verifier-1")
+
+	identity, err := authenticator.Authenticate(context.Background(), models.Credentials{Code: code, Nonce: "nonce-1", CodeVerifier: "verifier-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Identity{Provider: "oidc", Subject: "00u1", Username: "alice"}, identity)
+}
+
+func TestOIDCAuthenticator_CodeIsSingleUse(t *testing.T) {
+	authenticator, server := newOIDCAuthenticator(t)
+	code := server.IssueCode("00u1", "alice", "nonce-1", "verifier-1")
+	credentials := models.Credentials{Code: code, Nonce: "nonce-1", CodeVerifier: "verifier-1"}
+
+	_, err := authenticator.Authenticate(context.Background(), credentials)
+	assert.NoError(t, err)
+
+	_, err = authenticator.Authenticate(context.Background(), credentials)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestOIDCAuthenticator_WrongCodeVerifier(t *testing.T) {
+	authenticator, server := newOIDCAuthenticator(t)
+	code := server.IssueCode("00u1", "alice", "nonce-1", "verifier-1")
+
+	_, err := authenticator.Authenticate(context.Background(), models.Credentials{Code: code, Nonce: "nonce-1", CodeVerifier: "stolen"})
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestOIDCAuthenticator_EmptyCode(t *testing.T) {
+	authenticator, _ := newOIDCAuthenticator(t)
+
+	_, err := authenticator.Authenticate(context.Background(), models.Credentials{Nonce: "nonce-1"})
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestOIDCAuthenticator_NonceMismatch(t *testing.T) {
+	authenticator, server := newOIDCAuthenticator(t)
+	code := server.IssueCode("00u1", "alice", "nonce-1", "verifier-1")
+
+	_, err := authenticator.Authenticate(context.Background(), models.Credentials{Code: code, Nonce: "nonce-2", CodeVerifier: "verifier-1"})
+
+	assert.ErrorContains(t, err, "nonce")
+}
+
+func TestOIDCAuthenticator_RejectsInvalidIDTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   string
+	}{
+		{name: "audience", claims: jwt.MapClaims{"aud": "another-client"}, want: "audience"},
+		{name: "issuer", claims: jwt.MapClaims{"iss": "https://evil.example.com"}, want: "issuer"},
+		{name: "expired", claims: jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}, want: "expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator, server := newOIDCAuthenticator(t)
+			server.Claims = tt.claims
+			code := server.IssueCode("00u1", "alice", "nonce-1", "verifier-1")
+
+			_, err := authenticator.Authenticate(context.Background(), models.Credentials{Code: code, Nonce: "nonce-1", CodeVerifier: "verifier-1"})
+
+			assert.ErrorContains(t, err, tt.want)
+			assert.NotErrorIs(t, err, models.ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestOIDCAuthenticator_IssuerMismatch(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	authenticator, err := services.NewOIDCAuthenticator(services.OIDCConfig{
+		Issuer:       server.Issuer() + "/",
+		ClientID:     oidcClientID,
+		ClientSecret: oidcClientSecret,
+		RedirectURL:  oidcRedirectURL,
+	}, nil, nil)
+	assert.NoError(t, err)
+
+	_, err = authenticator.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+
+	assert.ErrorContains(t, err, "issuer")
+}
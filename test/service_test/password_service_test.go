@@ -0,0 +1,162 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type passwordServiceFixture struct {
+	userRepo  *MockUserRepository
+	resetRepo *MockPasswordResetRepository
+	tokenRepo *MockTokenRepository
+	guard     *MockLoginGuard
+	clock     *fakeClock
+	service   *services.PasswordService
+}
+
+func newPasswordServiceFixture(t *testing.T) *passwordServiceFixture {
+	f := &passwordServiceFixture{
+		userRepo:  new(MockUserRepository),
+		resetRepo: new(MockPasswordResetRepository),
+		tokenRepo: new(MockTokenRepository),
+		guard:     permissiveLoginGuard(),
+		clock:     &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 700_000_000, time.UTC)},
+	}
+	tokenService := services.NewTokenService(f.tokenRepo, f.userRepo, newTokenManager(t), time.Hour)
+	f.service = services.NewPasswordService(f.userRepo, f.resetRepo, tokenService, f.guard, time.Hour, f.clock)
+	return f
+}
+
+func newUserWithPassword(t *testing.T, password string) *models.User {
+	hashedPassword, err := services.HashPassword(password)
+	assert.NoError(t, err)
+	return &models.User{ID: 1, Username: "testuser", Password: hashedPassword}
+}
+
+func passwordMatches(password string) interface{} {
+	return mock.MatchedBy(func(hash string) bool {
+		ok, _ := services.VerifyPassword(password, hash)
+		return ok
+	})
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+
+	f.userRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(newUserWithPassword(t, "old-password"), nil)
+	f.userRepo.On("ChangeUserPassword", mock.Anything, int64(1), passwordMatches("new-password"), time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)).Return(nil)
+	f.tokenRepo.On("RevokeUserSessions", mock.Anything, int64(1)).Return(nil)
+
+	err := f.service.ChangePassword(context.Background(), "testuser", "old-password", "new-password", testClient)
+
+	assert.NoError(t, err)
+	f.userRepo.AssertExpectations(t)
+	f.tokenRepo.AssertExpectations(t)
+	f.guard.AssertCalled(t, "RecordSuccess", mock.Anything, "testuser")
+}
+
+func TestChangePassword_WrongOldPassword(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+
+	f.userRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(newUserWithPassword(t, "old-password"), nil)
+
+	err := f.service.ChangePassword(context.Background(), "testuser", "guess", "new-password", testClient)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	f.guard.AssertCalled(t, "RecordFailure", mock.Anything, "testuser", testClient)
+	f.userRepo.AssertNotCalled(t, "ChangeUserPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	f.tokenRepo.AssertNotCalled(t, "RevokeUserSessions", mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_Blocked(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+	f.guard = new(MockLoginGuard)
+	f.service = services.NewPasswordService(f.userRepo, f.resetRepo, nil, f.guard, time.Hour, f.clock)
+
+	f.guard.On("Check", mock.Anything, "testuser", testClient).Return(&models.LoginBlockedError{RetryAfter: time.Minute})
+
+	err := f.service.ChangePassword(context.Background(), "testuser", "old-password", "new-password", testClient)
+
+	assert.ErrorIs(t, err, models.ErrLoginBlocked)
+	f.userRepo.AssertNotCalled(t, "GetUserByUsername", mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_ExternalAccount(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+
+	f.userRepo.On("GetUserByUsername", mock.Anything, "alice").Return(&models.User{ID: 2, Username: "alice", Password: "!"}, nil)
+
+	err := f.service.ChangePassword(context.Background(), "alice", "!", "new-password", testClient)
+
+	assert.ErrorIs(t, err, models.ErrNoLocalPassword)
+}
+
+func TestCreateResetToken(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+
+	var stored *models.PasswordResetToken
+	f.userRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(newUserWithPassword(t, "old-password"), nil)
+	f.resetRepo.On("CreatePasswordResetToken", mock.Anything, mock.AnythingOfType("*models.PasswordResetToken")).Return(nil).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.PasswordResetToken) })
+
+	created, err := f.service.CreateResetToken(context.Background(), "testuser", "admin")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.Token)
+	assert.Equal(t, f.clock.now.Add(time.Hour), created.ExpiresAt)
+	hash := sha256.Sum256([]byte(created.Token))
+	assert.Equal(t, hex.EncodeToString(hash[:]), stored.TokenHash, "only the hash is stored")
+	assert.Equal(t, int64(1), stored.UserID)
+	assert.Equal(t, "admin", stored.CreatedBy)
+}
+
+func TestCreateResetToken_UnknownUser(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+
+	f.userRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+
+	_, err := f.service.CreateResetToken(context.Background(), "ghost", "admin")
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+	f.guard.On("Unlock", mock.Anything, "testuser").Return(nil)
+
+	hash := sha256.Sum256([]byte("reset-token"))
+	f.resetRepo.On("ConsumePasswordResetToken", mock.Anything, hex.EncodeToString(hash[:]), f.clock.now).
+		Return(&models.PasswordResetToken{ID: 5, UserID: 1}, nil)
+	f.userRepo.On("GetUserByID", mock.Anything, int64(1)).Return(newUserWithPassword(t, "forgotten"), nil)
+	f.userRepo.On("ChangeUserPassword", mock.Anything, int64(1), passwordMatches("new-password"), mock.Anything).Return(nil)
+	f.tokenRepo.On("RevokeUserSessions", mock.Anything, int64(1)).Return(nil)
+
+	err := f.service.ResetPassword(context.Background(), "reset-token", "new-password")
+
+	assert.NoError(t, err)
+	f.userRepo.AssertExpectations(t)
+	f.tokenRepo.AssertExpectations(t)
+	f.guard.AssertCalled(t, "Unlock", mock.Anything, "testuser")
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	f := newPasswordServiceFixture(t)
+
+	f.resetRepo.On("ConsumePasswordResetToken", mock.Anything, mock.Anything, mock.Anything).Return((*models.PasswordResetToken)(nil), models.ErrInvalidResetToken)
+
+	err := f.service.ResetPassword(context.Background(), "used-token", "new-password")
+
+	assert.ErrorIs(t, err, models.ErrInvalidResetToken)
+	f.userRepo.AssertNotCalled(t, "ChangeUserPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,111 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestGetProfile_UnknownUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := services.NewProfileService(mockRepo)
+
+	mockRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+
+	_, err := service.GetProfile(context.Background(), "ghost")
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+}
+
+func TestUpdateProfile_AppliesPresentFields(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := services.NewProfileService(mockRepo)
+
+	user := &models.User{ID: 1, Username: "testuser", Profile: models.Profile{DisplayName: "Test", Department: "Platform", Status: models.UserStatusActive}}
+	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+	mockRepo.On("GetUserByUsername", mock.Anything, "boss").Return(&models.User{ID: 2, Username: "boss"}, nil)
+	expected := models.Profile{
+		DisplayName: "Test",
+		Email:       "test@example.com",
+		Department:  "Platform",
+		Manager:     "boss",
+		HireDate:    "2024-03-01",
+		Status:      models.UserStatusActive,
+	}
+	mockRepo.On("UpdateUserProfile", mock.Anything, int64(1), expected).Return(nil)
+
+	profile, err := service.UpdateProfile(context.Background(), "testuser", models.ProfileUpdate{
+		Email:    stringPtr(" test@example.com "),
+		Manager:  stringPtr("boss"),
+		HireDate: stringPtr("2024-03-01"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, *profile)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateProfile_RejectsInvalidValues(t *testing.T) {
+	for name, update := range map[string]models.ProfileUpdate{
+		"email":       {Email: stringPtr("Test <test@example.com>")},
+		"hire date":   {HireDate: stringPtr("01.03.2024")},
+		"own manager": {Manager: stringPtr("testuser")},
+		"no manager":  {Manager: stringPtr("ghost")},
+		"long name":   {DisplayName: stringPtr(strings.Repeat("a", 256))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			service := services.NewProfileService(mockRepo)
+
+			mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+			mockRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+
+			_, err := service.UpdateProfile(context.Background(), "testuser", update)
+
+			assert.ErrorIs(t, err, models.ErrInvalidProfile)
+			mockRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := services.NewProfileService(mockRepo)
+
+	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+	mockRepo.On("UpdateUserStatus", mock.Anything, int64(1), models.UserStatusSuspended).Return(nil)
+
+	assert.NoError(t, service.SetStatus(context.Background(), "testuser", models.UserStatusSuspended))
+	assert.ErrorIs(t, service.SetStatus(context.Background(), "testuser", "retired"), models.ErrInvalidStatus)
+	assert.ErrorIs(t, service.SetStatus(context.Background(), "ghost", models.UserStatusActive), models.ErrUserNotFound)
+	mockRepo.AssertNumberOfCalls(t, "UpdateUserStatus", 1)
+}
+
+func TestSetDepartment(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := services.NewProfileService(mockRepo)
+
+	user := &models.User{ID: 1, Username: "testuser", Profile: models.Profile{DisplayName: "Test", Department: "Platform", Status: models.UserStatusActive}}
+	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+	mockRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+	mockRepo.On("UpdateUserDepartment", mock.Anything, int64(1), "Payments").Return(nil)
+
+	assert.NoError(t, service.SetDepartment(context.Background(), "testuser", " Payments "))
+	assert.ErrorIs(t, service.SetDepartment(context.Background(), "testuser", strings.Repeat("a", 256)), models.ErrInvalidProfile)
+	assert.ErrorIs(t, service.SetDepartment(context.Background(), "ghost", "Payments"), models.ErrUserNotFound)
+	mockRepo.AssertNumberOfCalls(t, "UpdateUserDepartment", 1)
+	mockRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything, mock.Anything, mock.Anything)
+}
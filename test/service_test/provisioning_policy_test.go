@@ -0,0 +1,47 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProvisioningPolicy_Always(t *testing.T) {
+	policy, err := services.NewProvisioningPolicy("always", nil, "")
+	assert.NoError(t, err)
+	assert.True(t, policy.Allows("anyone"))
+}
+
+func TestProvisioningPolicy_DefaultsToAlways(t *testing.T) {
+	policy, err := services.NewProvisioningPolicy("", nil, "")
+	assert.NoError(t, err)
+	assert.True(t, policy.Allows("anyone"))
+}
+
+func TestProvisioningPolicy_Never(t *testing.T) {
+	policy, err := services.NewProvisioningPolicy("never", []string{"alice"}, ".*")
+	assert.NoError(t, err)
+	assert.False(t, policy.Allows("alice"))
+}
+
+func TestProvisioningPolicy_AllowList(t *testing.T) {
+	policy, err := services.NewProvisioningPolicy("allowlist", []string{"alice", " bob "}, `[a-z]+\.[a-z]+`)
+	assert.NoError(t, err)
+
+	assert.True(t, policy.Allows("alice"))
+	assert.True(t, policy.Allows("bob"))
+	assert.True(t, policy.Allows("john.smith"))
+	assert.False(t, policy.Allows("mallory"))
+	assert.False(t, policy.Allows("john.smith.evil"))
+}
+
+func TestProvisioningPolicy_InvalidConfig(t *testing.T) {
+	_, err := services.NewProvisioningPolicy("sometimes", nil, "")
+	assert.Error(t, err)
+
+	_, err = services.NewProvisioningPolicy("allowlist", nil, "[")
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newScheduledTransferService(repo *MockScheduledTransferRepository, transactionRepo *MockTransactionRepository, clock *fakeClock) *services.ScheduledTransferService {
+	return services.NewScheduledTransferService(repo, services.NewTransactionService(transactionRepo, nil, nil), clock)
+}
+
+func TestScheduledTransferService_Create(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	// Thursday; schedules run in UTC whatever the zone of the server.
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC).In(time.FixedZone("HST", -10*60*60))}
+	service := newScheduledTransferService(mockRepo, new(MockTransactionRepository), clock)
+
+	transfer := &models.ScheduledTransfer{Owner: "lead", ToUser: "report", Amount: 10, Category: "teamwork", Schedule: "0 9 * * 1"}
+	mockRepo.On("CreateScheduledTransfer", mock.Anything, transfer).Return(nil)
+
+	err := service.CreateScheduledTransfer(context.Background(), transfer)
+	assert.NoError(t, err)
+	assert.True(t, transfer.Active)
+	assert.Equal(t, time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC), transfer.NextRunAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransferService_Create_Invalid(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	service := newScheduledTransferService(mockRepo, new(MockTransactionRepository), &fakeClock{now: time.Now()})
+
+	for name, transfer := range map[string]models.ScheduledTransfer{
+		"no recipient":     {Owner: "lead", Amount: 10, Schedule: "@weekly"},
+		"to self":          {Owner: "lead", ToUser: "lead", Amount: 10, Schedule: "@weekly"},
+		"zero amount":      {Owner: "lead", ToUser: "report", Schedule: "@weekly"},
+		"unknown category": {Owner: "lead", ToUser: "report", Amount: 10, Category: "birthday", Schedule: "@weekly"},
+		"bad schedule":     {Owner: "lead", ToUser: "report", Amount: 10, Schedule: "every monday"},
+	} {
+		err := service.CreateScheduledTransfer(context.Background(), &transfer)
+		assert.ErrorIs(t, err, models.ErrInvalidScheduledTransfer, name)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateScheduledTransfer", mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransferService_Update_ResumeReschedules(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	service := newScheduledTransferService(mockRepo, new(MockTransactionRepository), clock)
+
+	stale := time.Date(2026, 9, 7, 9, 0, 0, 0, time.UTC)
+	mockRepo.On("GetScheduledTransfer", mock.Anything, "lead", int64(1)).Return(&models.ScheduledTransfer{
+		ID: 1, Owner: "lead", ToUser: "report", Amount: 10, Schedule: "0 9 * * 1", NextRunAt: stale,
+	}, nil)
+	mockRepo.On("UpdateScheduledTransfer", mock.Anything, mock.AnythingOfType("*models.ScheduledTransfer"), true).Return(nil)
+
+	active, amount := true, 20
+	transfer, err := service.UpdateScheduledTransfer(context.Background(), "lead", 1, models.ScheduledTransferUpdate{Active: &active, Amount: &amount})
+	assert.NoError(t, err)
+	assert.True(t, transfer.Active)
+	assert.Equal(t, 20, transfer.Amount)
+	assert.Equal(t, time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC), transfer.NextRunAt)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransferService_Update_KeepsNextRun(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	service := newScheduledTransferService(mockRepo, new(MockTransactionRepository), &fakeClock{now: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)})
+
+	mockRepo.On("GetScheduledTransfer", mock.Anything, "lead", int64(1)).Return(&models.ScheduledTransfer{
+		ID: 1, Owner: "lead", ToUser: "report", Amount: 10, Schedule: "0 9 * * 1", Active: true,
+		NextRunAt: time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC),
+	}, nil)
+	mockRepo.On("UpdateScheduledTransfer", mock.Anything, mock.AnythingOfType("*models.ScheduledTransfer"), false).Return(nil)
+
+	amount := 20
+	_, err := service.UpdateScheduledTransfer(context.Background(), "lead", 1, models.ScheduledTransferUpdate{Amount: &amount})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransferService_Update_NotFound(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	service := newScheduledTransferService(mockRepo, new(MockTransactionRepository), &fakeClock{now: time.Now()})
+
+	mockRepo.On("GetScheduledTransfer", mock.Anything, "lead", int64(2)).Return(nil, nil)
+
+	_, err := service.UpdateScheduledTransfer(context.Background(), "lead", 2, models.ScheduledTransferUpdate{})
+	assert.ErrorIs(t, err, models.ErrScheduledTransferNotFound)
+}
+
+func TestScheduledTransferService_RunDue(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+	now := time.Date(2026, 10, 5, 9, 0, 30, 0, time.UTC)
+	service := newScheduledTransferService(mockRepo, mockTransactionRepo, &fakeClock{now: now})
+
+	dueAt := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+	nextRun := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	paid := models.ScheduledTransfer{ID: 1, OwnerID: 7, Owner: "lead", ToUser: "alice", Amount: 10, Message: "weekly thanks", Schedule: "0 9 * * 1", Active: true, NextRunAt: dueAt}
+	broke := models.ScheduledTransfer{ID: 2, OwnerID: 7, Owner: "lead", ToUser: "bob", Amount: 5000, Schedule: "0 9 * * 1", Active: true, NextRunAt: dueAt}
+	taken := models.ScheduledTransfer{ID: 3, OwnerID: 7, Owner: "lead", ToUser: "carol", Amount: 10, Schedule: "0 9 * * 1", Active: true, NextRunAt: dueAt}
+
+	mockRepo.On("GetDueScheduledTransfers", mock.Anything, now, 100).Return([]models.ScheduledTransfer{paid, broke, taken}, nil)
+	claim := func(id int64) interface{} {
+		return mock.MatchedBy(func(run *models.ScheduledTransferRun) bool {
+			return run.ScheduledTransferID == id && run.ScheduledFor.Equal(dueAt) && run.Status == models.ScheduledRunPending
+		})
+	}
+	mockRepo.On("ClaimScheduledTransferRun", mock.Anything, claim(1), nextRun).Return(true, nil).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.ScheduledTransferRun).ID = 11 })
+	mockRepo.On("ClaimScheduledTransferRun", mock.Anything, claim(2), nextRun).Return(true, nil).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.ScheduledTransferRun).ID = 12 })
+	mockRepo.On("ClaimScheduledTransferRun", mock.Anything, claim(3), nextRun).Return(false, nil)
+	mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.MatchedBy(func(transaction *models.CoinTransaction) bool {
+		return transaction.UserID == 7 && transaction.CounterpartUser == "alice" && transaction.Amount == 10 && transaction.Message == "weekly thanks"
+	}), mock.Anything).Return(nil)
+	mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.MatchedBy(func(transaction *models.CoinTransaction) bool {
+		return transaction.CounterpartUser == "bob"
+	}), mock.Anything).Return(&models.InsufficientFundsError{Balance: 100, Required: 5000})
+	mockRepo.On("FinishScheduledTransferRun", mock.Anything, &models.ScheduledTransferRun{
+		ID: 11, ScheduledTransferID: 1, ScheduledFor: dueAt, Status: models.ScheduledRunSucceeded,
+	}).Return(nil)
+	mockRepo.On("FinishScheduledTransferRun", mock.Anything, mock.MatchedBy(func(run *models.ScheduledTransferRun) bool {
+		return run.ID == 12 && run.Status == models.ScheduledRunFailed && run.Error == "not enough coins: balance is 100, 5000 required"
+	})).Return(nil)
+
+	err := service.RunDue(context.Background())
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertNumberOfCalls(t, "CreateTransaction", 2)
+	mockRepo.AssertNumberOfCalls(t, "FinishScheduledTransferRun", 2)
+}
+
+func TestScheduledTransferService_RunDue_ContinuesAfterError(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+	now := time.Date(2026, 10, 5, 9, 0, 30, 0, time.UTC)
+	service := newScheduledTransferService(mockRepo, mockTransactionRepo, &fakeClock{now: now})
+
+	dueAt := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+	first := models.ScheduledTransfer{ID: 1, OwnerID: 7, Owner: "lead", ToUser: "alice", Amount: 10, Schedule: "0 9 * * 1", Active: true, NextRunAt: dueAt}
+	second := models.ScheduledTransfer{ID: 2, OwnerID: 7, Owner: "lead", ToUser: "bob", Amount: 10, Schedule: "0 9 * * 1", Active: true, NextRunAt: dueAt}
+
+	mockRepo.On("GetDueScheduledTransfers", mock.Anything, now, 100).Return([]models.ScheduledTransfer{first, second}, nil)
+	mockRepo.On("ClaimScheduledTransferRun", mock.Anything, mock.MatchedBy(func(run *models.ScheduledTransferRun) bool {
+		return run.ScheduledTransferID == 1
+	}), mock.Anything).Return(false, errors.New("database error"))
+	mockRepo.On("ClaimScheduledTransferRun", mock.Anything, mock.MatchedBy(func(run *models.ScheduledTransferRun) bool {
+		return run.ScheduledTransferID == 2
+	}), mock.Anything).Return(true, nil)
+	mockTransactionRepo.On("CreateTransaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("FinishScheduledTransferRun", mock.Anything, mock.Anything).Return(nil)
+
+	err := service.RunDue(context.Background())
+	assert.ErrorContains(t, err, "scheduled transfer 1: database error")
+
+	mockRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertNumberOfCalls(t, "CreateTransaction", 1)
+}
+
+func TestScheduledTransferService_GetRuns_NotOwner(t *testing.T) {
+	mockRepo := new(MockScheduledTransferRepository)
+	service := newScheduledTransferService(mockRepo, new(MockTransactionRepository), &fakeClock{now: time.Now()})
+
+	mockRepo.On("GetScheduledTransfer", mock.Anything, "mallory", int64(1)).Return(nil, nil)
+
+	_, err := service.GetScheduledTransferRuns(context.Background(), "mallory", 1)
+	assert.ErrorIs(t, err, models.ErrScheduledTransferNotFound)
+
+	mockRepo.AssertNotCalled(t, "GetScheduledTransferRuns", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,96 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type sessionServiceFixture struct {
+	tokenRepo      *MockTokenRepository
+	loginEventRepo *MockLoginEventRepository
+	userRepo       *MockUserRepository
+	clock          *fakeClock
+	service        *services.SessionService
+}
+
+func newSessionServiceFixture() *sessionServiceFixture {
+	f := &sessionServiceFixture{
+		tokenRepo:      new(MockTokenRepository),
+		loginEventRepo: new(MockLoginEventRepository),
+		userRepo:       new(MockUserRepository),
+		clock:          &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	f.service = services.NewSessionService(f.tokenRepo, f.loginEventRepo, f.userRepo, f.clock)
+	return f
+}
+
+func TestGetSessions_MarksCurrentSession(t *testing.T) {
+	f := newSessionServiceFixture()
+
+	f.userRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	f.tokenRepo.On("GetActiveSessions", mock.Anything, int64(1), f.clock.now).Return([]models.Session{{ID: 3}, {ID: 4}}, nil)
+
+	sessions, err := f.service.GetSessions(context.Background(), "testuser", 4)
+
+	assert.NoError(t, err)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestGetSessions_UnknownUser(t *testing.T) {
+	f := newSessionServiceFixture()
+
+	f.userRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+
+	_, err := f.service.GetSessions(context.Background(), "ghost", 0)
+
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	f := newSessionServiceFixture()
+
+	f.userRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	f.tokenRepo.On("RevokeSession", mock.Anything, int64(1), int64(9)).Return(models.ErrSessionNotFound)
+
+	err := f.service.RevokeSession(context.Background(), "testuser", 9)
+
+	assert.ErrorIs(t, err, models.ErrSessionNotFound)
+}
+
+func TestGetLoginEvents_Limit(t *testing.T) {
+	f := newSessionServiceFixture()
+
+	f.loginEventRepo.On("GetLoginEvents", mock.Anything, models.LoginEventFilter{Username: "alice", Limit: services.DefaultLoginEventsLimit}).Return([]models.LoginEvent{}, nil)
+	f.loginEventRepo.On("GetLoginEvents", mock.Anything, models.LoginEventFilter{Limit: services.MaxLoginEventsLimit}).Return([]models.LoginEvent{}, nil)
+
+	_, err := f.service.GetLoginEvents(context.Background(), models.LoginEventFilter{Username: "alice"})
+	assert.NoError(t, err)
+	_, err = f.service.GetLoginEvents(context.Background(), models.LoginEventFilter{Limit: 1_000_000})
+	assert.NoError(t, err)
+
+	f.loginEventRepo.AssertExpectations(t)
+}
+
+func TestRecordLogin(t *testing.T) {
+	f := newSessionServiceFixture()
+	f.clock.now = f.clock.now.In(time.FixedZone("MSK", 3*60*60))
+
+	f.loginEventRepo.On("CreateLoginEvent", mock.Anything, mock.MatchedBy(func(event *models.LoginEvent) bool {
+		return event.Username == "alice" && event.CreatedAt.Equal(f.clock.now) && event.CreatedAt.Location() == time.UTC
+	})).Return(errors.New("database error"))
+
+	f.service.RecordLogin(context.Background(), models.LoginEvent{Username: "alice", IP: "10.0.0.1"})
+
+	f.loginEventRepo.AssertExpectations(t)
+}
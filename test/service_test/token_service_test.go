@@ -0,0 +1,348 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/avito-shop-service/pkg/auth"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestIssueTokens_Success(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	mockRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(session *models.Session) bool {
+		return session.UserID == 1 && session.IP == "10.0.0.1" && session.UserAgent == "curl/8.0"
+	}), mock.MatchedBy(func(token *models.RefreshToken) bool {
+		return token.UserID == 1 && len(token.TokenHash) == 64 && token.ExpiresAt.After(time.Now())
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Session).ID = 42
+	}).Return(nil)
+
+	user := &models.User{ID: 1, Username: "testuser", Roles: []string{"employee", "merch-admin"}, Scopes: []string{"coins:admin"}}
+	tokens, err := service.IssueTokens(context.Background(), user, models.ClientInfo{IP: "10.0.0.1", UserAgent: "curl/8.0"})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, int64(15*60), tokens.ExpiresIn)
+
+	claims, err := tokenManager.ParseToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", claims.EmployeeUsername)
+	assert.NotEmpty(t, claims.ID)
+	assert.Equal(t, []string{"employee", "merch-admin"}, claims.Roles)
+	assert.ElementsMatch(t, []string{"info:read", "coins:send", "merch:buy", "merch:write", "coins:admin"}, claims.Scopes)
+	assert.Equal(t, int64(42), claims.SessionID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	stored := &models.RefreshToken{ID: 7, UserID: 1, SessionID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.On("GetRefreshTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(stored, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Username: "testuser", Roles: []string{"finance-admin"}}, nil)
+	mockRepo.On("RotateRefreshToken", mock.Anything, int64(7), mock.AnythingOfType("*models.RefreshToken"), mock.MatchedBy(func(session *models.Session) bool {
+		return session.ID == 3 && session.IP == "10.0.0.2"
+	})).Return(nil)
+
+	tokens, err := service.Refresh(context.Background(), "refresh-token", models.ClientInfo{IP: "10.0.0.2"})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEqual(t, "refresh-token", tokens.RefreshToken)
+	mockRepo.AssertExpectations(t)
+
+	claims, err := tokenManager.ParseToken(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"finance-admin"}, claims.Roles, "roles must be reloaded on refresh")
+	assert.Equal(t, int64(3), claims.SessionID)
+}
+
+func TestRefresh_UnknownToken(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	mockRepo.On("GetRefreshTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return((*models.RefreshToken)(nil), nil)
+
+	_, err := service.Refresh(context.Background(), "unknown", models.ClientInfo{})
+
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+	mockRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_ExpiredToken(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	stored := &models.RefreshToken{ID: 7, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+	mockRepo.On("GetRefreshTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(stored, nil)
+
+	_, err := service.Refresh(context.Background(), "expired", models.ClientInfo{})
+
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+	mockRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_ReusedTokenRevokesAllUserTokens(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	stored := &models.RefreshToken{ID: 7, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	mockRepo.On("GetRefreshTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(stored, nil)
+	mockRepo.On("RevokeUserSessions", mock.Anything, int64(1)).Return(nil)
+
+	_, err := service.Refresh(context.Background(), "reused", models.ClientInfo{})
+
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_ConcurrentRotationRevokesAllUserTokens(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	stored := &models.RefreshToken{ID: 7, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.On("GetRefreshTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(stored, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockRepo.On("RotateRefreshToken", mock.Anything, int64(7), mock.AnythingOfType("*models.RefreshToken"), mock.AnythingOfType("*models.Session")).Return(models.ErrInvalidRefreshToken)
+	mockRepo.On("RevokeUserSessions", mock.Anything, int64(1)).Return(nil)
+
+	_, err := service.Refresh(context.Background(), "raced", models.ClientInfo{})
+
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_RevokedSessionDoesNotRevokeOtherSessions(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	revokedAt := time.Now().Add(-time.Minute)
+	stored := &models.RefreshToken{ID: 7, UserID: 1, SessionID: 3, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt, SessionRevokedAt: &revokedAt}
+	mockRepo.On("GetRefreshTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(stored, nil)
+
+	_, err := service.Refresh(context.Background(), "revoked-session", models.ClientInfo{})
+
+	assert.ErrorIs(t, err, models.ErrInvalidRefreshToken)
+	mockRepo.AssertNotCalled(t, "RevokeUserSessions", mock.Anything, mock.Anything)
+}
+
+func TestLogout_RevokesSession(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	claims := &auth.Claims{
+		EmployeeUsername: "testuser",
+		SessionID:        3,
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1", ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	}
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	mockRepo.On("RevokeSession", mock.Anything, int64(1), int64(3)).Return(models.ErrSessionNotFound)
+	mockRepo.On("RevokeAccessToken", mock.Anything, "jti-1", expiresAt).Return(nil)
+
+	err := service.Logout(context.Background(), claims, "")
+
+	assert.NoError(t, err, "an already revoked session is not an error")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogout_RevokesAccessAndRefreshTokens(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	claims := &auth.Claims{
+		EmployeeUsername: "testuser",
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1", ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	}
+	mockRepo.On("RevokeAccessToken", mock.Anything, "jti-1", expiresAt).Return(nil)
+	mockRepo.On("RevokeRefreshToken", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	err := service.Logout(context.Background(), claims, "refresh-token")
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogout_Error(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	claims := &auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti-1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	}
+	mockRepo.On("RevokeAccessToken", mock.Anything, "jti-1", mock.Anything).Return(errors.New("database error"))
+
+	err := service.Logout(context.Background(), claims, "")
+
+	assert.EqualError(t, err, "error revoking access token: database error")
+	mockRepo.AssertNotCalled(t, "RevokeRefreshToken", mock.Anything, mock.Anything)
+}
+
+func TestValidateAccessToken_Success(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	token, err := tokenManager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+	mockRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string"), int64(0)).Return(false, nil)
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
+
+	claims, err := service.ValidateAccessToken(context.Background(), token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "testuser", claims.EmployeeUsername)
+}
+
+func TestValidateAccessToken_Revoked(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	token, err := tokenManager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+	mockRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string"), int64(0)).Return(true, nil)
+
+	_, err = service.ValidateAccessToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrTokenRevoked)
+}
+
+func TestValidateAccessToken_InvalidToken(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	_, err := service.ValidateAccessToken(context.Background(), "invalid.token.value")
+
+	assert.ErrorIs(t, err, models.ErrInvalidToken)
+	mockRepo.AssertNotCalled(t, "IsAccessTokenRevoked", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestValidateAccessToken_IssuedBeforePasswordChange(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	tokenManager := newTokenManagerWithClock(t, clock)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	oldToken, err := tokenManager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+	passwordChangedAt := clock.now.Add(time.Second)
+	clock.now = passwordChangedAt.Add(500 * time.Millisecond)
+	newToken, err := tokenManager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+
+	mockRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string"), int64(0)).Return(false, nil)
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").
+		Return(&models.User{ID: 1, Username: "testuser", PasswordChangedAt: &passwordChangedAt}, nil)
+
+	_, err = service.ValidateAccessToken(context.Background(), oldToken)
+	assert.ErrorIs(t, err, models.ErrTokenRevoked)
+
+	_, err = service.ValidateAccessToken(context.Background(), newToken)
+	assert.NoError(t, err, "tokens issued in the second of the change stay valid")
+}
+
+func TestValidateAccessToken_UnknownUser(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	token, err := tokenManager.IssueToken(auth.Claims{EmployeeUsername: "ghost"})
+	assert.NoError(t, err)
+	mockRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string"), int64(0)).Return(false, nil)
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+
+	_, err = service.ValidateAccessToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrInvalidToken)
+}
+
+func TestValidateAccessToken_RevokedSession(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	token, err := tokenManager.IssueToken(auth.Claims{EmployeeUsername: "testuser", SessionID: 3})
+	assert.NoError(t, err)
+	mockRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string"), int64(3)).Return(true, nil)
+
+	_, err = service.ValidateAccessToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrTokenRevoked)
+}
+
+func TestValidateAccessToken_InactiveUser(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	token, err := tokenManager.IssueToken(auth.Claims{EmployeeUsername: "testuser"})
+	assert.NoError(t, err)
+	mockRepo.On("IsAccessTokenRevoked", mock.Anything, mock.AnythingOfType("string"), int64(0)).Return(false, nil)
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "testuser").
+		Return(&models.User{ID: 1, Username: "testuser", Profile: models.Profile{Status: models.UserStatusSuspended}}, nil)
+
+	_, err = service.ValidateAccessToken(context.Background(), token)
+
+	assert.ErrorIs(t, err, models.ErrAccountInactive)
+}
+
+func TestRefresh_InactiveUser(t *testing.T) {
+	mockRepo := new(MockTokenRepository)
+	mockUserRepo := new(MockUserRepository)
+	tokenManager := newTokenManager(t)
+	service := services.NewTokenService(mockRepo, mockUserRepo, tokenManager, time.Hour)
+
+	stored := &models.RefreshToken{ID: 7, UserID: 1, SessionID: 3, ExpiresAt: time.Now().Add(time.Hour)}
+	mockRepo.On("GetRefreshTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(stored, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, int64(1)).
+		Return(&models.User{ID: 1, Username: "testuser", Profile: models.Profile{Status: models.UserStatusTerminated}}, nil)
+
+	_, err := service.Refresh(context.Background(), "refresh-token", models.ClientInfo{})
+
+	assert.ErrorIs(t, err, models.ErrAccountInactive)
+	mockRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
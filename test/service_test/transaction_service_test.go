@@ -9,6 +9,7 @@ import (
 	"github.com/avito-shop-service/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,7 +18,7 @@ import (
 
 func TestGetTransactionsByUserID(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	userID := int64(1)
 	expectedTransactions := []models.CoinTransaction{
@@ -36,7 +37,7 @@ func TestGetTransactionsByUserID(t *testing.T) {
 
 func TestGetTransactionsByUserID_NegativeID(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	userID := int64(-1)
 
@@ -49,13 +50,13 @@ func TestGetTransactionsByUserID_NegativeID(t *testing.T) {
 
 func TestCreateTransaction_Success(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	transaction := &models.CoinTransaction{
 		ID: 1, UserID: 1, CounterpartUser: "user2", Amount: 50, TransactionType: "send", CreatedAt: time.Now(),
 	}
 
-	mockRepo.On("CreateTransaction", mock.Anything, transaction).Return(nil)
+	mockRepo.On("CreateTransaction", mock.Anything, transaction, mock.Anything).Return(nil)
 
 	err := service.CreateTransaction(context.Background(), transaction)
 	assert.Nil(t, err)
@@ -65,7 +66,7 @@ func TestCreateTransaction_Success(t *testing.T) {
 
 func TestCreateTransaction_NegativeUserID(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	transaction := &models.CoinTransaction{
 		ID: 1, UserID: -1, CounterpartUser: "user2", Amount: 50, TransactionType: "send", CreatedAt: time.Now(),
@@ -79,7 +80,7 @@ func TestCreateTransaction_NegativeUserID(t *testing.T) {
 
 func TestCreateTransaction_InvalidAmount(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	transaction := &models.CoinTransaction{
 		ID: 1, UserID: 1, CounterpartUser: "user2", Amount: 0, TransactionType: "send", CreatedAt: time.Now(),
@@ -93,7 +94,7 @@ func TestCreateTransaction_InvalidAmount(t *testing.T) {
 
 func TestCreateTransaction_InvalidTransactionType(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	transaction := &models.CoinTransaction{
 		ID: 1, UserID: 1, CounterpartUser: "user2", Amount: 50, TransactionType: "transfer", CreatedAt: time.Now(),
@@ -107,7 +108,7 @@ func TestCreateTransaction_InvalidTransactionType(t *testing.T) {
 
 func TestGetTransactionsByUserID_NoTransactions(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	userID := int64(1)
 	mockRepo.On("GetTransactionsByUserID", mock.Anything, userID).Return([]models.CoinTransaction{}, nil)
@@ -121,7 +122,7 @@ func TestGetTransactionsByUserID_NoTransactions(t *testing.T) {
 
 func TestGetTransactionsByUserID_ErrorFromRepo(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	userID := int64(1)
 	mockRepo.On("GetTransactionsByUserID", mock.Anything, userID).Return(([]models.CoinTransaction)(nil), fmt.Errorf("database error"))
@@ -135,7 +136,7 @@ func TestGetTransactionsByUserID_ErrorFromRepo(t *testing.T) {
 
 func TestCreateTransaction_ZeroAmount(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	transaction := &models.CoinTransaction{
 		ID: 1, UserID: 1, CounterpartUser: "user2", Amount: 0, TransactionType: "send", CreatedAt: time.Now(),
@@ -149,13 +150,13 @@ func TestCreateTransaction_ZeroAmount(t *testing.T) {
 
 func TestCreateTransaction_TooLargeAmount(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	transaction := &models.CoinTransaction{
 		ID: 1, UserID: 1, CounterpartUser: "user2", Amount: 1_000_000_000, TransactionType: "send", CreatedAt: time.Now(),
 	}
 
-	mockRepo.On("CreateTransaction", mock.Anything, transaction).Return(fmt.Errorf("amount exceeds limit"))
+	mockRepo.On("CreateTransaction", mock.Anything, transaction, mock.Anything).Return(fmt.Errorf("amount exceeds limit"))
 
 	err := service.CreateTransaction(context.Background(), transaction)
 	assert.EqualError(t, err, "amount exceeds limit")
@@ -165,16 +166,239 @@ func TestCreateTransaction_TooLargeAmount(t *testing.T) {
 
 func TestCreateTransaction_ErrorFromRepo(t *testing.T) {
 	mockRepo := new(MockTransactionRepository)
-	service := services.NewTransactionService(mockRepo)
+	service := services.NewTransactionService(mockRepo, nil, nil)
 
 	transaction := &models.CoinTransaction{
 		ID: 1, UserID: 1, CounterpartUser: "user2", Amount: 50, TransactionType: "send", CreatedAt: time.Now(),
 	}
 
-	mockRepo.On("CreateTransaction", mock.Anything, transaction).Return(fmt.Errorf("database error"))
+	mockRepo.On("CreateTransaction", mock.Anything, transaction, mock.Anything).Return(fmt.Errorf("database error"))
 
 	err := service.CreateTransaction(context.Background(), transaction)
 	assert.EqualError(t, err, "database error")
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestCreateTransaction_WithMessageAndCategory(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, []string{"great review"}, nil)
+
+	transaction := &models.CoinTransaction{
+		UserID: 1, CounterpartUser: "user2", Amount: 50, TransactionType: "send",
+		Message: "thanks for the thorough review", Category: "great review",
+	}
+
+	mockRepo.On("CreateTransaction", mock.Anything, transaction, mock.Anything).Return(nil)
+
+	err := service.CreateTransaction(context.Background(), transaction)
+	assert.Nil(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTransaction_UnknownCategory(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, []string{"great review"}, nil)
+
+	transaction := &models.CoinTransaction{
+		UserID: 1, CounterpartUser: "user2", Amount: 50, TransactionType: "send", Category: "birthday",
+	}
+
+	err := service.CreateTransaction(context.Background(), transaction)
+	assert.ErrorIs(t, err, models.ErrUnknownCategory)
+
+	mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateTransaction_MessageTooLong(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	transaction := &models.CoinTransaction{
+		UserID: 1, CounterpartUser: "user2", Amount: 50, TransactionType: "send", Message: strings.Repeat("спасибо", 72),
+	}
+
+	err := service.CreateTransaction(context.Background(), transaction)
+	assert.ErrorIs(t, err, models.ErrMessageTooLong)
+
+	mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_DefaultCategories(t *testing.T) {
+	service := services.NewTransactionService(new(MockTransactionRepository), nil, nil)
+
+	assert.Equal(t, services.DefaultKudosCategories, service.Categories())
+}
+
+func TestGetTransfers_CapsLimit(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	mockRepo.On("GetTransfers", mock.Anything, models.TransferFilter{Category: "great review", Limit: services.MaxTransfersLimit}).
+		Return([]models.Transfer{{ID: 1, FromUser: "user1", ToUser: "user2", Amount: 10, Category: "great review"}}, nil)
+
+	transfers, err := service.GetTransfers(context.Background(), models.TransferFilter{Category: "great review", Limit: 5000})
+	assert.Nil(t, err)
+	assert.Len(t, transfers, 1)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetHistory_Paginates(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 123456000, time.UTC)
+	history := []models.HistoryEntry{
+		{ID: 30, Type: models.EntryKindTransfer, Direction: models.DirectionSent, Counterpart: "user2", Amount: 10, CreatedAt: createdAt.Add(time.Hour)},
+		{ID: 20, Type: models.EntryKindPurchase, Direction: models.DirectionSent, Counterpart: models.AccountShop, Amount: 80, CreatedAt: createdAt},
+		{ID: 10, Type: models.EntryKindGrant, Direction: models.DirectionReceived, Counterpart: models.AccountIssuance, Amount: 1000, CreatedAt: createdAt.Add(-time.Hour)},
+	}
+	mockRepo.On("GetHistory", mock.Anything, models.TransactionFilter{UserID: 1, Limit: 3}).Return(history, nil)
+
+	page, err := service.GetHistory(context.Background(), models.TransactionFilter{UserID: 1, Limit: 2}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, history[:2], page.Transactions)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mockRepo.On("GetHistory", mock.Anything, models.TransactionFilter{UserID: 1, Limit: 3, After: &models.HistoryCursor{CreatedAt: createdAt, ID: 20}}).
+		Return(history[2:], nil)
+
+	page, err = service.GetHistory(context.Background(), models.TransactionFilter{UserID: 1, Limit: 2}, page.NextCursor)
+	assert.Nil(t, err)
+	assert.Equal(t, history[2:], page.Transactions)
+	assert.Empty(t, page.NextCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetHistory_DefaultPageSize(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	mockRepo.On("GetHistory", mock.Anything, models.TransactionFilter{UserID: 1, Direction: models.DirectionReceived, Limit: services.DefaultTransactionsPageSize + 1}).
+		Return(nil, nil)
+
+	page, err := service.GetHistory(context.Background(), models.TransactionFilter{UserID: 1, Direction: models.DirectionReceived}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []models.HistoryEntry{}, page.Transactions)
+	assert.Empty(t, page.NextCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetHistory_InvalidQuery(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	since := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for name, test := range map[string]struct {
+		filter models.TransactionFilter
+		cursor string
+	}{
+		"direction":   {filter: models.TransactionFilter{Direction: "sideways"}},
+		"type":        {filter: models.TransactionFilter{Type: "gift"}},
+		"time range":  {filter: models.TransactionFilter{Since: &since, Until: &until}},
+		"cursor":      {cursor: "not a cursor"},
+		"cursor data": {cursor: "MTIz"},
+	} {
+		_, err := service.GetHistory(context.Background(), test.filter, test.cursor)
+		assert.ErrorIs(t, err, models.ErrInvalidHistoryQuery, name)
+	}
+
+	mockRepo.AssertNotCalled(t, "GetHistory", mock.Anything, mock.Anything)
+}
+
+func TestCreateTransactions_Success(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	transactions := []models.CoinTransaction{
+		{UserID: 1, CounterpartUser: "bob", Amount: 100, TransactionType: "send"},
+		{UserID: 1, CounterpartUser: "carol", Amount: 50, TransactionType: "send", Category: "mentoring"},
+	}
+	mockRepo.On("CreateTransactions", mock.Anything, transactions, mock.Anything).Return(nil)
+
+	assert.NoError(t, service.CreateTransactions(context.Background(), transactions))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTransactions_Invalid(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	err := service.CreateTransactions(context.Background(), nil)
+	assert.ErrorIs(t, err, models.ErrInvalidBatchTransfer)
+
+	tests := []struct {
+		name   string
+		second models.CoinTransaction
+		err    error
+	}{
+		{"duplicate recipient", models.CoinTransaction{UserID: 1, CounterpartUser: "bob", Amount: 10}, models.ErrInvalidBatchTransfer},
+		{"zero amount", models.CoinTransaction{UserID: 1, CounterpartUser: "carol"}, models.ErrInvalidBatchTransfer},
+		{"other sender", models.CoinTransaction{UserID: 2, CounterpartUser: "carol", Amount: 10}, models.ErrInvalidBatchTransfer},
+		{"unknown category", models.CoinTransaction{UserID: 1, CounterpartUser: "carol", Amount: 10, Category: "unknown"}, models.ErrUnknownCategory},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.CreateTransactions(context.Background(), []models.CoinTransaction{
+				{UserID: 1, CounterpartUser: "bob", Amount: 100},
+				tt.second,
+			})
+			assert.ErrorIs(t, err, tt.err)
+			var batchErr *models.TransferBatchError
+			if assert.ErrorAs(t, err, &batchErr) {
+				assert.Equal(t, 1, batchErr.Index)
+			}
+		})
+	}
+	mockRepo.AssertNotCalled(t, "CreateTransactions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateTransactions_PassesLimitsToRepository(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockLimitRepo := new(MockTransferLimitRepository)
+	limits := services.NewTransferLimitService(mockLimitRepo, new(MockUserRepository), services.TransferLimitPolicy{DailyLimit: 400}, &fakeClock{now: time.Now()})
+	service := services.NewTransactionService(mockRepo, nil, limits)
+
+	transactions := []models.CoinTransaction{
+		{UserID: 1, CounterpartUser: "bob", Amount: 200, TransactionType: "send"},
+		{UserID: 1, CounterpartUser: "carol", Amount: 200, TransactionType: "send"},
+	}
+	mockLimitRepo.On("GetTransferLimitException", mock.Anything, int64(1)).Return(nil, nil)
+	mockRepo.On("CreateTransactions", mock.Anything, transactions, mock.MatchedBy(func(check *models.TransferLimitCheck) bool {
+		return check != nil && check.Limits.DailyLimit == 400
+	})).Return(&models.TransferBatchError{Index: 1, ToUser: "carol", Err: &models.TransferLimitError{Rule: models.RuleDailyLimit, Limit: 400, Used: 300}})
+
+	err := service.CreateTransactions(context.Background(), transactions)
+	assert.EqualError(t, err, "transfer to carol: transfer limit exceeded: daily_limit is 400 coins, 300 already sent")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTransaction_ToYourself(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	err := service.CreateTransaction(context.Background(), &models.CoinTransaction{UserID: 1, FromUser: "alice", CounterpartUser: "alice", Amount: 100, TransactionType: "send"})
+	assert.ErrorIs(t, err, models.ErrSelfTransfer)
+	mockRepo.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateTransactions_ToYourself(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransactionService(mockRepo, nil, nil)
+
+	err := service.CreateTransactions(context.Background(), []models.CoinTransaction{
+		{UserID: 1, FromUser: "alice", CounterpartUser: "bob", Amount: 100, TransactionType: "send"},
+		{UserID: 1, FromUser: "alice", CounterpartUser: "alice", Amount: 100, TransactionType: "send"},
+	})
+	assert.ErrorIs(t, err, models.ErrSelfTransfer)
+	var batchErr *models.TransferBatchError
+	if assert.ErrorAs(t, err, &batchErr) {
+		assert.Equal(t, 1, batchErr.Index)
+	}
+	mockRepo.AssertNotCalled(t, "CreateTransactions", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,177 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var transferLimitPolicy = services.TransferLimitPolicy{
+	MaxPerTransfer:  200,
+	DailyLimit:      300,
+	MonthlyLimit:    1000,
+	RecipientLimit:  250,
+	RecipientPeriod: 7 * 24 * time.Hour,
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestTransferLimitService_LimitCheck(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	mockRepo := new(MockTransferLimitRepository)
+	service := services.NewTransferLimitService(mockRepo, new(MockUserRepository), transferLimitPolicy, &fakeClock{now: now})
+
+	mockRepo.On("GetTransferLimitException", mock.Anything, int64(1)).Return(nil, nil)
+
+	check, err := service.LimitCheck(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.TransferLimitCheck{
+		Limits:         models.TransferLimits{MaxPerTransfer: 200, DailyLimit: 300, MonthlyLimit: 1000, RecipientLimit: 250},
+		DayStart:       time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+		MonthStart:     time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		RecipientStart: time.Date(2026, 10, 10, 15, 30, 0, 0, time.UTC),
+	}, check)
+}
+
+func TestTransferLimitCheck_Evaluate(t *testing.T) {
+	check := &models.TransferLimitCheck{
+		Limits: models.TransferLimits{MaxPerTransfer: 200, DailyLimit: 300, MonthlyLimit: 1000, RecipientLimit: 250},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		amount int
+		usage  models.TransferUsage
+		rule   string
+	}{
+		{name: "within limits", amount: 100, usage: models.TransferUsage{Daily: 100, Monthly: 500, Recipient: 100}},
+		{name: "exactly at limits", amount: 150, usage: models.TransferUsage{Daily: 150, Monthly: 850, Recipient: 100}},
+		{name: "too large", amount: 201, rule: models.RuleMaxPerTransfer},
+		{name: "daily", amount: 100, usage: models.TransferUsage{Daily: 250}, rule: models.RuleDailyLimit},
+		{name: "monthly", amount: 100, usage: models.TransferUsage{Daily: 100, Monthly: 950}, rule: models.RuleMonthlyLimit},
+		{name: "recipient", amount: 100, usage: models.TransferUsage{Daily: 100, Monthly: 100, Recipient: 200}, rule: models.RuleRecipientLimit},
+	} {
+		err := check.Evaluate(tc.usage, tc.amount)
+		if tc.rule == "" {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+		var limitErr *models.TransferLimitError
+		if assert.ErrorAs(t, err, &limitErr, tc.name) {
+			assert.Equal(t, tc.rule, limitErr.Rule, tc.name)
+		}
+		assert.ErrorIs(t, err, models.ErrTransferLimitExceeded, tc.name)
+	}
+}
+
+func TestTransferLimitService_LimitCheck_NoLimits(t *testing.T) {
+	mockRepo := new(MockTransferLimitRepository)
+	service := services.NewTransferLimitService(mockRepo, new(MockUserRepository), services.TransferLimitPolicy{}, &fakeClock{now: time.Now()})
+
+	mockRepo.On("GetTransferLimitException", mock.Anything, int64(1)).Return(nil, nil)
+
+	check, err := service.LimitCheck(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Nil(t, check)
+}
+
+func TestTransferLimitService_LimitCheck_Exception(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+
+	mockRepo := new(MockTransferLimitRepository)
+	clock := &fakeClock{now: now}
+	service := services.NewTransferLimitService(mockRepo, new(MockUserRepository), transferLimitPolicy, clock)
+
+	mockRepo.On("GetTransferLimitException", mock.Anything, int64(1)).Return(&models.TransferLimitException{
+		UserID: 1, MaxPerTransfer: intPtr(0), DailyLimit: intPtr(5000), Reason: "team reward", ExpiresAt: &expiresAt,
+	}, nil)
+
+	// The exception lifts the per-transfer maximum and raises the daily limit, the monthly and
+	// recipient limits still apply.
+	check, err := service.LimitCheck(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TransferLimits{DailyLimit: 5000, MonthlyLimit: 1000, RecipientLimit: 250}, check.Limits)
+
+	clock.now = expiresAt
+	check, err = service.LimitCheck(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TransferLimits{MaxPerTransfer: 200, DailyLimit: 300, MonthlyLimit: 1000, RecipientLimit: 250}, check.Limits)
+}
+
+func TestTransferLimitService_SetException(t *testing.T) {
+	mockRepo := new(MockTransferLimitRepository)
+	mockUserRepo := new(MockUserRepository)
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	service := services.NewTransferLimitService(mockRepo, mockUserRepo, transferLimitPolicy, &fakeClock{now: now})
+
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "alice").Return(&models.User{ID: 5, Username: "alice"}, nil)
+	mockRepo.On("SaveTransferLimitException", mock.Anything, mock.MatchedBy(func(exception *models.TransferLimitException) bool {
+		return exception.UserID == 5 && exception.Reason == "quarterly awards" && *exception.DailyLimit == 2000
+	})).Return(nil)
+
+	err := service.SetException(context.Background(), &models.TransferLimitException{
+		Username: "alice", DailyLimit: intPtr(2000), Reason: " quarterly awards ", CreatedBy: "admin",
+	})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransferLimitService_SetException_Invalid(t *testing.T) {
+	mockRepo := new(MockTransferLimitRepository)
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	service := services.NewTransferLimitService(mockRepo, new(MockUserRepository), transferLimitPolicy, &fakeClock{now: now})
+
+	past := now.Add(-time.Minute)
+	for name, exception := range map[string]models.TransferLimitException{
+		"no reason":      {Username: "alice", DailyLimit: intPtr(10)},
+		"no limits":      {Username: "alice", Reason: "because"},
+		"negative limit": {Username: "alice", DailyLimit: intPtr(-1), Reason: "because"},
+		"expired":        {Username: "alice", DailyLimit: intPtr(10), Reason: "because", ExpiresAt: &past},
+	} {
+		err := service.SetException(context.Background(), &exception)
+		assert.ErrorIs(t, err, models.ErrInvalidLimitException, name)
+	}
+
+	mockRepo.AssertNotCalled(t, "SaveTransferLimitException", mock.Anything, mock.Anything)
+}
+
+func TestTransferLimitService_DeleteException_NotFound(t *testing.T) {
+	mockRepo := new(MockTransferLimitRepository)
+	mockUserRepo := new(MockUserRepository)
+	service := services.NewTransferLimitService(mockRepo, mockUserRepo, transferLimitPolicy, &fakeClock{now: time.Now()})
+
+	mockUserRepo.On("GetUserByUsername", mock.Anything, "alice").Return(&models.User{ID: 5, Username: "alice"}, nil)
+	mockRepo.On("DeleteTransferLimitException", mock.Anything, int64(5)).Return(false, nil)
+
+	err := service.DeleteException(context.Background(), "alice")
+	assert.ErrorIs(t, err, models.ErrLimitExceptionNotFound)
+}
+
+func TestCreateTransaction_PassesLimitsToRepository(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	mockLimitRepo := new(MockTransferLimitRepository)
+	limits := services.NewTransferLimitService(mockLimitRepo, new(MockUserRepository), services.TransferLimitPolicy{MaxPerTransfer: 100}, &fakeClock{now: time.Now()})
+	service := services.NewTransactionService(mockRepo, nil, limits)
+
+	transaction := &models.CoinTransaction{UserID: 1, CounterpartUser: "bob", Amount: 150, TransactionType: "send"}
+	limitErr := &models.TransferLimitError{Rule: models.RuleMaxPerTransfer, Limit: 100}
+	mockLimitRepo.On("GetTransferLimitException", mock.Anything, int64(1)).Return(nil, nil)
+	mockRepo.On("CreateTransaction", mock.Anything, transaction, mock.MatchedBy(func(check *models.TransferLimitCheck) bool {
+		return check != nil && check.Limits.MaxPerTransfer == 100
+	})).Return(limitErr)
+
+	err := service.CreateTransaction(context.Background(), transaction)
+	assert.EqualError(t, err, "transfer limit exceeded: max_per_transfer is 100 coins")
+}
@@ -0,0 +1,113 @@
+//go:build unit
+// +build unit
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/avito-shop-service/internal/models"
+	"github.com/avito-shop-service/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransferReversalService_CancelTransfer(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	sentAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	service := services.NewTransferReversalService(mockRepo, 10*time.Minute, &fakeClock{now: sentAt.Add(9 * time.Minute)})
+
+	mockRepo.On("GetTransfer", mock.Anything, int64(42)).
+		Return(&models.Transfer{ID: 42, FromUser: "alice", ToUser: "bob", Amount: 50, CreatedAt: sentAt}, nil)
+	mockRepo.On("ReverseTransfer", mock.Anything, mock.MatchedBy(func(reversal *models.TransferReversal) bool {
+		return reversal.TransferID == 42 && reversal.ReversedBy == "alice" && reversal.Reason == ""
+	}), true).Run(func(args mock.Arguments) {
+		reversal := args.Get(1).(*models.TransferReversal)
+		reversal.ID = 43
+		reversal.Amount = 50
+	}).Return(nil)
+
+	reversal, err := service.CancelTransfer(context.Background(), "alice", 42)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(43), reversal.ID)
+	assert.Equal(t, 50, reversal.Amount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransferReversalService_CancelTransfer_WindowClosed(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	sentAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	service := services.NewTransferReversalService(mockRepo, 10*time.Minute, &fakeClock{now: sentAt.Add(10 * time.Minute)})
+
+	mockRepo.On("GetTransfer", mock.Anything, int64(42)).
+		Return(&models.Transfer{ID: 42, FromUser: "alice", ToUser: "bob", Amount: 50, CreatedAt: sentAt}, nil)
+
+	_, err := service.CancelTransfer(context.Background(), "alice", 42)
+	assert.ErrorIs(t, err, models.ErrTransferNotReversible)
+	assert.EqualError(t, err, "transfer cannot be reversed: the cancellation window closed at 2026-10-17T12:10:00Z")
+	mockRepo.AssertNotCalled(t, "ReverseTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransferReversalService_CancelTransfer_NotOwnOrReversed(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	sentAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	service := services.NewTransferReversalService(mockRepo, 0, &fakeClock{now: sentAt.Add(time.Minute)})
+
+	reversalID := int64(44)
+	mockRepo.On("GetTransfer", mock.Anything, int64(42)).
+		Return(&models.Transfer{ID: 42, FromUser: "alice", ToUser: "bob", Amount: 50, CreatedAt: sentAt}, nil)
+	mockRepo.On("GetTransfer", mock.Anything, int64(43)).
+		Return(&models.Transfer{ID: 43, FromUser: "alice", ToUser: "bob", Amount: 50, ReversalID: &reversalID, CreatedAt: sentAt}, nil)
+	mockRepo.On("GetTransfer", mock.Anything, int64(99)).Return(nil, nil)
+
+	_, err := service.CancelTransfer(context.Background(), "bob", 42)
+	assert.ErrorIs(t, err, models.ErrTransferNotFound)
+
+	_, err = service.CancelTransfer(context.Background(), "alice", 99)
+	assert.ErrorIs(t, err, models.ErrTransferNotFound)
+
+	_, err = service.CancelTransfer(context.Background(), "alice", 43)
+	assert.ErrorIs(t, err, models.ErrTransferAlreadyReversed)
+
+	mockRepo.AssertNotCalled(t, "ReverseTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransferReversalService_CancelTransfer_Spent(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	sentAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	service := services.NewTransferReversalService(mockRepo, 0, &fakeClock{now: sentAt.Add(time.Minute)})
+
+	mockRepo.On("GetTransfer", mock.Anything, int64(42)).
+		Return(&models.Transfer{ID: 42, FromUser: "alice", ToUser: "bob", Amount: 50, CreatedAt: sentAt}, nil)
+	mockRepo.On("ReverseTransfer", mock.Anything, mock.Anything, true).
+		Return(fmt.Errorf("%w: bob has spent coins since the transfer", models.ErrTransferNotReversible))
+
+	_, err := service.CancelTransfer(context.Background(), "alice", 42)
+	assert.ErrorIs(t, err, models.ErrTransferNotReversible)
+}
+
+func TestTransferReversalService_ReverseTransfer(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransferReversalService(mockRepo, 0, nil)
+
+	mockRepo.On("ReverseTransfer", mock.Anything, mock.MatchedBy(func(reversal *models.TransferReversal) bool {
+		return reversal.TransferID == 42 && reversal.ReversedBy == "admin" && reversal.Reason == "sent to the wrong bob"
+	}), false).Return(nil)
+
+	reversal, err := service.ReverseTransfer(context.Background(), "admin", 42, " sent to the wrong bob ")
+	assert.NoError(t, err)
+	assert.Equal(t, "sent to the wrong bob", reversal.Reason)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransferReversalService_ReverseTransfer_NoReason(t *testing.T) {
+	mockRepo := new(MockTransactionRepository)
+	service := services.NewTransferReversalService(mockRepo, 0, nil)
+
+	_, err := service.ReverseTransfer(context.Background(), "admin", 42, "  ")
+	assert.ErrorIs(t, err, models.ErrInvalidReversal)
+	mockRepo.AssertNotCalled(t, "ReverseTransfer", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -5,17 +5,51 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"github.com/avito-shop-service/internal/models"
 	"github.com/avito-shop-service/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"strings"
 	"testing"
+	"time"
 )
 
+var testClient = models.ClientInfo{IP: "10.0.0.1"}
+
+func newUserService(t *testing.T, repo *MockUserRepository) *services.UserService {
+	return newUserServiceWithGuard(t, repo, permissiveLoginGuard())
+}
+
+func newUserServiceWithGuard(t *testing.T, repo *MockUserRepository, guard *MockLoginGuard) *services.UserService {
+	return services.NewUserService(repo, newTestTokenService(t, repo), guard, permissiveLoginRecorder(), services.NewLocalAuthenticator(repo))
+}
+
+func newTestTokenService(t *testing.T, repo *MockUserRepository) *services.TokenService {
+	tokenRepo := new(MockTokenRepository)
+	tokenRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("*models.Session"), mock.AnythingOfType("*models.RefreshToken")).Return(nil)
+	return services.NewTokenService(tokenRepo, repo, newTokenManager(t), time.Hour)
+}
+
+func permissiveLoginRecorder() *MockLoginRecorder {
+	recorder := new(MockLoginRecorder)
+	recorder.On("RecordLogin", mock.Anything, mock.Anything).Return()
+	return recorder
+}
+
+func permissiveLoginGuard() *MockLoginGuard {
+	guard := new(MockLoginGuard)
+	guard.On("Check", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	guard.On("RecordFailure", mock.Anything, mock.Anything, mock.Anything).Return()
+	guard.On("RecordSuccess", mock.Anything, mock.Anything).Return()
+	return guard
+}
+
 func TestCreateUser_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "testuser"
 	password := "password123"
@@ -28,12 +62,13 @@ func TestCreateUser_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, username, createdUser.Username)
 	assert.Equal(t, 1000, createdUser.Coins)
+	assert.Equal(t, []string{"employee"}, createdUser.Roles)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestCreateUser_AlreadyExists(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "existinguser"
 	existingUser := &models.User{Username: username}
@@ -48,34 +83,98 @@ func TestCreateUser_AlreadyExists(t *testing.T) {
 
 func TestAuthenticate_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "testuser"
 	password := "password123"
-	hashedPassword := services.HashPassword(password)
+	hashedPassword, err := services.HashPassword(password)
+	assert.NoError(t, err)
 	user := &models.User{Username: username, Password: hashedPassword}
 
 	mockRepo.On("GetUserByUsername", mock.Anything, username).Return(user, nil)
 
-	token, err := service.Authenticate(context.Background(), username, password)
+	tokens, err := service.Authenticate(context.Background(), username, password, testClient)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdateUserPassword")
+}
+
+func TestAuthenticate_LegacyHashIsUpgraded(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newUserService(t, mockRepo)
+
+	username := "testuser"
+	password := "password123"
+	legacyHash := sha256.Sum256([]byte(password))
+	user := &models.User{ID: 1, Username: username, Password: hex.EncodeToString(legacyHash[:])}
+
+	mockRepo.On("GetUserByUsername", mock.Anything, username).Return(user, nil)
+	mockRepo.On("UpdateUserPassword", mock.Anything, int64(1), mock.MatchedBy(func(hash string) bool {
+		ok, needsRehash := services.VerifyPassword(password, hash)
+		return ok && !needsRehash
+	})).Return(nil)
+
+	tokens, err := service.Authenticate(context.Background(), username, password, testClient)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthenticate_LegacyHashUpgradeFailureDoesNotBlockLogin(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newUserService(t, mockRepo)
+
+	username := "testuser"
+	password := "password123"
+	legacyHash := sha256.Sum256([]byte(password))
+	user := &models.User{ID: 1, Username: username, Password: hex.EncodeToString(legacyHash[:])}
+
+	mockRepo.On("GetUserByUsername", mock.Anything, username).Return(user, nil)
+	mockRepo.On("UpdateUserPassword", mock.Anything, int64(1), mock.AnythingOfType("string")).Return(errors.New("update failed"))
+
+	tokens, err := service.Authenticate(context.Background(), username, password, testClient)
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestHashPassword_IsSalted(t *testing.T) {
+	first, err := services.HashPassword("password123")
+	assert.NoError(t, err)
+	second, err := services.HashPassword("password123")
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.True(t, strings.HasPrefix(first, "$argon2id$"))
+
+	ok, needsRehash := services.VerifyPassword("password123", first)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	ok, _ = services.VerifyPassword("wrongpassword", first)
+	assert.False(t, ok)
+}
+
 func TestAuthenticate_InvalidPassword(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "testuser"
 	password := "wrongpassword"
-	hashedPassword := services.HashPassword("password123")
+	hashedPassword, err := services.HashPassword("password123")
+	assert.NoError(t, err)
 	user := &models.User{Username: username, Password: hashedPassword}
 
 	mockRepo.On("GetUserByUsername", mock.Anything, username).Return(user, nil)
 
-	_, err := service.Authenticate(context.Background(), username, password)
+	_, err = service.Authenticate(context.Background(), username, password, testClient)
 
 	assert.EqualError(t, err, "invalid username or password")
 	mockRepo.AssertExpectations(t)
@@ -83,7 +182,7 @@ func TestAuthenticate_InvalidPassword(t *testing.T) {
 
 func TestUpdateUserCoins_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	userID := int64(1)
 	coins := 500
@@ -98,7 +197,7 @@ func TestUpdateUserCoins_Success(t *testing.T) {
 
 func TestUpdateUserCoins_NegativeAmount(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	err := service.UpdateUserCoins(context.Background(), 1, -500)
 
@@ -108,7 +207,7 @@ func TestUpdateUserCoins_NegativeAmount(t *testing.T) {
 
 func TestCreateUser_ErrorCheckingExistingUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "testuser"
 	password := "password123"
@@ -123,7 +222,7 @@ func TestCreateUser_ErrorCheckingExistingUser(t *testing.T) {
 
 func TestCreateUser_ErrorCreatingUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "newuser"
 	password := "password123"
@@ -138,7 +237,7 @@ func TestCreateUser_ErrorCreatingUser(t *testing.T) {
 
 func TestGetUserByUsername_EmptyUsername(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	_, err := service.GetUserByUsername(context.Background(), "")
 
@@ -148,7 +247,7 @@ func TestGetUserByUsername_EmptyUsername(t *testing.T) {
 
 func TestGetUserByUsername_ErrorFetchingUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "testuser"
 	mockRepo.On("GetUserByUsername", mock.Anything, username).Return((*models.User)(nil), errors.New("database error"))
@@ -161,13 +260,13 @@ func TestGetUserByUsername_ErrorFetchingUser(t *testing.T) {
 
 func TestAuthenticate_ErrorFetchingUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "testuser"
 	password := "password123"
 	mockRepo.On("GetUserByUsername", mock.Anything, username).Return((*models.User)(nil), errors.New("database error"))
 
-	_, err := service.Authenticate(context.Background(), username, password)
+	_, err := service.Authenticate(context.Background(), username, password, testClient)
 
 	assert.EqualError(t, err, "error fetching user: database error")
 	mockRepo.AssertExpectations(t)
@@ -175,13 +274,13 @@ func TestAuthenticate_ErrorFetchingUser(t *testing.T) {
 
 func TestAuthenticate_UserNotFound(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	username := "nonexistent"
 	password := "password123"
 	mockRepo.On("GetUserByUsername", mock.Anything, username).Return((*models.User)(nil), nil)
 
-	_, err := service.Authenticate(context.Background(), username, password)
+	_, err := service.Authenticate(context.Background(), username, password, testClient)
 
 	assert.EqualError(t, err, "invalid username or password")
 	mockRepo.AssertExpectations(t)
@@ -189,7 +288,7 @@ func TestAuthenticate_UserNotFound(t *testing.T) {
 
 func TestUpdateUserCoins_ErrorUpdatingDB(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	service := services.NewUserService(mockRepo)
+	service := newUserService(t, mockRepo)
 
 	userID := int64(1)
 	coins := 500
@@ -200,3 +299,211 @@ func TestUpdateUserCoins_ErrorUpdatingDB(t *testing.T) {
 	assert.EqualError(t, err, "update failed")
 	mockRepo.AssertExpectations(t)
 }
+
+func TestAuthenticate_Blocked(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	guard := new(MockLoginGuard)
+	service := newUserServiceWithGuard(t, mockRepo, guard)
+
+	guard.On("Check", mock.Anything, "testuser", testClient).Return(&models.LoginBlockedError{RetryAfter: time.Minute})
+
+	_, err := service.Authenticate(context.Background(), "testuser", "password123", testClient)
+
+	var blockedErr *models.LoginBlockedError
+	assert.ErrorAs(t, err, &blockedErr)
+	assert.ErrorIs(t, err, models.ErrLoginBlocked)
+	assert.Equal(t, time.Minute, blockedErr.RetryAfter)
+	mockRepo.AssertNotCalled(t, "GetUserByUsername", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticate_FailureAndSuccessAreRecorded(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	guard := permissiveLoginGuard()
+	service := newUserServiceWithGuard(t, mockRepo, guard)
+
+	hashedPassword, err := services.HashPassword("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{Username: "testuser", Password: hashedPassword}, nil)
+	mockRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+
+	_, err = service.Authenticate(context.Background(), "testuser", "wrong", testClient)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	_, err = service.Authenticate(context.Background(), "ghost", "wrong", testClient)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	_, err = service.Authenticate(context.Background(), "testuser", "password123", testClient)
+	assert.NoError(t, err)
+
+	guard.AssertCalled(t, "RecordFailure", mock.Anything, "testuser", testClient)
+	guard.AssertCalled(t, "RecordFailure", mock.Anything, "ghost", testClient)
+	guard.AssertNumberOfCalls(t, "RecordSuccess", 1)
+}
+
+func TestUnlockUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	guard := new(MockLoginGuard)
+	service := newUserServiceWithGuard(t, mockRepo, guard)
+
+	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{Username: "testuser"}, nil)
+	mockRepo.On("GetUserByUsername", mock.Anything, "ghost").Return((*models.User)(nil), nil)
+	guard.On("Unlock", mock.Anything, "testuser").Return(nil)
+
+	assert.NoError(t, service.UnlockUser(context.Background(), "testuser"))
+	assert.ErrorIs(t, service.UnlockUser(context.Background(), "ghost"), models.ErrUserNotFound)
+	guard.AssertNumberOfCalls(t, "Unlock", 1)
+}
+
+func newUserServiceWithAuthenticators(t *testing.T, repo *MockUserRepository, authenticators ...services.Authenticator) *services.UserService {
+	return services.NewUserService(repo, newTestTokenService(t, repo), permissiveLoginGuard(), permissiveLoginRecorder(), authenticators...)
+}
+
+func TestAuthenticate_ExternalUserIsProvisionedOnFirstLogin(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	ldapAuthenticator, _ := newLDAPAuthenticator(t)
+	service := newUserServiceWithAuthenticators(t, mockRepo, services.NewLocalAuthenticator(mockRepo), ldapAuthenticator)
+
+	mockRepo.On("GetUserByUsername", mock.Anything, "alice").Return((*models.User)(nil), nil).Twice()
+	mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *models.User) bool {
+		return user.Username == "alice" && user.Password == "!" && user.Coins == 1000
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.User).ID = 7
+	})
+
+	tokens, err := service.Authenticate(context.Background(), "alice", "alice-secret", testClient)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAuthenticate_ExternalAccountHasNoLocalPassword(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := newUserService(t, mockRepo)
+
+	mockRepo.On("GetUserByUsername", mock.Anything, "alice").Return(&models.User{ID: 7, Username: "alice", Password: "!"}, nil)
+
+	_, err := service.Authenticate(context.Background(), "alice", "!", testClient)
+
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+}
+
+func TestAuthenticate_ProviderErrorIsNotReportedAsInvalidCredentials(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	unavailable := &MockAuthenticator{name: "ldap"}
+	rejecting := &MockAuthenticator{name: "local"}
+	service := newUserServiceWithAuthenticators(t, mockRepo, unavailable, rejecting)
+
+	unavailable.On("Authenticate", mock.Anything, mock.Anything).Return((*models.Identity)(nil), errors.New("connection refused"))
+	rejecting.On("Authenticate", mock.Anything, mock.Anything).Return((*models.Identity)(nil), models.ErrInvalidCredentials)
+
+	_, err := service.Authenticate(context.Background(), "alice", "secret", testClient)
+
+	assert.EqualError(t, err, "connection refused")
+	rejecting.AssertExpectations(t)
+}
+
+func TestAuthenticate_LaterProviderAcceptsPassword(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	unavailable := &MockAuthenticator{name: "ldap"}
+	accepting := &MockAuthenticator{name: "local"}
+	service := newUserServiceWithAuthenticators(t, mockRepo, unavailable, accepting)
+
+	user := &models.User{ID: 1, Username: "alice"}
+	unavailable.On("Authenticate", mock.Anything, mock.Anything).Return((*models.Identity)(nil), errors.New("connection refused"))
+	accepting.On("Authenticate", mock.Anything, models.Credentials{Username: "alice", Password: "secret"}).
+		Return(&models.Identity{Provider: "local", Subject: "1", Username: "alice", User: user}, nil)
+
+	tokens, err := service.Authenticate(context.Background(), "alice", "secret", testClient)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	mockRepo.AssertNotCalled(t, "GetUserByUsername", mock.Anything, mock.Anything)
+}
+
+func TestAuthenticateExternal(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	oidc := &MockAuthenticator{name: "oidc"}
+	service := newUserServiceWithAuthenticators(t, mockRepo, oidc)
+
+	credentials := models.Credentials{Code: "code", Nonce: "nonce", CodeVerifier: "verifier"}
+	oidc.On("Authenticate", mock.Anything, credentials).Return(&models.Identity{Provider: "oidc", Subject: "00u1", Username: "alice"}, nil)
+	mockRepo.On("GetUserByUsername", mock.Anything, "alice").Return(&models.User{ID: 7, Username: "alice", Password: "!"}, nil)
+
+	tokens, err := service.AuthenticateExternal(context.Background(), "oidc", credentials, testClient)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	_, err = service.AuthenticateExternal(context.Background(), "saml", credentials, testClient)
+	assert.ErrorIs(t, err, models.ErrUnknownProvider)
+}
+
+func TestAuthenticate_LoginEventsAreRecorded(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	recorder := permissiveLoginRecorder()
+	service := services.NewUserService(mockRepo, newTestTokenService(t, mockRepo), permissiveLoginGuard(), recorder, services.NewLocalAuthenticator(mockRepo))
+
+	hashedPassword, err := services.HashPassword("password123")
+	assert.NoError(t, err)
+	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(&models.User{ID: 1, Username: "testuser", Password: hashedPassword}, nil)
+
+	_, err = service.Authenticate(context.Background(), "testuser", "wrong", testClient)
+	assert.ErrorIs(t, err, models.ErrInvalidCredentials)
+	_, err = service.Authenticate(context.Background(), "testuser", "password123", testClient)
+	assert.NoError(t, err)
+
+	recorder.AssertCalled(t, "RecordLogin", mock.Anything, mock.MatchedBy(func(event models.LoginEvent) bool {
+		return !event.Success && event.Username == "testuser" && event.UserID == nil &&
+			event.FailureReason == models.LoginFailureInvalidCredentials && event.IP == testClient.IP
+	}))
+	recorder.AssertCalled(t, "RecordLogin", mock.Anything, mock.MatchedBy(func(event models.LoginEvent) bool {
+		return event.Success && event.Username == "testuser" && event.UserID != nil && *event.UserID == 1 &&
+			event.Provider == "local" && event.FailureReason == ""
+	}))
+}
+
+func TestAuthenticate_BlockedLoginIsRecorded(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	guard := new(MockLoginGuard)
+	recorder := permissiveLoginRecorder()
+	service := services.NewUserService(mockRepo, newTestTokenService(t, mockRepo), guard, recorder, services.NewLocalAuthenticator(mockRepo))
+
+	guard.On("Check", mock.Anything, "testuser", testClient).Return(&models.LoginBlockedError{RetryAfter: time.Minute})
+
+	_, err := service.Authenticate(context.Background(), "testuser", "password123", testClient)
+
+	assert.ErrorIs(t, err, models.ErrLoginBlocked)
+	recorder.AssertCalled(t, "RecordLogin", mock.Anything, mock.MatchedBy(func(event models.LoginEvent) bool {
+		return !event.Success && event.FailureReason == models.LoginFailureBlocked
+	}))
+}
+
+func TestAuthenticate_InactiveAccountIsRefused(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	recorder := permissiveLoginRecorder()
+	service := services.NewUserService(mockRepo, newTestTokenService(t, mockRepo), permissiveLoginGuard(), recorder, services.NewLocalAuthenticator(mockRepo))
+
+	hashedPassword, err := services.HashPassword("password123")
+	assert.NoError(t, err)
+	user := &models.User{ID: 1, Username: "testuser", Password: hashedPassword, Profile: models.Profile{Status: models.UserStatusTerminated}}
+	mockRepo.On("GetUserByUsername", mock.Anything, "testuser").Return(user, nil)
+
+	_, err = service.Authenticate(context.Background(), "testuser", "password123", testClient)
+
+	var inactiveErr *models.AccountInactiveError
+	assert.ErrorAs(t, err, &inactiveErr)
+	assert.Equal(t, models.UserStatusTerminated, inactiveErr.Status)
+	recorder.AssertCalled(t, "RecordLogin", mock.Anything, mock.MatchedBy(func(event models.LoginEvent) bool {
+		return !event.Success && event.FailureReason == models.LoginFailureInactive
+	}))
+}
+
+func TestRecordFailedLogin_InvalidRequest(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	recorder := permissiveLoginRecorder()
+	service := services.NewUserService(mockRepo, newTestTokenService(t, mockRepo), permissiveLoginGuard(), recorder, services.NewLocalAuthenticator(mockRepo))
+
+	service.RecordFailedLogin(context.Background(), "testuser", testClient, models.ErrInvalidLoginRequest)
+
+	recorder.AssertCalled(t, "RecordLogin", mock.Anything, mock.MatchedBy(func(event models.LoginEvent) bool {
+		return !event.Success && event.Username == "testuser" && event.FailureReason == models.LoginFailureInvalidRequest
+	}))
+}